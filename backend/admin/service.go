@@ -1,10 +1,18 @@
 package admin
 
 import (
+	"bytes"
 	"fmt"
+	"swift_transit/alert"
 	"swift_transit/domain"
+	"swift_transit/fare"
+	"swift_transit/gtfs"
+	"swift_transit/pass"
 	"swift_transit/repo"
+	"swift_transit/route"
+	"swift_transit/student"
 	"swift_transit/utils"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -37,6 +45,46 @@ type Service interface {
 	// Routes
 	GetAllRoutes(page, pageSize int) ([]domain.Route, int, error)
 	DeleteRoute(id int64) error
+	GetRouteVersions(routeId int64) ([]domain.RouteVersion, error)
+	GetRouteVersion(routeId int64, version int) (*domain.RouteVersion, error)
+	CheckRouteVersion(v domain.RouteVersion) (*domain.RouteCheck, error)
+	CreateRouteVersion(v domain.RouteVersion) (*domain.RouteVersion, *domain.RouteCheck, error)
+	ScheduleRouteVersion(routeId int64, version int, effectiveFrom time.Time) (*domain.RouteVersion, error)
+	DeleteRouteVersion(routeId int64, version int) error
+	GetFarePolicy(routeId int64) (*domain.FarePolicy, error)
+	UpdateFarePolicy(policy domain.FarePolicy) (*domain.FarePolicy, error)
+
+	// Concessions
+	GetConcessions() ([]domain.ConcessionRule, error)
+	CreateConcession(rule domain.ConcessionRule) (*domain.ConcessionRule, error)
+	UpdateConcession(rule domain.ConcessionRule) error
+	DeleteConcession(id int64) error
+
+	// Pass plans
+	GetPassPlans() ([]domain.PassPlan, error)
+	CreatePassPlan(plan domain.PassPlan) (*domain.PassPlan, error)
+	UpdatePassPlan(plan domain.PassPlan) error
+	DeletePassPlan(id int64) error
+
+	// Student verifications
+	GetStudentVerifications(status string, page, pageSize int) ([]domain.StudentVerification, int, error)
+	ApproveStudentVerification(id, adminId int64, note string) (*domain.StudentVerification, error)
+	RejectStudentVerification(id, adminId int64, note string) (*domain.StudentVerification, error)
+
+	// GTFS
+	ExportGTFS() ([]byte, error)
+	// ImportGTFS imports a feed; updates of routes imported before take
+	// effect at effectiveFrom, or at once when it is unset
+	ImportGTFS(feed []byte, effectiveFrom time.Time) (*gtfs.ImportReport, error)
+
+	// Bus alerts
+	GetAlerts(page, pageSize int) ([]domain.BusAlert, int, error)
+
+	// Stations
+	// GetStationActivity ranks stations by tickets starting and ending there
+	// from the start of the from date to the end of the to date, given as
+	// domain.DateLayout; empty dates cover the last 30 days
+	GetStationActivity(from, to string) ([]domain.StationActivity, error)
 
 	// Tickets
 	GetAllTickets(page, pageSize int) ([]domain.Ticket, int, error)
@@ -47,12 +95,24 @@ type Service interface {
 
 type service struct {
 	repo        repo.AdminRepo
+	fareSvc     fare.Service
+	routeSvc    route.Service
+	studentSvc  student.Service
+	passSvc     pass.Service
+	gtfsSvc     gtfs.Service
+	alertSvc    alert.Service
 	utilHandler *utils.Handler
 }
 
-func NewService(repo repo.AdminRepo, utilHandler *utils.Handler) Service {
+func NewService(repo repo.AdminRepo, fareSvc fare.Service, routeSvc route.Service, studentSvc student.Service, passSvc pass.Service, gtfsSvc gtfs.Service, alertSvc alert.Service, utilHandler *utils.Handler) Service {
 	return &service{
 		repo:        repo,
+		fareSvc:     fareSvc,
+		routeSvc:    routeSvc,
+		studentSvc:  studentSvc,
+		passSvc:     passSvc,
+		gtfsSvc:     gtfsSvc,
+		alertSvc:    alertSvc,
 		utilHandler: utilHandler,
 	}
 }
@@ -67,6 +127,7 @@ func (s *service) Login(username, password string) (*domain.Admin, string, error
 		return nil, "", fmt.Errorf("invalid credentials")
 	}
 
+	admin.Role = domain.RoleAdmin
 	token, err := s.utilHandler.CreateJWT(admin)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate token: %w", err)
@@ -144,10 +205,146 @@ func (s *service) GetAllRoutes(page, pageSize int) ([]domain.Route, int, error)
 	return s.repo.GetAllRoutes(pageSize, offset)
 }
 
+// DeleteRoute refuses routes that tickets were sold on, since deleting takes
+// the stops and versions the tickets refer to with it.
 func (s *service) DeleteRoute(id int64) error {
+	tickets, err := s.repo.CountRouteTickets(id)
+	if err != nil {
+		return err
+	}
+	if tickets > 0 {
+		return fmt.Errorf("route has %d tickets; publish a new version instead of deleting it", tickets)
+	}
 	return s.repo.DeleteRoute(id)
 }
 
+func (s *service) GetRouteVersions(routeId int64) ([]domain.RouteVersion, error) {
+	return s.routeSvc.GetVersions(routeId)
+}
+
+func (s *service) GetRouteVersion(routeId int64, version int) (*domain.RouteVersion, error) {
+	return s.routeSvc.GetVersion(routeId, version)
+}
+
+func (s *service) CheckRouteVersion(v domain.RouteVersion) (*domain.RouteCheck, error) {
+	return s.routeSvc.CheckVersion(v)
+}
+
+func (s *service) CreateRouteVersion(v domain.RouteVersion) (*domain.RouteVersion, *domain.RouteCheck, error) {
+	return s.routeSvc.CreateVersion(v)
+}
+
+func (s *service) ScheduleRouteVersion(routeId int64, version int, effectiveFrom time.Time) (*domain.RouteVersion, error) {
+	return s.routeSvc.ScheduleVersion(routeId, version, effectiveFrom)
+}
+
+func (s *service) DeleteRouteVersion(routeId int64, version int) error {
+	return s.routeSvc.DeleteVersion(routeId, version)
+}
+
+func (s *service) GetFarePolicy(routeId int64) (*domain.FarePolicy, error) {
+	return s.fareSvc.GetPolicy(routeId)
+}
+
+func (s *service) UpdateFarePolicy(policy domain.FarePolicy) (*domain.FarePolicy, error) {
+	return s.fareSvc.UpdatePolicy(policy)
+}
+
+// Concessions
+func (s *service) GetConcessions() ([]domain.ConcessionRule, error) {
+	return s.fareSvc.GetConcessions()
+}
+
+func (s *service) CreateConcession(rule domain.ConcessionRule) (*domain.ConcessionRule, error) {
+	return s.fareSvc.CreateConcession(rule)
+}
+
+func (s *service) UpdateConcession(rule domain.ConcessionRule) error {
+	return s.fareSvc.UpdateConcession(rule)
+}
+
+func (s *service) DeleteConcession(id int64) error {
+	return s.fareSvc.DeleteConcession(id)
+}
+
+// Pass plans
+func (s *service) GetPassPlans() ([]domain.PassPlan, error) {
+	return s.passSvc.GetPlans(false)
+}
+
+func (s *service) CreatePassPlan(plan domain.PassPlan) (*domain.PassPlan, error) {
+	return s.passSvc.CreatePlan(plan)
+}
+
+func (s *service) UpdatePassPlan(plan domain.PassPlan) error {
+	return s.passSvc.UpdatePlan(plan)
+}
+
+func (s *service) DeletePassPlan(id int64) error {
+	return s.passSvc.DeletePlan(id)
+}
+
+// Student verifications
+func (s *service) GetStudentVerifications(status string, page, pageSize int) ([]domain.StudentVerification, int, error) {
+	return s.studentSvc.List(status, page, pageSize)
+}
+
+func (s *service) ApproveStudentVerification(id, adminId int64, note string) (*domain.StudentVerification, error) {
+	return s.studentSvc.Approve(id, adminId, note)
+}
+
+func (s *service) RejectStudentVerification(id, adminId int64, note string) (*domain.StudentVerification, error) {
+	return s.studentSvc.Reject(id, adminId, note)
+}
+
+// GTFS
+func (s *service) ExportGTFS() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.gtfsSvc.Export(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *service) ImportGTFS(feed []byte, effectiveFrom time.Time) (*gtfs.ImportReport, error) {
+	return s.gtfsSvc.Import(bytes.NewReader(feed), int64(len(feed)), effectiveFrom)
+}
+
+// Bus alerts
+func (s *service) GetAlerts(page, pageSize int) ([]domain.BusAlert, int, error) {
+	return s.alertSvc.GetAllAlerts(page, pageSize)
+}
+
+// Stations
+const (
+	stationActivityDays  = 30
+	stationActivityLimit = 50
+)
+
+func (s *service) GetStationActivity(from, to string) ([]domain.StationActivity, error) {
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.Local)
+	if to != "" {
+		d, err := time.ParseInLocation(domain.DateLayout, to, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to date")
+		}
+		end = d.AddDate(0, 0, 1)
+	}
+	start := end.AddDate(0, 0, -stationActivityDays)
+	if from != "" {
+		d, err := time.ParseInLocation(domain.DateLayout, from, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from date")
+		}
+		start = d
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("from must not be after to")
+	}
+	return s.repo.GetStationActivity(start, end, stationActivityLimit)
+}
+
 // Tickets
 func (s *service) GetAllTickets(page, pageSize int) ([]domain.Ticket, int, error) {
 	offset := (page - 1) * pageSize
@@ -0,0 +1,31 @@
+package alert
+
+import (
+	"swift_transit/domain"
+	"swift_transit/location"
+)
+
+// Thresholds decide when a bus is flagged; see config.AlertConfig.
+type Thresholds struct {
+	OffRouteMeters  float64
+	OffRouteUpdates int
+	SpeedLimit      float64 // km/h
+}
+
+type Service interface {
+	// ObserveLocation checks a bus position against its route and the speed
+	// limit, and raises an alert when one is crossed
+	ObserveLocation(update location.LocationUpdate)
+
+	GetOwnerAlerts(ownerId int64, page, pageSize int) ([]domain.BusAlert, int, error)
+	GetAllAlerts(page, pageSize int) ([]domain.BusAlert, int, error)
+}
+
+type AlertRepo interface {
+	// DistanceFromRoute returns how far in meters the point is from the
+	// route geometry
+	DistanceFromRoute(routeId int64, lat, lon float64) (float64, error)
+	Create(alert domain.BusAlert) (*domain.BusAlert, error)
+	FindByOwner(ownerId int64, limit, offset int) ([]domain.BusAlert, int, error)
+	FindAll(limit, offset int) ([]domain.BusAlert, int, error)
+}
@@ -0,0 +1,139 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"swift_transit/domain"
+	"swift_transit/location"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Detection state expires when a bus stops reporting
+const stateTTL = 30 * time.Minute
+
+// service keeps per-bus detection state in Redis, so a bus is judged on all
+// its updates whichever instance it is connected to.
+type service struct {
+	repo       AlertRepo
+	hub        *location.Hub
+	thresholds Thresholds
+	redis      *redis.Client
+	ctx        context.Context
+}
+
+func NewService(repo AlertRepo, hub *location.Hub, thresholds Thresholds, redis *redis.Client, ctx context.Context) Service {
+	return &service{
+		repo:       repo,
+		hub:        hub,
+		thresholds: thresholds,
+		redis:      redis,
+		ctx:        ctx,
+	}
+}
+
+func offRouteKey(busID int64) string {
+	return fmt.Sprintf("bus_off_route:%d", busID)
+}
+
+func overspeedKey(busID int64) string {
+	return fmt.Sprintf("bus_overspeed:%d", busID)
+}
+
+func (s *service) ObserveLocation(update location.LocationUpdate) {
+	if err := s.checkRoute(update); err != nil {
+		log.Printf("failed to check bus %d against its route: %v", update.BusID, err)
+	}
+	if err := s.checkSpeed(update); err != nil {
+		log.Printf("failed to check speed of bus %d: %v", update.BusID, err)
+	}
+}
+
+// checkRoute counts consecutive updates away from the route and alerts once
+// when the count reaches the threshold. Coming back resets the count.
+func (s *service) checkRoute(update location.LocationUpdate) error {
+	distance, err := s.repo.DistanceFromRoute(update.RouteID, update.Latitude, update.Longitude)
+	if err != nil {
+		return err
+	}
+	key := offRouteKey(update.BusID)
+	if distance <= s.thresholds.OffRouteMeters {
+		return s.redis.Del(s.ctx, key).Err()
+	}
+
+	var count *redis.IntCmd
+	_, err = s.redis.Pipelined(s.ctx, func(pipe redis.Pipeliner) error {
+		count = pipe.Incr(s.ctx, key)
+		pipe.Expire(s.ctx, key, stateTTL)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if count.Val() != int64(s.thresholds.OffRouteUpdates) {
+		return nil
+	}
+
+	return s.raise(domain.BusAlert{
+		Type:           domain.AlertOffRoute,
+		Message:        fmt.Sprintf("%s is %.0f m off route for %d updates", update.RegistrationNumber, distance, count.Val()),
+		DistanceMeters: distance,
+	}, update)
+}
+
+// checkSpeed alerts when a bus goes over the limit, and again only after it
+// has slowed down below it.
+func (s *service) checkSpeed(update location.LocationUpdate) error {
+	key := overspeedKey(update.BusID)
+	if update.Speed <= s.thresholds.SpeedLimit {
+		return s.redis.Del(s.ctx, key).Err()
+	}
+
+	first, err := s.redis.SetNX(s.ctx, key, update.Speed, stateTTL).Result()
+	if err != nil {
+		return err
+	}
+	if !first {
+		s.redis.Expire(s.ctx, key, stateTTL)
+		return nil
+	}
+
+	return s.raise(domain.BusAlert{
+		Type:    domain.AlertOverspeed,
+		Message: fmt.Sprintf("%s is driving at %.0f km/h, above the %.0f km/h limit", update.RegistrationNumber, update.Speed, s.thresholds.SpeedLimit),
+	}, update)
+}
+
+// raise stores the alert and pushes it to the owner of the bus.
+func (s *service) raise(alert domain.BusAlert, update location.LocationUpdate) error {
+	alert.BusId = update.BusID
+	alert.RegistrationNumber = update.RegistrationNumber
+	alert.RouteId = update.RouteID
+	alert.Latitude = update.Latitude
+	alert.Longitude = update.Longitude
+	alert.Speed = update.Speed
+	alert.CreatedAt = time.Now()
+
+	saved, err := s.repo.Create(alert)
+	if err != nil {
+		return err
+	}
+	log.Printf("bus alert: %s", saved.Message)
+
+	if saved.OwnerId == nil {
+		return nil
+	}
+	return s.hub.PublishToOwner(*saved.OwnerId, location.MessageAlert, saved)
+}
+
+func (s *service) GetOwnerAlerts(ownerId int64, page, pageSize int) ([]domain.BusAlert, int, error) {
+	offset := (page - 1) * pageSize
+	return s.repo.FindByOwner(ownerId, pageSize, offset)
+}
+
+func (s *service) GetAllAlerts(page, pageSize int) ([]domain.BusAlert, int, error) {
+	offset := (page - 1) * pageSize
+	return s.repo.FindAll(pageSize, offset)
+}
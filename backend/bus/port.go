@@ -5,8 +5,19 @@ import (
 	"swift_transit/ticket"
 )
 
+// FindByLocationRequest searches buses between two points. Radius is the
+// furthest a rider is willing to walk to or from a stop, in meters.
+type FindByLocationRequest struct {
+	OriginLat      float64
+	OriginLon      float64
+	DestinationLat float64
+	DestinationLon float64
+	Radius         float64
+}
+
 type Service interface {
 	FindBus(start, end string) ([]domain.Bus, error)
+	FindBusByLocation(req FindByLocationRequest) ([]domain.Bus, error)
 	Login(regNum, password string, variant string) (*BusLoginResult, error)
 	Register(regNum, password string, routeIdUp, routeIdDown int64) (*domain.BusCredential, error)
 	ValidateTicket(ticketID int64, routeID int64, busName string) error
@@ -15,6 +26,7 @@ type Service interface {
 
 type BusRepo interface {
 	FindBus(start, end string) ([]domain.Bus, error)
+	FindBusByLocation(originLat, originLon, destLat, destLon, radius float64) ([]domain.Bus, error)
 	GetBusByRegistrationNumber(regNum string) (*domain.BusCredential, error)
 	Create(busCred domain.BusCredential) (*domain.BusCredential, error)
 }
@@ -2,10 +2,14 @@ package bus
 
 import (
 	"fmt"
-	"math"
+	"log"
 	"strings"
 	"swift_transit/domain"
+	"swift_transit/fare"
+	"swift_transit/pass"
 	"swift_transit/ticket"
+	"swift_transit/user"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -19,12 +23,20 @@ type BusLoginResult struct {
 type service struct {
 	repo       BusRepo
 	ticketRepo ticket.TicketRepo
+	fareSvc    fare.Service
+	passSvc    pass.Service
+	userRepo   user.UserRepo
+	occupancy  ticket.Occupancy
 }
 
-func NewService(repo BusRepo, ticketRepo ticket.TicketRepo) Service {
+func NewService(repo BusRepo, ticketRepo ticket.TicketRepo, fareSvc fare.Service, passSvc pass.Service, userRepo user.UserRepo, occupancy ticket.Occupancy) Service {
 	return &service{
 		repo:       repo,
 		ticketRepo: ticketRepo,
+		fareSvc:    fareSvc,
+		passSvc:    passSvc,
+		userRepo:   userRepo,
+		occupancy:  occupancy,
 	}
 }
 
@@ -34,7 +46,38 @@ func (svc *service) FindBus(start, end string) ([]domain.Bus, error) {
 		return nil, err
 	}
 	for i := range buses {
-		buses[i].Fare = math.Ceil(buses[i].Fare)
+		fare, err := svc.fareSvc.CalculateFare(buses[i].Id, start, end, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		buses[i].Fare = fare
+	}
+	return buses, nil
+}
+
+func (svc *service) FindBusByLocation(req FindByLocationRequest) ([]domain.Bus, error) {
+	for _, p := range [][2]float64{{req.OriginLat, req.OriginLon}, {req.DestinationLat, req.DestinationLon}} {
+		if p[0] < -90 || p[0] > 90 || p[1] < -180 || p[1] > 180 {
+			return nil, fmt.Errorf("invalid coordinates")
+		}
+	}
+	if req.Radius <= 0 {
+		req.Radius = domain.DefaultNearbyRadius
+	}
+	if req.Radius > domain.MaxNearbyRadius {
+		req.Radius = domain.MaxNearbyRadius
+	}
+
+	buses, err := svc.repo.FindBusByLocation(req.OriginLat, req.OriginLon, req.DestinationLat, req.DestinationLon, req.Radius)
+	if err != nil {
+		return nil, err
+	}
+	for i := range buses {
+		fare, err := svc.fareSvc.CalculateFare(buses[i].Id, buses[i].BoardingStop, buses[i].AlightingStop, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		buses[i].Fare = fare
 	}
 	return buses, nil
 }
@@ -87,34 +130,54 @@ func (svc *service) Register(regNum, password string, routeIdUp, routeIdDown int
 
 func (svc *service) ValidateTicket(ticketID int64, routeID int64, RegistrationNumber string) error {
 	// 1. Get Ticket
-	ticket, err := svc.ticketRepo.Get(ticketID)
+	t, err := svc.ticketRepo.Get(ticketID)
 	if err != nil {
 		return err
 	}
 
-	if ticket.CancelledAt != nil {
+	if t.CancelledAt != nil {
 		return fmt.Errorf("ticket has been cancelled")
 	}
 
-	if !ticket.PaidStatus {
+	if !t.PaidStatus {
 		return fmt.Errorf("ticket is unpaid")
 	}
 
 	// 2. Check if ticket belongs to the route
-	if ticket.RouteId != routeID {
+	if t.RouteId != routeID {
 		return fmt.Errorf("ticket is not valid for this route")
 	}
 
 	// 3. Check if already checked
-	if ticket.Checked {
+	if t.Checked {
 		return fmt.Errorf("ticket already checked")
 	}
+	if err := ticket.CheckJourneyLeg(svc.ticketRepo, t); err != nil {
+		return err
+	}
 
 	// 4. Update status
 	return svc.ticketRepo.ValidateTicket(ticketID, RegistrationNumber)
 }
 
 func (svc *service) CheckTicket(req ticket.CheckTicketRequest) (map[string]interface{}, error) {
+	if strings.HasPrefix(req.QRCode, pass.QRPrefix) {
+		p, err := svc.passSvc.BoardWithQR(req.QRCode, pass.BoardRequest{
+			RouteId:            req.RouteID,
+			RegistrationNumber: req.RegistrationNumber,
+			Stop:               req.CurrentStoppage.Name,
+			Source:             "QR",
+		})
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"success": true,
+			"status":  "valid_pass",
+			"pass":    p,
+		}, nil
+	}
+
 	t, err := svc.ticketRepo.GetByQRCode(req.QRCode)
 	if err != nil {
 		return nil, fmt.Errorf("ticket not found")
@@ -132,8 +195,15 @@ func (svc *service) CheckTicket(req ticket.CheckTicketRequest) (map[string]inter
 	if t.Checked {
 		return nil, fmt.Errorf("ticket already checked")
 	}
+	if err := ticket.CheckJourneyLeg(svc.ticketRepo, t); err != nil {
+		return nil, err
+	}
 
-	destStop, err := svc.ticketRepo.GetStop(req.RouteID, t.EndDestination)
+	var routeVersionID int64
+	if t.RouteVersionId != nil {
+		routeVersionID = *t.RouteVersionId
+	}
+	destStop, err := svc.ticketRepo.GetStop(req.RouteID, t.EndDestination, routeVersionID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid destination stop in ticket: %v", err)
 	}
@@ -145,10 +215,15 @@ func (svc *service) CheckTicket(req ticket.CheckTicketRequest) (map[string]inter
 	}
 
 	if req.CurrentStoppage.Order > destStop.Order {
-		extraFare, err := svc.ticketRepo.CalculateFare(req.RouteID, t.EndDestination, req.CurrentStoppage.Name)
+		rider, err := svc.userRepo.GetWithPassword(t.UserId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load rider: %v", err)
+		}
+		quote, err := svc.fareSvc.QuoteFare(req.RouteID, t.EndDestination, req.CurrentStoppage.Name, rider, time.Now())
 		if err != nil {
 			return nil, fmt.Errorf("failed to calculate extra fare: %v", err)
 		}
+		extraFare := quote.Fare
 
 		response["status"] = "over_travel"
 		response["message"] = fmt.Sprintf("You have over-traveled. Please pay extra fare: %.2f", extraFare)
@@ -160,5 +235,10 @@ func (svc *service) CheckTicket(req ticket.CheckTicketRequest) (map[string]inter
 		return nil, err
 	}
 
+	// Occupancy is best effort and never holds up boarding
+	if err := svc.occupancy.Board(req.RegistrationNumber, fmt.Sprintf("ticket:%d", t.Id), t.EndDestination); err != nil {
+		log.Printf("failed to count rider on %s: %v", req.RegistrationNumber, err)
+	}
+
 	return response, nil
 }
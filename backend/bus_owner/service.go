@@ -12,7 +12,7 @@ import (
 type Service interface {
 	Register(username, password string) error
 	Login(username, password string) (*domain.BusOwner, string, error)
-	RegisterBus(ownerId int64, regNo, password string, routeIdUp, routeIdDown int64) error
+	RegisterBus(ownerId int64, regNo, password string, routeIdUp, routeIdDown int64, seatCapacity int) error
 	GetBuses(ownerId int64) ([]domain.BusCredential, error)
 	GetAnalytics(ownerId int64) (map[string]interface{}, error)
 	GetPerBusAnalytics(ownerId int64) ([]domain.BusAnalytics, error)
@@ -61,6 +61,7 @@ func (s *service) Login(username, password string) (*domain.BusOwner, string, er
 		return nil, "", fmt.Errorf("invalid credentials")
 	}
 
+	owner.Role = domain.RoleBusOwner
 	token, err := s.utilHandler.CreateJWT(owner)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate token: %w", err)
@@ -69,7 +70,11 @@ func (s *service) Login(username, password string) (*domain.BusOwner, string, er
 	return owner, token, nil
 }
 
-func (s *service) RegisterBus(ownerId int64, regNo, password string, routeIdUp, routeIdDown int64) error {
+func (s *service) RegisterBus(ownerId int64, regNo, password string, routeIdUp, routeIdDown int64, seatCapacity int) error {
+	if seatCapacity < 0 {
+		return fmt.Errorf("seat capacity cannot be negative")
+	}
+
 	// Check limit (max 10 buses per route per owner)
 	// We check for both up and down routes, assuming they are the same logical route usually,
 	// or we count total buses associated with either.
@@ -119,6 +124,7 @@ func (s *service) RegisterBus(ownerId int64, regNo, password string, routeIdUp,
 		RouteIdUp:          routeIdUp,
 		RouteIdDown:        routeIdDown,
 		OwnerId:            &ownerId,
+		SeatCapacity:       seatCapacity,
 	})
 }
 
@@ -133,8 +139,11 @@ func (s *service) GetAnalytics(ownerId int64) (map[string]interface{}, error) {
 	}
 
 	return map[string]interface{}{
-		"total_revenue": analytics.TotalRevenue,
-		"total_tickets": analytics.TotalTickets,
+		"total_revenue":       analytics.TotalRevenue,
+		"total_tickets":       analytics.TotalTickets,
+		"concession_discount": analytics.ConcessionDiscount,
+		"pass_boardings":      analytics.PassBoardings,
+		"pass_revenue":        analytics.PassRevenue,
 		"today": map[string]interface{}{
 			"revenue": analytics.Today.Revenue,
 			"tickets": analytics.Today.Tickets,
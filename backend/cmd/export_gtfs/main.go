@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"swift_transit/config"
+	"swift_transit/fare"
+	"swift_transit/gtfs"
+	"swift_transit/infra/db"
+	"swift_transit/repo"
+	"swift_transit/route"
+	"swift_transit/schedule"
+	"swift_transit/utils"
+)
+
+func main() {
+	out := flag.String("out", "gtfs.zip", "path of the GTFS zip to write")
+	flag.Parse()
+
+	cnf := config.Load()
+	utilHandler := utils.NewHandler(cnf)
+
+	dbCon, err := db.NewConnection(&cnf.Db)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer dbCon.Close()
+
+	routeRepo := repo.NewRouteRepo(dbCon, utilHandler)
+	fareSvc := fare.NewService(repo.NewFareRepo(dbCon, utilHandler))
+	routeSvc := route.NewService(routeRepo, fareSvc, route.CheckThresholds{
+		MinLengthMeters:  cnf.RouteCheck.MinLengthMeters,
+		StopOffsetMeters: cnf.RouteCheck.StopOffsetMeters,
+	})
+	scheduleSvc := schedule.NewService(repo.NewScheduleRepo(dbCon, utilHandler), cnf.GTFS.Timezone)
+	gtfsSvc := gtfs.NewService(routeRepo, routeSvc, fareSvc, scheduleSvc, gtfs.Agency{
+		Name:     cnf.ServiceName,
+		URL:      cnf.GTFS.AgencyURL,
+		Timezone: cnf.GTFS.Timezone,
+		Currency: cnf.GTFS.Currency,
+	})
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal("Failed to create output file:", err)
+	}
+	defer f.Close()
+
+	if err := gtfsSvc.Export(f); err != nil {
+		log.Fatal("Failed to export GTFS feed:", err)
+	}
+
+	fmt.Printf("✓ GTFS feed written to %s\n", *out)
+}
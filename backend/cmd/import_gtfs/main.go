@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"swift_transit/config"
+	"swift_transit/fare"
+	"swift_transit/gtfs"
+	"swift_transit/infra/db"
+	"swift_transit/repo"
+	"swift_transit/route"
+	"swift_transit/schedule"
+	"swift_transit/utils"
+	"time"
+)
+
+func main() {
+	in := flag.String("file", "gtfs.zip", "path of the GTFS zip to import")
+	from := flag.String("effective-from", "", "RFC 3339 time updates of routes imported before take effect; at once when empty")
+	flag.Parse()
+
+	var effectiveFrom time.Time
+	if *from != "" {
+		t, err := time.Parse(time.RFC3339, *from)
+		if err != nil {
+			log.Fatal("Invalid -effective-from:", err)
+		}
+		effectiveFrom = t
+	}
+
+	cnf := config.Load()
+	utilHandler := utils.NewHandler(cnf)
+
+	dbCon, err := db.NewConnection(&cnf.Db)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer dbCon.Close()
+
+	if err := db.MigrateDB(dbCon, "./migrations"); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
+	routeRepo := repo.NewRouteRepo(dbCon, utilHandler)
+	if err := routeRepo.LinkStations(); err != nil {
+		log.Fatal("Failed to link stops to stations:", err)
+	}
+	fareSvc := fare.NewService(repo.NewFareRepo(dbCon, utilHandler))
+	routeSvc := route.NewService(routeRepo, fareSvc, route.CheckThresholds{
+		MinLengthMeters:  cnf.RouteCheck.MinLengthMeters,
+		StopOffsetMeters: cnf.RouteCheck.StopOffsetMeters,
+	})
+	scheduleSvc := schedule.NewService(repo.NewScheduleRepo(dbCon, utilHandler), cnf.GTFS.Timezone)
+	gtfsSvc := gtfs.NewService(routeRepo, routeSvc, fareSvc, scheduleSvc, gtfs.Agency{
+		Name:     cnf.ServiceName,
+		URL:      cnf.GTFS.AgencyURL,
+		Timezone: cnf.GTFS.Timezone,
+		Currency: cnf.GTFS.Currency,
+	})
+
+	f, err := os.Open(*in)
+	if err != nil {
+		log.Fatal("Failed to open feed:", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		log.Fatal("Failed to read feed:", err)
+	}
+
+	report, err := gtfsSvc.Import(f, info.Size(), effectiveFrom)
+	if err != nil {
+		log.Fatal("Failed to import GTFS feed:", err)
+	}
+
+	for _, rt := range report.Routes {
+		action := "updated"
+		if rt.Created {
+			action = "created"
+		} else if rt.Scheduled {
+			action = fmt.Sprintf("scheduled version %d of", rt.Version)
+		}
+		fmt.Printf("✓ %s route %d %q (%s, direction %d) with %d stops\n", action, rt.RouteId, rt.Name, rt.GTFSRouteId, rt.Direction, rt.Stops)
+	}
+	for _, e := range report.Errors {
+		if e.Line > 0 {
+			fmt.Printf("✗ %s:%d: %s\n", e.File, e.Line, e.Message)
+		} else {
+			fmt.Printf("✗ %s: %s\n", e.File, e.Message)
+		}
+	}
+	for _, e := range report.Warnings {
+		fmt.Printf("! %s:%d: %s\n", e.File, e.Line, e.Message)
+	}
+	fmt.Printf("\n%d created, %d updated, %d errors\n", report.Created, report.Updated, len(report.Errors))
+}
@@ -4,29 +4,40 @@ import (
 	"context"
 	"fmt"
 	"swift_transit/admin"
+	"swift_transit/alert"
 	"swift_transit/bus"
 	"swift_transit/bus_owner"
 	"swift_transit/config"
+	"swift_transit/eta"
+	"swift_transit/fare"
+	"swift_transit/gtfs"
 	"swift_transit/infra/db"
 	"swift_transit/infra/payment"
 	"swift_transit/infra/rabbitmq"
 	redisConf "swift_transit/infra/redis"
 	"swift_transit/location"
+	"swift_transit/pass"
 	"swift_transit/repo"
 	"swift_transit/rest"
 	adminHandler "swift_transit/rest/handlers/admin"
 	busHandler "swift_transit/rest/handlers/bus"
 	busOwnerHandler "swift_transit/rest/handlers/bus_owner"
+	passHandler "swift_transit/rest/handlers/pass"
 	routeHandler "swift_transit/rest/handlers/route"
+	scheduleHandler "swift_transit/rest/handlers/schedule"
 	ticketHandler "swift_transit/rest/handlers/ticket"
 	transactionHandler "swift_transit/rest/handlers/transaction"
 	userHandler "swift_transit/rest/handlers/user"
 	"swift_transit/rest/middlewares"
 	"swift_transit/route"
+	"swift_transit/schedule"
+	"swift_transit/student"
 	"swift_transit/ticket"
 	"swift_transit/transaction"
+	"swift_transit/trip"
 	"swift_transit/user"
 	"swift_transit/utils"
+	"time"
 )
 
 func Start() {
@@ -52,13 +63,23 @@ func Start() {
 	//repos
 	userRepo := repo.NewUserRepo(dbCon, utilHandler)
 	routeRepo := repo.NewRouteRepo(dbCon, utilHandler)
+	if err := routeRepo.LinkStations(); err != nil {
+		panic(err)
+	}
 	busRepo := repo.NewBusRepo(dbCon, utilHandler)
 	ticketRepo := repo.NewTicketRepo(dbCon, utilHandler)
+	fareRepo := repo.NewFareRepo(dbCon, utilHandler)
+	studentRepo := repo.NewStudentRepo(dbCon, utilHandler)
+	passRepo := repo.NewPassRepo(dbCon, utilHandler)
 
 	//domains
 	usrSvc := user.NewService(userRepo)
-	routeSvc := route.NewService(routeRepo)
-	busSvc := bus.NewService(busRepo, ticketRepo)
+	fareSvc := fare.NewService(fareRepo)
+	routeSvc := route.NewService(routeRepo, fareSvc, route.CheckThresholds{
+		MinLengthMeters:  cnf.RouteCheck.MinLengthMeters,
+		StopOffsetMeters: cnf.RouteCheck.StopOffsetMeters,
+	})
+	studentSvc := student.NewService(studentRepo)
 	sslCommerz := payment.NewSSLCommerz(cnf.SSLCommerz)
 
 	// RabbitMQ
@@ -73,34 +94,74 @@ func Start() {
 	transactionSvc := transaction.NewService(transactionRepo, userRepo, sslCommerz, redisCon, cnf.PublicBaseURL)
 	transHandler := transactionHandler.NewHandler(transactionSvc, middlewareHandler, mngr, utilHandler)
 
-	ticketSvc := ticket.NewService(ticketRepo, userRepo, transactionRepo, redisCon, sslCommerz, rabbitMQ, ctx, cnf.PublicBaseURL)
+	passSvc := pass.NewService(passRepo, userRepo, transactionRepo, sslCommerz, cnf.PublicBaseURL)
+
+	scheduleSvc := schedule.NewService(repo.NewScheduleRepo(dbCon, utilHandler), cnf.GTFS.Timezone)
+	tripSvc := trip.NewService(repo.NewTripRepo(dbCon, utilHandler), scheduleSvc, redisCon, ctx)
+	busSvc := bus.NewService(busRepo, ticketRepo, fareSvc, passSvc, userRepo, tripSvc)
+	ticketSvc := ticket.NewService(ticketRepo, fareSvc, passSvc, userRepo, transactionRepo, redisCon, sslCommerz, rabbitMQ, ctx, cnf.PublicBaseURL, ticket.FareCaps{Daily: cnf.FareCaps.Daily, Weekly: cnf.FareCaps.Weekly}, ticket.TransferPolicy{
+		DiscountPercent: cnf.Transfer.DiscountPercent,
+		Window:          time.Duration(cnf.Transfer.WindowMinutes) * time.Minute,
+	}, tripSvc)
 
 	// Start Ticket Worker
 	// Start Ticket Worker
 	ticketWorker := ticket.NewTicketWorker(ticketSvc, rabbitMQ)
 	go ticketWorker.Start()
 
+	// Put scheduled route versions into effect
+	routeVersionJob := route.NewVersionJob(routeSvc)
+	go routeVersionJob.Start()
+
+	// Charge the maximum fare for RFID journeys that were never tapped off
+	journeyTimeoutJob := ticket.NewJourneyTimeoutJob(ticketSvc)
+	go journeyTimeoutJob.Start()
+
 	// Start Ticket Check Worker
 	ticketCheckWorker := ticket.NewTicketCheckWorker(ticketSvc, ticketRepo, rabbitMQ)
 	go ticketCheckWorker.Start()
 
 	// WebSocket Hub
-	hub := location.NewHub()
+	locationBroker := location.NewMemoryBroker()
+	if cnf.Location.Broker == "redis" {
+		locationBroker = location.NewRedisBroker(redisCon, ctx)
+	}
+	stopDetector := location.NewStopDetector(repo.NewStopEventRepo(dbCon, utilHandler), redisCon, ctx)
+	hub := location.NewHub(locationBroker, location.NewRedisPositionStore(redisCon, ctx), stopDetector)
+	etaSvc := eta.NewService(repo.NewETARepo(dbCon, utilHandler), hub, tripSvc)
+	alertSvc := alert.NewService(repo.NewBusAlertRepo(dbCon, utilHandler), hub, alert.Thresholds{
+		OffRouteMeters:  cnf.Alerts.OffRouteMeters,
+		OffRouteUpdates: cnf.Alerts.OffRouteUpdates,
+		SpeedLimit:      cnf.Alerts.SpeedLimit,
+	}, redisCon, ctx)
+	hub.AddObserver(tripSvc)
+	hub.SetCrowding(tripSvc)
+	hub.AddObserver(alertSvc)
+	hub.AddObserver(etaSvc)
 	go hub.Run()
 
-	userHdlr := userHandler.NewHandler(usrSvc, middlewareHandler, mngr, utilHandler, redisCon, ctx, hub)
-	routeHdlr := routeHandler.NewHandler(routeSvc, middlewareHandler, mngr, utilHandler)
-	busHdlr := busHandler.NewHandler(busSvc, ticketSvc, middlewareHandler, mngr, utilHandler, hub)
+	userHdlr := userHandler.NewHandler(usrSvc, studentSvc, middlewareHandler, mngr, utilHandler, redisCon, ctx, hub)
+	routeHdlr := routeHandler.NewHandler(routeSvc, etaSvc, middlewareHandler, mngr, utilHandler, hub)
+	busHdlr := busHandler.NewHandler(busSvc, ticketSvc, tripSvc, middlewareHandler, mngr, utilHandler, hub)
 	ticketHdlr := ticketHandler.NewHandler(ticketSvc, middlewareHandler, mngr, utilHandler, cnf.PublicBaseURL)
 
 	busOwnerRepo := repo.NewBusOwnerRepo(dbCon.DB, utilHandler)
 	busOwnerSvc := bus_owner.NewService(busOwnerRepo, busRepo, ticketRepo, routeRepo, utilHandler)
-	busOwnerHdlr := busOwnerHandler.NewHandler(busOwnerSvc, middlewareHandler, mngr, utilHandler)
+	busOwnerHdlr := busOwnerHandler.NewHandler(busOwnerSvc, tripSvc, alertSvc, middlewareHandler, mngr, utilHandler, hub)
 
 	adminRepo := repo.NewAdminRepo(dbCon.DB)
-	adminSvc := admin.NewService(adminRepo, utilHandler)
+	gtfsSvc := gtfs.NewService(routeRepo, routeSvc, fareSvc, scheduleSvc, gtfs.Agency{
+		Name:     cnf.ServiceName,
+		URL:      cnf.GTFS.AgencyURL,
+		Timezone: cnf.GTFS.Timezone,
+		Currency: cnf.GTFS.Currency,
+	})
+	adminSvc := admin.NewService(adminRepo, fareSvc, routeSvc, studentSvc, passSvc, gtfsSvc, alertSvc, utilHandler)
 	adminHdlr := adminHandler.NewHandler(adminSvc, utilHandler, middlewareHandler, mngr)
 
-	handler := rest.NewHandler(cnf, middlewareHandler, userHdlr, routeHdlr, busHdlr, ticketHdlr, transHandler, busOwnerHdlr, adminHdlr)
+	passHdlr := passHandler.NewHandler(passSvc, middlewareHandler, mngr, utilHandler)
+	scheduleHdlr := scheduleHandler.NewHandler(scheduleSvc, middlewareHandler, mngr, utilHandler)
+
+	handler := rest.NewHandler(cnf, middlewareHandler, userHdlr, routeHdlr, busHdlr, ticketHdlr, transHandler, busOwnerHdlr, adminHdlr, passHdlr, scheduleHdlr)
 	handler.Serve()
 }
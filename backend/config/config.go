@@ -34,6 +34,50 @@ type RabbitMQConfig struct {
 	URL string
 }
 
+// FareCapConfig limits how much an RFID rider is charged per period. A zero
+// cap disables it.
+type FareCapConfig struct {
+	Daily  float64
+	Weekly float64
+}
+
+// TransferConfig prices multi-leg journeys: legs after the first get the
+// discount when boarded within the window after the previous leg.
+type TransferConfig struct {
+	DiscountPercent float64
+	WindowMinutes   int
+}
+
+// GTFSConfig describes the agency in the exported GTFS feed.
+type GTFSConfig struct {
+	AgencyURL string
+	Timezone  string
+	Currency  string
+}
+
+// LocationConfig picks the broker behind the location hub: "memory" for a
+// single instance, "redis" when several API instances share riders and buses.
+type LocationConfig struct {
+	Broker string
+}
+
+// AlertConfig sets when buses are flagged to their owners: off-route after
+// OffRouteUpdates consecutive positions farther than OffRouteMeters from the
+// route, overspeed above SpeedLimit km/h.
+type AlertConfig struct {
+	OffRouteMeters  float64
+	OffRouteUpdates int
+	SpeedLimit      float64
+}
+
+// RouteCheckConfig sets how new and edited routes are checked: lines shorter
+// than MinLengthMeters are rejected, stops farther than StopOffsetMeters from
+// the line are snapped with a warning.
+type RouteCheckConfig struct {
+	MinLengthMeters  float64
+	StopOffsetMeters float64
+}
+
 type Config struct {
 	Version       string
 	HttpPort      string
@@ -44,6 +88,12 @@ type Config struct {
 	RedisCnf      RedisConfig
 	SSLCommerz    SSLCommerzConfig
 	RabbitMQ      RabbitMQConfig
+	FareCaps      FareCapConfig
+	Transfer      TransferConfig
+	GTFS          GTFSConfig
+	Location      LocationConfig
+	Alerts        AlertConfig
+	RouteCheck    RouteCheckConfig
 }
 
 var configurations *Config
@@ -106,6 +156,83 @@ func loadConfig() {
 		publicBaseURL = fmt.Sprintf("http://localhost:%s", httpPort)
 	}
 
+	dailyCap, err := parseFloatEnv("RFID_DAILY_CAP", 0)
+	if err != nil {
+		fmt.Println("Invalid RFID_DAILY_CAP value in .env")
+		os.Exit(1)
+	}
+	weeklyCap, err := parseFloatEnv("RFID_WEEKLY_CAP", 0)
+	if err != nil {
+		fmt.Println("Invalid RFID_WEEKLY_CAP value in .env")
+		os.Exit(1)
+	}
+
+	transferDiscount, err := parseFloatEnv("TRANSFER_DISCOUNT_PERCENT", 50)
+	if err != nil || transferDiscount > 100 {
+		fmt.Println("Invalid TRANSFER_DISCOUNT_PERCENT value in .env")
+		os.Exit(1)
+	}
+	transferWindow := 60
+	if val := os.Getenv("TRANSFER_WINDOW_MINUTES"); val != "" {
+		transferWindow, err = strconv.Atoi(val)
+		if err != nil || transferWindow <= 0 {
+			fmt.Println("Invalid TRANSFER_WINDOW_MINUTES value in .env")
+			os.Exit(1)
+		}
+	}
+
+	gtfsAgencyURL := os.Getenv("GTFS_AGENCY_URL")
+	if gtfsAgencyURL == "" {
+		gtfsAgencyURL = publicBaseURL
+	}
+	gtfsTimezone := os.Getenv("GTFS_TIMEZONE")
+	if gtfsTimezone == "" {
+		gtfsTimezone = "Asia/Dhaka"
+	}
+	gtfsCurrency := os.Getenv("GTFS_CURRENCY")
+	if gtfsCurrency == "" {
+		gtfsCurrency = "BDT"
+	}
+
+	locationBroker := os.Getenv("LOCATION_BROKER")
+	if locationBroker == "" {
+		locationBroker = "memory"
+	}
+	if locationBroker != "memory" && locationBroker != "redis" {
+		fmt.Println("Invalid LOCATION_BROKER value in .env")
+		os.Exit(1)
+	}
+
+	offRouteMeters, err := parseFloatEnv("ALERT_OFF_ROUTE_METERS", 150)
+	if err != nil || offRouteMeters == 0 {
+		fmt.Println("Invalid ALERT_OFF_ROUTE_METERS value in .env")
+		os.Exit(1)
+	}
+	offRouteUpdates := 3
+	if val := os.Getenv("ALERT_OFF_ROUTE_UPDATES"); val != "" {
+		offRouteUpdates, err = strconv.Atoi(val)
+		if err != nil || offRouteUpdates <= 0 {
+			fmt.Println("Invalid ALERT_OFF_ROUTE_UPDATES value in .env")
+			os.Exit(1)
+		}
+	}
+	speedLimit, err := parseFloatEnv("ALERT_SPEED_LIMIT_KMPH", 60)
+	if err != nil || speedLimit == 0 {
+		fmt.Println("Invalid ALERT_SPEED_LIMIT_KMPH value in .env")
+		os.Exit(1)
+	}
+
+	minRouteLength, err := parseFloatEnv("ROUTE_MIN_LENGTH_METERS", 500)
+	if err != nil {
+		fmt.Println("Invalid ROUTE_MIN_LENGTH_METERS value in .env")
+		os.Exit(1)
+	}
+	stopOffset, err := parseFloatEnv("ROUTE_STOP_OFFSET_METERS", 50)
+	if err != nil || stopOffset == 0 {
+		fmt.Println("Invalid ROUTE_STOP_OFFSET_METERS value in .env")
+		os.Exit(1)
+	}
+
 	configurations = &Config{
 		Version:       version,
 		HttpPort:      httpPort,
@@ -133,7 +260,49 @@ func loadConfig() {
 		RabbitMQ: RabbitMQConfig{
 			URL: os.Getenv("RABBITMQ_URL"),
 		},
+		FareCaps: FareCapConfig{
+			Daily:  dailyCap,
+			Weekly: weeklyCap,
+		},
+		Transfer: TransferConfig{
+			DiscountPercent: transferDiscount,
+			WindowMinutes:   transferWindow,
+		},
+		GTFS: GTFSConfig{
+			AgencyURL: gtfsAgencyURL,
+			Timezone:  gtfsTimezone,
+			Currency:  gtfsCurrency,
+		},
+		Location: LocationConfig{
+			Broker: locationBroker,
+		},
+		Alerts: AlertConfig{
+			OffRouteMeters:  offRouteMeters,
+			OffRouteUpdates: offRouteUpdates,
+			SpeedLimit:      speedLimit,
+		},
+		RouteCheck: RouteCheckConfig{
+			MinLengthMeters:  minRouteLength,
+			StopOffsetMeters: stopOffset,
+		},
+	}
+}
+
+// parseFloatEnv reads a non-negative number from the environment, or def when
+// the variable is unset
+func parseFloatEnv(key string, def float64) (float64, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return def, nil
+	}
+	value, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, err
+	}
+	if value < 0 {
+		return 0, fmt.Errorf("%s is negative", key)
 	}
+	return value, nil
 }
 
 func Load() *Config {
@@ -2,9 +2,16 @@ package domain
 
 import "time"
 
+// Roles carried in the tokens of admins and bus owners, whose ids overlap
+const (
+	RoleAdmin    = "admin"
+	RoleBusOwner = "bus_owner"
+)
+
 type Admin struct {
 	Id        int64     `json:"id"`
 	Username  string    `json:"username"`
 	Password  string    `json:"-"` // Never send password in JSON
+	Role      string    `json:"role,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -1,11 +1,14 @@
 package domain
 
 type BusOwnerAnalytics struct {
-	TotalRevenue float64         `json:"total_revenue"`
-	TotalTickets int             `json:"total_tickets"`
-	Today        PeriodAnalytics `json:"today"`
-	Weekly       PeriodAnalytics `json:"weekly"`
-	Monthly      PeriodAnalytics `json:"monthly"`
+	TotalRevenue       float64         `json:"total_revenue"`
+	TotalTickets       int             `json:"total_tickets"`
+	ConcessionDiscount float64         `json:"concession_discount"`
+	PassBoardings      int             `json:"pass_boardings"`
+	PassRevenue        float64         `json:"pass_revenue"`
+	Today              PeriodAnalytics `json:"today"`
+	Weekly             PeriodAnalytics `json:"weekly"`
+	Monthly            PeriodAnalytics `json:"monthly"`
 }
 
 type PeriodAnalytics struct {
@@ -17,4 +20,7 @@ type BusAnalytics struct {
 	RegistrationNumber string  `json:"registration_number"`
 	Tickets            int     `json:"tickets"`
 	Revenue            float64 `json:"revenue"`
+	ConcessionDiscount float64 `json:"concession_discount"`
+	PassBoardings      int     `json:"pass_boardings"`
+	PassRevenue        float64 `json:"pass_revenue"`
 }
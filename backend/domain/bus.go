@@ -6,6 +6,11 @@ type Bus struct {
 	LineStringGeoJSON *LineString `json:"linestring_geojson" db:"linestring_geojson"` // Not stored directly, used for geom insertion
 	Fare              float64     `json:"fare" db:"fare"`
 	Stops             []Stop      `json:"stops"`
+	// Set when searching by coordinates: the chosen stops and the total walk
+	// in meters to the boarding stop and from the alighting stop
+	BoardingStop  string  `json:"boarding_stop,omitempty" db:"boarding_stop"`
+	AlightingStop string  `json:"alighting_stop,omitempty" db:"alighting_stop"`
+	WalkDistance  float64 `json:"walk_distance,omitempty" db:"walk_distance"`
 }
 
 type BusCredential struct {
@@ -15,4 +20,5 @@ type BusCredential struct {
 	RouteIdUp          int64  `json:"route_id_up" db:"route_id_up"`
 	RouteIdDown        int64  `json:"route_id_down" db:"route_id_down"`
 	OwnerId            *int64 `json:"owner_id" db:"owner_id"`
+	SeatCapacity       int    `json:"seat_capacity" db:"seat_capacity"` // 0 when unknown
 }
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+const (
+	AlertOffRoute  = "off_route"
+	AlertOverspeed = "overspeed"
+)
+
+// BusAlert flags a bus driving away from its route or above the speed limit.
+type BusAlert struct {
+	Id                 int64     `json:"id" db:"id"`
+	BusId              int64     `json:"bus_id" db:"bus_id"`
+	RegistrationNumber string    `json:"registration_number" db:"registration_number"`
+	OwnerId            *int64    `json:"owner_id" db:"owner_id"`
+	RouteId            int64     `json:"route_id" db:"route_id"`
+	Type               string    `json:"alert_type" db:"type"`
+	Message            string    `json:"message" db:"message"`
+	Latitude           float64   `json:"latitude" db:"latitude"`
+	Longitude          float64   `json:"longitude" db:"longitude"`
+	Speed              float64   `json:"speed" db:"speed"`                     // km/h
+	DistanceMeters     float64   `json:"distance_meters" db:"distance_meters"` // from the route, for off-route alerts
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+}
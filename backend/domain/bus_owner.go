@@ -6,5 +6,6 @@ type BusOwner struct {
 	Id        int64     `json:"id" db:"id"`
 	Username  string    `json:"username" db:"username"`
 	Password  string    `json:"-" db:"password"`
+	Role      string    `json:"role,omitempty" db:"-"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
@@ -0,0 +1,11 @@
+package domain
+
+type ConcessionRule struct {
+	Id              int64   `json:"id" db:"id"`
+	Name            string  `json:"name" db:"name"`
+	DiscountPercent float64 `json:"discount_percent" db:"discount_percent"`
+	ValidFrom       string  `json:"valid_from" db:"valid_from"`   // HH:MM, empty means all day
+	ValidUntil      string  `json:"valid_until" db:"valid_until"` // HH:MM
+	RouteIds        []int64 `json:"route_ids"`                    // empty means every route
+	Active          bool    `json:"active" db:"active"`
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// StopProgress places a stop on its route as the distance in meters from the
+// start of the route geometry.
+type StopProgress struct {
+	StopId        int64   `json:"stop_id" db:"stop_id"`
+	Name          string  `json:"name" db:"name"`
+	Order         int     `json:"order" db:"stop_order"`
+	DistanceAlong float64 `json:"distance_along" db:"distance_along"`
+}
+
+type StopETA struct {
+	StopId    int64     `json:"stop_id"`
+	StopName  string    `json:"stop_name"`
+	StopOrder int       `json:"stop_order"`
+	Distance  float64   `json:"distance"` // meters still to travel along the route
+	Seconds   int64     `json:"seconds"`
+	ArrivalAt time.Time `json:"arrival_at"`
+	// Set when the bus follows a scheduled run with a time at this stop
+	ScheduledAt  *time.Time `json:"scheduled_at,omitempty"`
+	DelaySeconds *int64     `json:"delay_seconds,omitempty"` // negative when early
+}
+
+// BusETA lists the estimated arrival of one bus at each stop still ahead of
+// it. DelaySeconds is how late it is expected at the next scheduled stop.
+type BusETA struct {
+	BusId              int64     `json:"bus_id"`
+	RouteId            int64     `json:"route_id"`
+	RegistrationNumber string    `json:"registration_number,omitempty"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	DelaySeconds       *int64    `json:"delay_seconds,omitempty"`
+	Stops              []StopETA `json:"stops"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+type FarePolicy struct {
+	RouteId      int64            `json:"route_id" db:"route_id"`
+	BaseFare     float64          `json:"base_fare" db:"base_fare"`
+	PerKmRate    float64          `json:"per_km_rate" db:"per_km_rate"`
+	MinFare      float64          `json:"min_fare" db:"min_fare"`
+	MaxFare      *float64         `json:"max_fare" db:"max_fare"`
+	RoundingMode string           `json:"rounding_mode" db:"rounding_mode"` // none, ceil, floor, nearest
+	RoundingStep float64          `json:"rounding_step" db:"rounding_step"`
+	FareTable    []FareTableEntry `json:"fare_table"`
+}
+
+type FareTableEntry struct {
+	RouteId  int64   `json:"route_id" db:"route_id"`
+	FromStop string  `json:"from_stop" db:"from_stop"`
+	ToStop   string  `json:"to_stop" db:"to_stop"`
+	Fare     float64 `json:"fare" db:"fare"`
+}
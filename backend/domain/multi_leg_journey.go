@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// MultiLegJourney groups the tickets of a trip that changes buses. Each leg is
+// a regular ticket with its own QR code; the transfer terms are stored on the
+// journey so later config changes do not affect tickets already sold.
+type MultiLegJourney struct {
+	Id                      int64     `json:"id" db:"id"`
+	UserId                  int64     `json:"user_id" db:"user_id"`
+	TotalFare               float64   `json:"total_fare" db:"total_fare"`
+	TransferDiscountPercent float64   `json:"transfer_discount_percent" db:"transfer_discount_percent"`
+	TransferWindowMinutes   int       `json:"transfer_window_minutes" db:"transfer_window_minutes"`
+	CreatedAt               time.Time `json:"created_at" db:"created_at"`
+	Legs                    []Ticket  `json:"legs"`
+}
@@ -0,0 +1,7 @@
+package domain
+
+// Search radius for stops and buses near a point
+const (
+	DefaultNearbyRadius = 500  // meters
+	MaxNearbyRadius     = 5000 // meters
+)
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+const (
+	PassPeriodDaily   = "DAILY"
+	PassPeriodWeekly  = "WEEKLY"
+	PassPeriodMonthly = "MONTHLY"
+)
+
+// PassPlan is a purchasable pass product. A nil RouteId makes it valid on
+// every route.
+type PassPlan struct {
+	Id      int64   `json:"id" db:"id"`
+	Name    string  `json:"name" db:"name"`
+	Period  string  `json:"period" db:"period"` // DAILY, WEEKLY, MONTHLY
+	RouteId *int64  `json:"route_id" db:"route_id"`
+	Price   float64 `json:"price" db:"price"`
+	Active  bool    `json:"active" db:"active"`
+}
+
+type Pass struct {
+	Id            int64      `json:"id" db:"id"`
+	UserId        int64      `json:"user_id" db:"user_id"`
+	PlanId        int64      `json:"plan_id" db:"plan_id"`
+	PlanName      string     `json:"plan_name" db:"plan_name"`
+	Period        string     `json:"period" db:"period"`
+	RouteId       *int64     `json:"route_id" db:"route_id"`
+	Price         float64    `json:"price" db:"price"`
+	QRCode        string     `json:"qr_code" db:"qr_code"`
+	PaymentMethod string     `json:"payment_method" db:"payment_method"`
+	PaymentStatus string     `json:"payment_status" db:"payment_status"` // pending, paid, cancelled
+	TranId        *string    `json:"-" db:"tran_id"`
+	ValidFrom     *time.Time `json:"valid_from" db:"valid_from"`
+	ValidUntil    *time.Time `json:"valid_until" db:"valid_until"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PassUsage records one boarding made with a pass so the bus that carried the
+// rider is credited for it.
+type PassUsage struct {
+	Id                 int64     `json:"id" db:"id"`
+	PassId             int64     `json:"pass_id" db:"pass_id"`
+	UserId             int64     `json:"user_id" db:"user_id"`
+	RouteId            int64     `json:"route_id" db:"route_id"`
+	RegistrationNumber string    `json:"registration_number" db:"registration_number"`
+	Stop               string    `json:"stop" db:"stop"`
+	Source             string    `json:"source" db:"source"` // QR, RFID
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+const (
+	JourneyOpen     = "OPEN"
+	JourneyClosed   = "CLOSED"
+	JourneyTimedOut = "TIMED_OUT"
+)
+
+// RFIDJourney is a tap-on/tap-off trip. HoldAmount is taken from the wallet at
+// tap-on and the difference to the settled fare is refunded at tap-off.
+type RFIDJourney struct {
+	Id           int64      `json:"id" db:"id"`
+	UserId       int64      `json:"user_id" db:"user_id"`
+	RouteId      int64      `json:"route_id" db:"route_id"`
+	BusName      string     `json:"bus_name" db:"bus_name"`
+	StartStop    string     `json:"start_stop" db:"start_stop"`
+	EndStop      *string    `json:"end_stop" db:"end_stop"`
+	HoldAmount   float64    `json:"hold_amount" db:"hold_amount"`
+	HoldDiscount float64    `json:"hold_discount" db:"hold_discount"`
+	Fare         *float64   `json:"fare" db:"fare"`
+	Status       string     `json:"status" db:"status"`
+	TicketId     *int64     `json:"ticket_id" db:"ticket_id"`
+	TappedOnAt   time.Time  `json:"tapped_on_at" db:"tapped_on_at"`
+	TappedOffAt  *time.Time `json:"tapped_off_at" db:"tapped_off_at"`
+}
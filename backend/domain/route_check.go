@@ -0,0 +1,32 @@
+package domain
+
+// RouteCheck reports on a new route before it is saved. Stops are snapped
+// onto the line and listed in the order they lie along it. Errors keep the
+// route from being saved; warnings do not.
+type RouteCheck struct {
+	Valid        bool          `json:"valid"`
+	LengthMeters float64       `json:"length_meters"`
+	Errors       []string      `json:"errors"`
+	Warnings     []string      `json:"warnings"`
+	Stops        []SnappedStop `json:"stops"`
+}
+
+// SnappedStop is a stop moved to the nearest point of the route line. Order
+// is its place along the line and GivenOrder the place it was sent in.
+// Offset is how far, in meters, it was moved.
+type SnappedStop struct {
+	Stop
+	GivenOrder    int     `json:"given_order"`
+	Position      float64 `json:"position"`       // fraction of the line's length
+	DistanceAlong float64 `json:"distance_along"` // meters from the line's start
+	Offset        float64 `json:"offset"`
+}
+
+// RouteStops returns the snapped stops in their order along the line.
+func (c *RouteCheck) RouteStops() []Stop {
+	stops := make([]Stop, len(c.Stops))
+	for i, stop := range c.Stops {
+		stops[i] = stop.Stop
+	}
+	return stops
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// RouteVersion is one edit of a route's name, geometry and stop list. It
+// replaces the route's live geometry and stops once EffectiveFrom passes;
+// AppliedAt stays nil until then. Tickets keep the version they were sold on.
+type RouteVersion struct {
+	Id                int64       `json:"id" db:"id"`
+	RouteId           int64       `json:"route_id" db:"route_id"`
+	Version           int         `json:"version" db:"version"`
+	Name              string      `json:"name" db:"name"`
+	LineStringGeoJSON *LineString `json:"linestring_geojson,omitempty" db:"linestring_geojson"`
+	Stops             []Stop      `json:"stops,omitempty" db:"-"`
+	StopCount         int         `json:"stop_count" db:"stop_count"`
+	EffectiveFrom     time.Time   `json:"effective_from" db:"effective_from"`
+	AppliedAt         *time.Time  `json:"applied_at" db:"applied_at"`
+	CreatedAt         time.Time   `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	ExceptionAdded   = "added"
+	ExceptionRemoved = "removed"
+)
+
+// DateLayout is how service dates are written in requests and responses.
+const DateLayout = "2006-01-02"
+
+// ServiceTime is a time of a service day in seconds after its midnight,
+// written as "HH:MM:SS". Like GTFS stop times it may pass 24:00:00 for trips
+// that run past midnight.
+type ServiceTime int
+
+func ParseServiceTime(s string) (ServiceTime, error) {
+	var h, m, sec int
+	if n, err := fmt.Sscanf(s, "%d:%d:%d", &h, &m, &sec); err != nil || n != 3 {
+		if n, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || n != 2 {
+			return 0, fmt.Errorf("invalid time %q, expected HH:MM:SS", s)
+		}
+	}
+	if h < 0 || h > 47 || m < 0 || m > 59 || sec < 0 || sec > 59 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM:SS", s)
+	}
+	return ServiceTime(h*3600 + m*60 + sec), nil
+}
+
+func (t ServiceTime) String() string {
+	return fmt.Sprintf("%02d:%02d:%02d", t/3600, t%3600/60, t%60)
+}
+
+func (t ServiceTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+func (t *ServiceTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseServiceTime(s)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// On returns the clock time t falls at on the service day starting at
+// midnight of date.
+func (t ServiceTime) On(date time.Time) time.Time {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, 0, int(t), 0, date.Location())
+}
+
+// ServiceCalendar says which days scheduled trips run on. A nil OwnerId
+// marks a calendar shared by every operator.
+type ServiceCalendar struct {
+	Id         int64               `json:"id" db:"id"`
+	Name       string              `json:"name" db:"name"`
+	OwnerId    *int64              `json:"owner_id" db:"owner_id"`
+	Monday     bool                `json:"monday" db:"monday"`
+	Tuesday    bool                `json:"tuesday" db:"tuesday"`
+	Wednesday  bool                `json:"wednesday" db:"wednesday"`
+	Thursday   bool                `json:"thursday" db:"thursday"`
+	Friday     bool                `json:"friday" db:"friday"`
+	Saturday   bool                `json:"saturday" db:"saturday"`
+	Sunday     bool                `json:"sunday" db:"sunday"`
+	StartDate  string              `json:"start_date" db:"start_date"`
+	EndDate    string              `json:"end_date" db:"end_date"`
+	Exceptions []CalendarException `json:"exceptions" db:"-"`
+}
+
+// CalendarException adds or removes service on one date, such as a holiday.
+type CalendarException struct {
+	Id         int64  `json:"id" db:"id"`
+	CalendarId int64  `json:"calendar_id" db:"calendar_id"`
+	Date       string `json:"date" db:"date"`
+	Type       string `json:"type" db:"type"`
+	Note       string `json:"note" db:"note"`
+}
+
+// RunsOn reports whether the calendar has service on the date, given as
+// DateLayout. Exceptions override the weekly pattern.
+func (c ServiceCalendar) RunsOn(date string) bool {
+	for _, e := range c.Exceptions {
+		if e.Date == date {
+			return e.Type == ExceptionAdded
+		}
+	}
+	if date < c.StartDate || date > c.EndDate {
+		return false
+	}
+	d, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return false
+	}
+	return [...]bool{c.Sunday, c.Monday, c.Tuesday, c.Wednesday, c.Thursday, c.Friday, c.Saturday}[d.Weekday()]
+}
+
+// ScheduledTrip is one timetabled run of a route variant.
+type ScheduledTrip struct {
+	Id           int64               `json:"id" db:"id"`
+	RouteId      int64               `json:"route_id" db:"route_id"`
+	CalendarId   int64               `json:"calendar_id" db:"calendar_id"`
+	CalendarName string              `json:"calendar_name" db:"calendar_name"`
+	OwnerId      *int64              `json:"owner_id" db:"owner_id"`
+	Headsign     string              `json:"headsign" db:"headsign"`
+	StopTimes    []ScheduledStopTime `json:"stop_times" db:"-"`
+}
+
+type ScheduledStopTime struct {
+	ScheduledTripId int64       `json:"-" db:"scheduled_trip_id"`
+	StopId          int64       `json:"stop_id" db:"stop_id"`
+	StopName        string      `json:"stop_name" db:"stop_name"`
+	StopOrder       int         `json:"stop_order" db:"stop_order"`
+	Arrival         ServiceTime `json:"arrival" db:"arrival_seconds"`
+	Departure       ServiceTime `json:"departure" db:"departure_seconds"`
+}
+
+// Timetable lists the scheduled trips of a route running on one date, by
+// first departure.
+type Timetable struct {
+	RouteId int64           `json:"route_id"`
+	Date    string          `json:"date"`
+	Trips   []ScheduledTrip `json:"trips"`
+}
+
+// ScheduledRun is a scheduled trip on one service date, with the clock time
+// it is due at each stop by stop order.
+type ScheduledRun struct {
+	ScheduledTripId int64
+	RouteId         int64
+	ServiceDate     string
+	Arrivals        map[int]time.Time
+}
@@ -0,0 +1,34 @@
+package domain
+
+// Station is one physical stop, shared by every route that stops there.
+type Station struct {
+	Id     int64         `json:"id" db:"id"`
+	Name   string        `json:"name" db:"name"`
+	Lon    float64       `json:"lon" db:"lon"`
+	Lat    float64       `json:"lat" db:"lat"`
+	Routes []StationStop `json:"routes" db:"-"`
+}
+
+// StationStop is a route's stop at a station.
+type StationStop struct {
+	StationId int64  `json:"-" db:"station_id"`
+	StopId    int64  `json:"stop_id" db:"stop_id"`
+	RouteId   int64  `json:"route_id" db:"route_id"`
+	RouteName string `json:"route_name" db:"route_name"`
+	Order     int    `json:"order" db:"stop_order"`
+}
+
+// NearbyStation is a station found by location. Distance is in meters to the
+// closest of its stops and is zero when the point lies inside a stop's area.
+type NearbyStation struct {
+	Station
+	Distance float64 `json:"distance" db:"distance"`
+}
+
+// StationActivity counts the paid tickets boarding and leaving at a station.
+type StationActivity struct {
+	StationId  int64  `json:"station_id"`
+	Name       string `json:"name"`
+	Boardings  int    `json:"boardings"`
+	Alightings int    `json:"alightings"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+const (
+	StopArrived  = "arrived"
+	StopDeparted = "departed"
+)
+
+// StopEvent records a bus entering or leaving a stop's area. StopId is
+// cleared if the stop is later removed; StopName keeps the record readable.
+type StopEvent struct {
+	Id         int64     `json:"id" db:"id"`
+	BusId      int64     `json:"bus_id" db:"bus_id"`
+	RouteId    int64     `json:"route_id" db:"route_id"`
+	StopId     *int64    `json:"stop_id" db:"stop_id"`
+	StopName   string    `json:"stop_name" db:"stop_name"`
+	StopOrder  int       `json:"stop_order" db:"stop_order"`
+	Event      string    `json:"event" db:"event"`
+	OccurredAt time.Time `json:"occurred_at" db:"occurred_at"`
+}
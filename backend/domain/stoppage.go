@@ -35,12 +35,15 @@ func (p Polygon) Value() (driver.Value, error) {
 	return string(b), err
 }
 
+// Stop is a route's stop at a station. Routes serving the same place have
+// their own stops, in their own order, at one station.
 type Stop struct {
-	Id       int64    `json:"id" db:"id"`
-	RouteId  int64    `json:"route_id" db:"route_id"`
-	Name     string   `json:"name" db:"name"`
-	Order    int      `json:"order" db:"stop_order"`
-	Lon      float64  `json:"lon" db:"lon"`
-	Lat      float64  `json:"lat" db:"lat"`
-	AreaGeom *Polygon `json:"area_geom" db:"area_geom"`
+	Id        int64    `json:"id" db:"id"`
+	RouteId   int64    `json:"route_id" db:"route_id"`
+	StationId *int64   `json:"station_id" db:"station_id"`
+	Name      string   `json:"name" db:"name"`
+	Order     int      `json:"order" db:"stop_order"`
+	Lon       float64  `json:"lon" db:"lon"`
+	Lat       float64  `json:"lat" db:"lat"`
+	AreaGeom  *Polygon `json:"area_geom" db:"area_geom"`
 }
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+const (
+	StudentVerificationPending  = "PENDING"
+	StudentVerificationApproved = "APPROVED"
+	StudentVerificationRejected = "REJECTED"
+	StudentVerificationExpired  = "EXPIRED"
+)
+
+type StudentVerification struct {
+	Id            int64      `json:"id" db:"id"`
+	UserId        int64      `json:"user_id" db:"user_id"`
+	InstitutionId string     `json:"institution_id" db:"institution_id"`
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	Status        string     `json:"status" db:"status"`
+	Note          string     `json:"note" db:"note"`
+	ReviewedBy    *int64     `json:"reviewed_by" db:"reviewed_by"`
+	ReviewedAt    *time.Time `json:"reviewed_at" db:"reviewed_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UserName      string     `json:"user_name,omitempty" db:"user_name"`
+	UserEmail     string     `json:"user_email,omitempty" db:"user_email"`
+}
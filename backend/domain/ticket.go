@@ -1,5 +1,9 @@
 package domain
 
+// TicketStatusCapped marks an RFID ticket issued at no charge because the
+// rider reached a fare cap
+const TicketStatusCapped = "CAPPED"
+
 type Ticket struct {
 	Id                 int64   `json:"id" db:"id"`
 	UserId             int64   `json:"user_id" db:"user_id"`
@@ -8,6 +12,7 @@ type Ticket struct {
 	StartDestination   string  `json:"start_destination" db:"start_destination"`
 	EndDestination     string  `json:"end_destination" db:"end_destination"`
 	Fare               float64 `json:"fare" db:"fare"`
+	Discount           float64 `json:"discount" db:"discount"`
 	PaidStatus         bool    `json:"paid_status" db:"paid_status"`
 	Checked            bool    `json:"checked" db:"checked"`
 	QRCode             string  `json:"qr_code" db:"qr_code"`
@@ -19,4 +24,9 @@ type Ticket struct {
 	PaymentStatus      string  `json:"payment_status" db:"payment_status"`
 	CancelledAt        *string `json:"cancelled_at,omitempty" db:"cancelled_at"`
 	RegistrationNumber *string `json:"registration_number" db:"registration_number"`
+	JourneyId          *int64  `json:"journey_id,omitempty" db:"journey_id"`
+	LegIndex           *int    `json:"leg_index,omitempty" db:"leg_index"`
+	TransferDiscount   float64 `json:"transfer_discount" db:"transfer_discount"`
+	CheckedAt          *string `json:"checked_at,omitempty" db:"checked_at"`
+	RouteVersionId     *int64  `json:"route_version_id,omitempty" db:"route_version_id"` // the route version the ticket was sold on
 }
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+const (
+	TripActive    = "active"
+	TripCompleted = "completed"
+)
+
+// Trip is one run of a bus along a route variant. Path is built from the
+// recorded points and stays nil until the trip has at least two of them.
+// Trips matched to a scheduled run record how late they were at each stop.
+type Trip struct {
+	Id                 int64       `json:"id" db:"id"`
+	BusId              int64       `json:"bus_id" db:"bus_id"`
+	RegistrationNumber string      `json:"registration_number" db:"registration_number"`
+	RouteId            int64       `json:"route_id" db:"route_id"`
+	Variant            string      `json:"variant" db:"variant"`
+	DriverName         string      `json:"driver_name" db:"driver_name"`
+	SeatCapacity       int         `json:"seat_capacity" db:"seat_capacity"`
+	Status             string      `json:"status" db:"status"`
+	StartedAt          time.Time   `json:"started_at" db:"started_at"`
+	EndedAt            *time.Time  `json:"ended_at" db:"ended_at"`
+	DistanceMeters     float64     `json:"distance_meters" db:"distance_meters"`
+	ScheduledTripId    *int64      `json:"scheduled_trip_id" db:"scheduled_trip_id"`
+	ServiceDate        *string     `json:"service_date" db:"service_date"`
+	DelaySeconds       *int        `json:"delay_seconds" db:"delay_seconds"` // at the last stop reached; negative when early
+	PointCount         int         `json:"point_count" db:"point_count"`
+	Path               *LineString `json:"path,omitempty" db:"path"`
+}
+
+type TripPoint struct {
+	Latitude   float64   `json:"latitude" db:"lat"`
+	Longitude  float64   `json:"longitude" db:"lon"`
+	Speed      float64   `json:"speed" db:"speed"` // km/h
+	RecordedAt time.Time `json:"recorded_at" db:"recorded_at"`
+}
+
+// TripTicketCheck is a ticket checked on board during a trip.
+type TripTicketCheck struct {
+	TicketId         int64     `json:"ticket_id" db:"id"`
+	BusId            int64     `json:"bus_id" db:"-"`
+	RouteId          int64     `json:"route_id" db:"route_id"`
+	StartDestination string    `json:"start_destination" db:"start_destination"`
+	EndDestination   string    `json:"end_destination" db:"end_destination"`
+	CheckedAt        time.Time `json:"checked_at" db:"checked_at"`
+}
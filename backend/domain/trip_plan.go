@@ -0,0 +1,30 @@
+package domain
+
+// TransferPoint links a stop on one route to a stop on another route that a
+// rider can change at, either because both stops have the same name or
+// because they are within walking distance of each other.
+type TransferPoint struct {
+	FromRouteId  int64   `json:"from_route_id" db:"from_route_id"`
+	FromStop     string  `json:"from_stop" db:"from_stop"`
+	ToRouteId    int64   `json:"to_route_id" db:"to_route_id"`
+	ToStop       string  `json:"to_stop" db:"to_stop"`
+	WalkDistance float64 `json:"walk_distance" db:"walk_distance"` // meters
+}
+
+type ItineraryLeg struct {
+	RouteId   int64   `json:"route_id"`
+	RouteName string  `json:"route_name"`
+	From      string  `json:"from"`
+	To        string  `json:"to"`
+	Stops     int     `json:"stops"`
+	Fare      float64 `json:"fare"`
+	// WalkDistance is the walk in meters to the next leg's boarding stop
+	WalkDistance float64 `json:"walk_distance"`
+}
+
+type Itinerary struct {
+	Legs         []ItineraryLeg `json:"legs"`
+	Transfers    int            `json:"transfers"`
+	TotalFare    float64        `json:"total_fare"`
+	WalkDistance float64        `json:"walk_distance"` // meters
+}
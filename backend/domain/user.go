@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // model or entity
 type User struct {
 	Id           int64   `json:"id" db:"id"`
@@ -12,4 +14,7 @@ type User struct {
 	Balance      float32 `json:"balance" db:"balance"`
 	RFID         *string `json:"rfid" db:"rfid"`
 	IsRFIDActive bool    `json:"is_rfid_active" db:"is_rfid_active"`
+	// StudentVerifiedUntil is set when an admin approves a student
+	// verification; concessions apply only until this date.
+	StudentVerifiedUntil *time.Time `json:"student_verified_until" db:"student_verified_until"`
 }
@@ -0,0 +1,27 @@
+package eta
+
+import (
+	"swift_transit/domain"
+	"swift_transit/location"
+	"time"
+)
+
+type Service interface {
+	// RouteETAs estimates, for every active bus on the route, its arrival at
+	// the stops ahead of it; stop limits the result to one stop by name
+	RouteETAs(routeID int64, stop string) ([]domain.BusETA, error)
+	// ObserveLocation pushes fresh ETAs for a bus to the route's subscribers
+	ObserveLocation(update location.LocationUpdate)
+}
+
+// Schedules gives the timetabled run a bus follows, or nil when it follows
+// none.
+type Schedules interface {
+	ScheduledRun(busId int64) (*domain.ScheduledRun, error)
+}
+
+type ETARepo interface {
+	GetStopProgress(routeId int64) ([]domain.StopProgress, error)
+	GetBusProgress(routeId int64, lat, lon float64) (float64, error)
+	GetSegmentTimes(routeId int64, since time.Time) (map[int]float64, error)
+}
@@ -0,0 +1,230 @@
+package eta
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"swift_transit/domain"
+	"swift_transit/location"
+	"sync"
+	"time"
+)
+
+const (
+	// Stop-to-stop travel times are averaged over this much history
+	historyWindow = 30 * 24 * time.Hour
+	// Stop positions and travel times of a route are reloaded after this long
+	profileTTL = 10 * time.Minute
+	// Assumed when a bus reports no speed and there is no history
+	defaultSpeedKmph = 15.0
+	// A bus this many meters past a stop is still treated as at the stop
+	stopTolerance = 30.0
+)
+
+// routeProfile is what is needed from the database to estimate a route,
+// cached so each location update costs a single query.
+type routeProfile struct {
+	stops    []domain.StopProgress
+	segments map[int]float64
+	loadedAt time.Time
+}
+
+type service struct {
+	repo      ETARepo
+	hub       *location.Hub
+	schedules Schedules
+
+	mu       sync.Mutex
+	profiles map[int64]*routeProfile
+}
+
+func NewService(repo ETARepo, hub *location.Hub, schedules Schedules) Service {
+	return &service{
+		repo:      repo,
+		hub:       hub,
+		schedules: schedules,
+		profiles:  make(map[int64]*routeProfile),
+	}
+}
+
+func (s *service) profile(routeID int64) (*routeProfile, error) {
+	s.mu.Lock()
+	p, ok := s.profiles[routeID]
+	s.mu.Unlock()
+	if ok && time.Since(p.loadedAt) < profileTTL {
+		return p, nil
+	}
+
+	stops, err := s.repo.GetStopProgress(routeID)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := s.repo.GetSegmentTimes(routeID, time.Now().Add(-historyWindow))
+	if err != nil {
+		return nil, err
+	}
+	p = &routeProfile{stops: stops, segments: segments, loadedAt: time.Now()}
+
+	s.mu.Lock()
+	s.profiles[routeID] = p
+	s.mu.Unlock()
+	return p, nil
+}
+
+func (s *service) RouteETAs(routeID int64, stop string) ([]domain.BusETA, error) {
+	p, err := s.profile(routeID)
+	if err != nil {
+		return nil, err
+	}
+	if stop != "" && !hasStop(p.stops, stop) {
+		return nil, fmt.Errorf("stop not found on route")
+	}
+
+	positions, err := s.hub.RoutePositions(routeID)
+	if err != nil {
+		return nil, err
+	}
+
+	etas := []domain.BusETA{}
+	for _, pos := range positions {
+		eta, err := s.estimate(p, pos.LocationUpdate, pos.UpdatedAt)
+		if err != nil {
+			log.Printf("failed to estimate bus %d: %v", pos.BusID, err)
+			continue
+		}
+		if stop != "" {
+			eta.Stops = filterStop(eta.Stops, stop)
+			if len(eta.Stops) == 0 {
+				// Already past the stop
+				continue
+			}
+		}
+		etas = append(etas, *eta)
+	}
+
+	if stop != "" {
+		sort.Slice(etas, func(i, j int) bool { return etas[i].Stops[0].ArrivalAt.Before(etas[j].Stops[0].ArrivalAt) })
+	}
+	return etas, nil
+}
+
+func (s *service) ObserveLocation(update location.LocationUpdate) {
+	p, err := s.profile(update.RouteID)
+	if err != nil {
+		log.Printf("failed to load route %d for ETA: %v", update.RouteID, err)
+		return
+	}
+	if len(p.stops) == 0 {
+		return
+	}
+	eta, err := s.estimate(p, update, time.Now())
+	if err != nil {
+		log.Printf("failed to estimate bus %d: %v", update.BusID, err)
+		return
+	}
+	// Only subscribers that asked for "eta" frames receive them; older rider
+	// apps read every frame as a position
+	if err := s.hub.Publish(update.RouteID, location.MessageETA, eta); err != nil {
+		log.Printf("failed to publish ETA: %v", err)
+	}
+}
+
+// estimate walks the stops ahead of the bus. Each stop-to-stop segment takes
+// its historical average time, scaled for the part of it left to travel; a
+// segment without history is driven at the bus's current speed.
+func (s *service) estimate(p *routeProfile, update location.LocationUpdate, at time.Time) (*domain.BusETA, error) {
+	along, err := s.repo.GetBusProgress(update.RouteID, update.Latitude, update.Longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	speed := update.Speed
+	if speed <= 0 {
+		speed = defaultSpeedKmph
+	}
+	metersPerSecond := speed / 3.6
+
+	eta := &domain.BusETA{
+		BusId:              update.BusID,
+		RouteId:            update.RouteID,
+		RegistrationNumber: update.RegistrationNumber,
+		UpdatedAt:          at,
+		Stops:              []domain.StopETA{},
+	}
+
+	pos := along
+	var elapsed float64
+	for i, stop := range p.stops {
+		if stop.DistanceAlong < along-stopTolerance {
+			continue
+		}
+
+		remaining := math.Max(stop.DistanceAlong-pos, 0)
+		seconds := remaining / metersPerSecond
+		if i > 0 {
+			prev := p.stops[i-1]
+			length := stop.DistanceAlong - prev.DistanceAlong
+			if avg, ok := p.segments[prev.Order]; ok && length > 0 {
+				seconds = avg * remaining / length
+			}
+		}
+		elapsed += seconds
+		pos = math.Max(pos, stop.DistanceAlong)
+
+		eta.Stops = append(eta.Stops, domain.StopETA{
+			StopId:    stop.StopId,
+			StopName:  stop.Name,
+			StopOrder: stop.Order,
+			Distance:  math.Round(math.Max(stop.DistanceAlong-along, 0)),
+			Seconds:   int64(math.Round(elapsed)),
+			ArrivalAt: at.Add(time.Duration(elapsed * float64(time.Second))),
+		})
+	}
+	s.compareSchedule(eta)
+	return eta, nil
+}
+
+// compareSchedule sets how late the bus is expected at each stop its
+// scheduled run, if any, has a time for.
+func (s *service) compareSchedule(eta *domain.BusETA) {
+	run, err := s.schedules.ScheduledRun(eta.BusId)
+	if err != nil || run == nil {
+		if err != nil {
+			log.Printf("failed to load schedule of bus %d: %v", eta.BusId, err)
+		}
+		return
+	}
+	for i := range eta.Stops {
+		stop := &eta.Stops[i]
+		due, ok := run.Arrivals[stop.StopOrder]
+		if !ok {
+			continue
+		}
+		delay := int64(math.Round(stop.ArrivalAt.Sub(due).Seconds()))
+		stop.ScheduledAt, stop.DelaySeconds = &due, &delay
+		if eta.DelaySeconds == nil {
+			eta.DelaySeconds = &delay
+		}
+	}
+}
+
+func hasStop(stops []domain.StopProgress, name string) bool {
+	for _, stop := range stops {
+		if strings.EqualFold(stop.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func filterStop(stops []domain.StopETA, name string) []domain.StopETA {
+	var out []domain.StopETA
+	for _, stop := range stops {
+		if strings.EqualFold(stop.StopName, name) {
+			out = append(out, stop)
+		}
+	}
+	return out
+}
@@ -0,0 +1,89 @@
+package fare
+
+import (
+	"fmt"
+	"swift_transit/domain"
+	"time"
+)
+
+// Concessions are student discounts. The self-declared is_student flag is not
+// enough; the rider needs an approved verification that covers the travel
+// date (verification is valid through the whole expiry day).
+func concessionEligible(rider *domain.User, at time.Time) bool {
+	if rider == nil || rider.StudentVerifiedUntil == nil {
+		return false
+	}
+	return at.Before(rider.StudentVerifiedUntil.AddDate(0, 0, 1))
+}
+
+// bestConcession picks the largest discount among the rules that cover the
+// route at the given time.
+func bestConcession(rules []domain.ConcessionRule, routeId int64, at time.Time) *domain.ConcessionRule {
+	var best *domain.ConcessionRule
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Active || !coversRoute(rule, routeId) || !withinHours(rule, at) {
+			continue
+		}
+		if best == nil || rule.DiscountPercent > best.DiscountPercent {
+			best = rule
+		}
+	}
+	return best
+}
+
+func coversRoute(rule *domain.ConcessionRule, routeId int64) bool {
+	if len(rule.RouteIds) == 0 {
+		return true
+	}
+	for _, id := range rule.RouteIds {
+		if id == routeId {
+			return true
+		}
+	}
+	return false
+}
+
+func withinHours(rule *domain.ConcessionRule, at time.Time) bool {
+	if rule.ValidFrom == "" || rule.ValidUntil == "" {
+		return true
+	}
+	from, err := time.Parse("15:04", rule.ValidFrom)
+	if err != nil {
+		return false
+	}
+	until, err := time.Parse("15:04", rule.ValidUntil)
+	if err != nil {
+		return false
+	}
+
+	minute := at.Hour()*60 + at.Minute()
+	start := from.Hour()*60 + from.Minute()
+	end := until.Hour()*60 + until.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	// Window wraps past midnight, e.g. 22:00-02:00
+	return minute >= start || minute < end
+}
+
+func validateConcession(rule domain.ConcessionRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if rule.DiscountPercent <= 0 || rule.DiscountPercent > 100 {
+		return fmt.Errorf("discount_percent must be between 0 and 100")
+	}
+	if (rule.ValidFrom == "") != (rule.ValidUntil == "") {
+		return fmt.Errorf("valid_from and valid_until must be set together")
+	}
+	if rule.ValidFrom != "" {
+		if _, err := time.Parse("15:04", rule.ValidFrom); err != nil {
+			return fmt.Errorf("invalid valid_from, expected HH:MM")
+		}
+		if _, err := time.Parse("15:04", rule.ValidUntil); err != nil {
+			return fmt.Errorf("invalid valid_until, expected HH:MM")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package fare
+
+import (
+	"math"
+	"swift_transit/domain"
+)
+
+const (
+	RoundingNone    = "none"
+	RoundingCeil    = "ceil"
+	RoundingFloor   = "floor"
+	RoundingNearest = "nearest"
+)
+
+// Trip is the part of a route a fare is charged for.
+type Trip struct {
+	RouteId    int64
+	StartStop  string
+	EndStop    string
+	DistanceKm float64
+}
+
+type FarePolicy interface {
+	Calculate(trip Trip) float64
+}
+
+// DefaultPolicy reproduces the original flat formula: 2.5 per km with a
+// minimum of 10, rounded up to a whole taka.
+func DefaultPolicy(routeId int64) domain.FarePolicy {
+	return domain.FarePolicy{
+		RouteId:      routeId,
+		BaseFare:     0,
+		PerKmRate:    2.5,
+		MinFare:      10,
+		RoundingMode: RoundingCeil,
+		RoundingStep: 1,
+	}
+}
+
+// NewPolicy builds the policy described by a stored route configuration.
+// Stop-to-stop table entries take precedence over the distance formula.
+func NewPolicy(cfg domain.FarePolicy) FarePolicy {
+	var policy FarePolicy = &distancePolicy{
+		baseFare:  cfg.BaseFare,
+		perKmRate: cfg.PerKmRate,
+		minFare:   cfg.MinFare,
+		maxFare:   cfg.MaxFare,
+	}
+
+	if len(cfg.FareTable) > 0 {
+		table := &tablePolicy{
+			fares:    make(map[[2]string]float64, len(cfg.FareTable)),
+			fallback: policy,
+		}
+		for _, entry := range cfg.FareTable {
+			table.fares[[2]string{entry.FromStop, entry.ToStop}] = entry.Fare
+		}
+		policy = table
+	}
+
+	return &roundedPolicy{
+		policy: policy,
+		mode:   cfg.RoundingMode,
+		step:   cfg.RoundingStep,
+	}
+}
+
+type distancePolicy struct {
+	baseFare  float64
+	perKmRate float64
+	minFare   float64
+	maxFare   *float64
+}
+
+func (p *distancePolicy) Calculate(trip Trip) float64 {
+	fare := p.baseFare + trip.DistanceKm*p.perKmRate
+	if fare < p.minFare {
+		fare = p.minFare
+	}
+	if p.maxFare != nil && fare > *p.maxFare {
+		fare = *p.maxFare
+	}
+	return fare
+}
+
+type tablePolicy struct {
+	fares    map[[2]string]float64
+	fallback FarePolicy
+}
+
+func (p *tablePolicy) Calculate(trip Trip) float64 {
+	if fare, ok := p.fares[[2]string{trip.StartStop, trip.EndStop}]; ok {
+		return fare
+	}
+	// Tables are usually entered one way only
+	if fare, ok := p.fares[[2]string{trip.EndStop, trip.StartStop}]; ok {
+		return fare
+	}
+	return p.fallback.Calculate(trip)
+}
+
+type roundedPolicy struct {
+	policy FarePolicy
+	mode   string
+	step   float64
+}
+
+func (p *roundedPolicy) Calculate(trip Trip) float64 {
+	return Round(p.policy.Calculate(trip), p.mode, p.step)
+}
+
+// Round applies a rounding rule to an amount. A step of 5 rounds to the
+// nearest multiple of 5 in the given direction.
+func Round(amount float64, mode string, step float64) float64 {
+	if step <= 0 {
+		step = 1
+	}
+	switch mode {
+	case RoundingCeil:
+		return math.Ceil(amount/step) * step
+	case RoundingFloor:
+		return math.Floor(amount/step) * step
+	case RoundingNearest:
+		return math.Round(amount/step) * step
+	default:
+		return amount
+	}
+}
@@ -0,0 +1,37 @@
+package fare
+
+import (
+	"swift_transit/domain"
+	"time"
+)
+
+// Quote is the price a specific rider pays for a trip.
+type Quote struct {
+	FullFare   float64 `json:"full_fare"`
+	Discount   float64 `json:"discount"`
+	Fare       float64 `json:"fare"`
+	Concession string  `json:"concession,omitempty"`
+}
+
+// Fares are measured on the route version in effect at the time given, so a
+// ticket is priced on the route it was bought for even after an edit.
+type Service interface {
+	CalculateFare(routeId int64, start, end string, at time.Time) (float64, error)
+	QuoteFare(routeId int64, start, end string, rider *domain.User, at time.Time) (*Quote, error)
+	GetPolicy(routeId int64) (*domain.FarePolicy, error)
+	UpdatePolicy(policy domain.FarePolicy) (*domain.FarePolicy, error)
+	GetConcessions() ([]domain.ConcessionRule, error)
+	CreateConcession(rule domain.ConcessionRule) (*domain.ConcessionRule, error)
+	UpdateConcession(rule domain.ConcessionRule) error
+	DeleteConcession(id int64) error
+}
+
+type FareRepo interface {
+	GetPolicy(routeId int64) (*domain.FarePolicy, error)
+	UpsertPolicy(policy domain.FarePolicy) error
+	GetTripDistance(routeId int64, start, end string, at time.Time) (float64, error)
+	GetConcessions() ([]domain.ConcessionRule, error)
+	CreateConcession(rule domain.ConcessionRule) (*domain.ConcessionRule, error)
+	UpdateConcession(rule domain.ConcessionRule) error
+	DeleteConcession(id int64) error
+}
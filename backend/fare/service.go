@@ -0,0 +1,140 @@
+package fare
+
+import (
+	"fmt"
+	"swift_transit/domain"
+	"time"
+)
+
+type service struct {
+	repo FareRepo
+}
+
+func NewService(repo FareRepo) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+func (s *service) CalculateFare(routeId int64, start, end string, at time.Time) (float64, error) {
+	quote, err := s.QuoteFare(routeId, start, end, nil, at)
+	if err != nil {
+		return 0, err
+	}
+	return quote.Fare, nil
+}
+
+func (s *service) QuoteFare(routeId int64, start, end string, rider *domain.User, at time.Time) (*Quote, error) {
+	cfg, err := s.GetPolicy(routeId)
+	if err != nil {
+		return nil, err
+	}
+
+	distance, err := s.repo.GetTripDistance(routeId, start, end, at)
+	if err != nil {
+		return nil, err
+	}
+
+	fullFare := NewPolicy(*cfg).Calculate(Trip{
+		RouteId:    routeId,
+		StartStop:  start,
+		EndStop:    end,
+		DistanceKm: distance,
+	})
+	quote := &Quote{FullFare: fullFare, Fare: fullFare}
+
+	if !concessionEligible(rider, at) {
+		return quote, nil
+	}
+
+	rules, err := s.repo.GetConcessions()
+	if err != nil {
+		return nil, err
+	}
+	rule := bestConcession(rules, routeId, at)
+	if rule == nil {
+		return quote, nil
+	}
+
+	quote.Fare = Round(fullFare*(1-rule.DiscountPercent/100), cfg.RoundingMode, cfg.RoundingStep)
+	if quote.Fare > fullFare {
+		quote.Fare = fullFare
+	}
+	quote.Discount = fullFare - quote.Fare
+	quote.Concession = rule.Name
+	return quote, nil
+}
+
+func (s *service) GetPolicy(routeId int64) (*domain.FarePolicy, error) {
+	cfg, err := s.repo.GetPolicy(routeId)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		def := DefaultPolicy(routeId)
+		return &def, nil
+	}
+	return cfg, nil
+}
+
+func (s *service) UpdatePolicy(policy domain.FarePolicy) (*domain.FarePolicy, error) {
+	if policy.RouteId == 0 {
+		return nil, fmt.Errorf("route_id is required")
+	}
+	if policy.BaseFare < 0 || policy.PerKmRate < 0 || policy.MinFare < 0 {
+		return nil, fmt.Errorf("fare values cannot be negative")
+	}
+	if policy.MaxFare != nil && *policy.MaxFare < policy.MinFare {
+		return nil, fmt.Errorf("max_fare cannot be lower than min_fare")
+	}
+	if policy.RoundingMode == "" {
+		policy.RoundingMode = RoundingNone
+	}
+	switch policy.RoundingMode {
+	case RoundingNone, RoundingCeil, RoundingFloor, RoundingNearest:
+	default:
+		return nil, fmt.Errorf("invalid rounding_mode: %s", policy.RoundingMode)
+	}
+	if policy.RoundingStep <= 0 {
+		policy.RoundingStep = 1
+	}
+	for i, entry := range policy.FareTable {
+		if entry.FromStop == "" || entry.ToStop == "" || entry.Fare < 0 {
+			return nil, fmt.Errorf("invalid fare table entry at position %d", i+1)
+		}
+		policy.FareTable[i].RouteId = policy.RouteId
+	}
+
+	if err := s.repo.UpsertPolicy(policy); err != nil {
+		return nil, err
+	}
+	return s.GetPolicy(policy.RouteId)
+}
+
+func (s *service) GetConcessions() ([]domain.ConcessionRule, error) {
+	return s.repo.GetConcessions()
+}
+
+func (s *service) CreateConcession(rule domain.ConcessionRule) (*domain.ConcessionRule, error) {
+	if err := validateConcession(rule); err != nil {
+		return nil, err
+	}
+	if rule.RouteIds == nil {
+		rule.RouteIds = []int64{}
+	}
+	return s.repo.CreateConcession(rule)
+}
+
+func (s *service) UpdateConcession(rule domain.ConcessionRule) error {
+	if err := validateConcession(rule); err != nil {
+		return err
+	}
+	if rule.RouteIds == nil {
+		rule.RouteIds = []int64{}
+	}
+	return s.repo.UpdateConcession(rule)
+}
+
+func (s *service) DeleteConcession(id int64) error {
+	return s.repo.DeleteConcession(id)
+}
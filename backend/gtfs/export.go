@@ -0,0 +1,420 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"swift_transit/domain"
+	"time"
+)
+
+const (
+	agencyID  = "swift_transit"
+	serviceID = "DAILY"
+
+	// Routes with timetables are exported with their scheduled trips and
+	// service calendars. Any other route is exported as one frequency-based
+	// trip running all day, with stop times estimated from the distance
+	// between stops at an average bus speed.
+	serviceStart  = 6 * time.Hour
+	serviceEnd    = 22 * time.Hour
+	headway       = 15 * time.Minute
+	avgSpeedKmph  = 20.0
+	gtfsRouteType = "3" // bus
+)
+
+type table struct {
+	name   string
+	header []string
+	rows   [][]string
+}
+
+func (s *service) Export(w io.Writer) error {
+	routes, err := s.routeRepo.FindAll()
+	if err != nil {
+		return err
+	}
+
+	// A route needs at least two stops to be ridden
+	exported := make([]domain.Route, 0, len(routes))
+	for _, rt := range routes {
+		if len(rt.Stops) >= 2 {
+			exported = append(exported, rt)
+		}
+	}
+	if len(exported) == 0 {
+		return fmt.Errorf("no routes with stops to export")
+	}
+
+	fareAttributes, fareRules, err := s.fareTables(exported)
+	if err != nil {
+		return err
+	}
+	scheduled, calendars, err := s.scheduledTrips(exported)
+	if err != nil {
+		return err
+	}
+	var unscheduled []domain.Route
+	for _, rt := range exported {
+		if len(scheduled[rt.Id]) == 0 {
+			unscheduled = append(unscheduled, rt)
+		}
+	}
+
+	tables := []table{
+		s.agencyTable(),
+		stopsTable(exported),
+		routesTable(exported),
+		tripsTable(exported, scheduled),
+		stopTimesTable(exported, scheduled),
+		frequenciesTable(unscheduled),
+		calendarTable(calendars, len(unscheduled) > 0, time.Now()),
+		calendarDatesTable(calendars),
+		shapesTable(exported),
+		fareAttributes,
+		fareRules,
+		s.feedInfoTable(),
+	}
+
+	zw := zip.NewWriter(w)
+	for _, t := range tables {
+		f, err := zw.Create(t.name)
+		if err != nil {
+			return err
+		}
+		cw := csv.NewWriter(f)
+		if err := cw.Write(t.header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(t.rows); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (s *service) agencyTable() table {
+	return table{
+		name:   "agency.txt",
+		header: []string{"agency_id", "agency_name", "agency_url", "agency_timezone"},
+		rows:   [][]string{{agencyID, s.agency.Name, s.agency.URL, s.agency.Timezone}},
+	}
+}
+
+func (s *service) feedInfoTable() table {
+	return table{
+		name:   "feed_info.txt",
+		header: []string{"feed_publisher_name", "feed_publisher_url", "feed_lang"},
+		rows:   [][]string{{s.agency.Name, s.agency.URL, "en"}},
+	}
+}
+
+func stopsTable(routes []domain.Route) table {
+	t := table{
+		name:   "stops.txt",
+		header: []string{"stop_id", "stop_name", "stop_lat", "stop_lon", "zone_id"},
+	}
+	for _, rt := range routes {
+		for _, stop := range rt.Stops {
+			t.rows = append(t.rows, []string{
+				stopID(stop.Id),
+				stop.Name,
+				formatCoord(stop.Lat),
+				formatCoord(stop.Lon),
+				zoneID(stop.Id),
+			})
+		}
+	}
+	return t
+}
+
+func routesTable(routes []domain.Route) table {
+	t := table{
+		name:   "routes.txt",
+		header: []string{"route_id", "agency_id", "route_short_name", "route_long_name", "route_type"},
+	}
+	for _, rt := range routes {
+		t.rows = append(t.rows, []string{routeID(rt.Id), agencyID, "", rt.Name, gtfsRouteType})
+	}
+	return t
+}
+
+// scheduledTrips returns the scheduled trips of each exported route that can
+// be written as GTFS trips, and the calendars they run on.
+func (s *service) scheduledTrips(routes []domain.Route) (map[int64][]domain.ScheduledTrip, []domain.ServiceCalendar, error) {
+	trips, err := s.timetables.GetTrips(0, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	calendars, err := s.timetables.GetCalendars(0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	exported := make(map[int64]bool, len(routes))
+	for _, rt := range routes {
+		exported[rt.Id] = true
+	}
+	byRoute := make(map[int64][]domain.ScheduledTrip)
+	used := make(map[int64]bool)
+	for _, trip := range trips {
+		if !exported[trip.RouteId] || len(trip.StopTimes) < 2 {
+			continue
+		}
+		byRoute[trip.RouteId] = append(byRoute[trip.RouteId], trip)
+		used[trip.CalendarId] = true
+	}
+
+	var usedCalendars []domain.ServiceCalendar
+	for _, c := range calendars {
+		if used[c.Id] {
+			usedCalendars = append(usedCalendars, c)
+		}
+	}
+	return byRoute, usedCalendars, nil
+}
+
+func tripsTable(routes []domain.Route, scheduled map[int64][]domain.ScheduledTrip) table {
+	t := table{
+		name:   "trips.txt",
+		header: []string{"route_id", "service_id", "trip_id", "trip_headsign", "shape_id"},
+	}
+	for _, rt := range routes {
+		shape := ""
+		if hasShape(rt) {
+			shape = shapeID(rt.Id)
+		}
+		lastStop := rt.Stops[len(rt.Stops)-1].Name
+		if len(scheduled[rt.Id]) == 0 {
+			t.rows = append(t.rows, []string{routeID(rt.Id), serviceID, tripID(rt.Id), lastStop, shape})
+			continue
+		}
+		for _, trip := range scheduled[rt.Id] {
+			headsign := trip.Headsign
+			if headsign == "" {
+				headsign = lastStop
+			}
+			t.rows = append(t.rows, []string{routeID(rt.Id), calendarID(trip.CalendarId), scheduledTripID(trip.Id), headsign, shape})
+		}
+	}
+	return t
+}
+
+func stopTimesTable(routes []domain.Route, scheduled map[int64][]domain.ScheduledTrip) table {
+	t := table{
+		name:   "stop_times.txt",
+		header: []string{"trip_id", "arrival_time", "departure_time", "stop_id", "stop_sequence", "timepoint"},
+	}
+	for _, rt := range routes {
+		if trips := scheduled[rt.Id]; len(trips) > 0 {
+			for _, trip := range trips {
+				for _, st := range trip.StopTimes {
+					t.rows = append(t.rows, []string{
+						scheduledTripID(trip.Id),
+						st.Arrival.String(),
+						st.Departure.String(),
+						stopID(st.StopId),
+						strconv.Itoa(st.StopOrder),
+						"1",
+					})
+				}
+			}
+			continue
+		}
+
+		elapsed := 0.0
+		for i, stop := range rt.Stops {
+			if i > 0 {
+				prev := rt.Stops[i-1]
+				elapsed += distanceMeters(prev.Lat, prev.Lon, stop.Lat, stop.Lon) / (avgSpeedKmph * 1000 / 3600)
+			}
+			at := formatTime(serviceStart + time.Duration(math.Round(elapsed))*time.Second)
+			t.rows = append(t.rows, []string{tripID(rt.Id), at, at, stopID(stop.Id), strconv.Itoa(i + 1), "0"})
+		}
+	}
+	return t
+}
+
+func frequenciesTable(routes []domain.Route) table {
+	t := table{
+		name:   "frequencies.txt",
+		header: []string{"trip_id", "start_time", "end_time", "headway_secs", "exact_times"},
+	}
+	for _, rt := range routes {
+		t.rows = append(t.rows, []string{tripID(rt.Id), formatTime(serviceStart), formatTime(serviceEnd), strconv.Itoa(int(headway.Seconds())), "0"})
+	}
+	return t
+}
+
+// calendarTable writes the service calendars of scheduled trips, and the
+// every-day service of frequency-based trips when there are any.
+func calendarTable(calendars []domain.ServiceCalendar, daily bool, now time.Time) table {
+	t := table{
+		name:   "calendar.txt",
+		header: []string{"service_id", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "start_date", "end_date"},
+	}
+	if daily {
+		t.rows = append(t.rows, []string{
+			serviceID, "1", "1", "1", "1", "1", "1", "1",
+			now.Format("20060102"),
+			now.AddDate(1, 0, 0).Format("20060102"),
+		})
+	}
+	for _, c := range calendars {
+		t.rows = append(t.rows, []string{
+			calendarID(c.Id),
+			flag(c.Monday), flag(c.Tuesday), flag(c.Wednesday), flag(c.Thursday), flag(c.Friday), flag(c.Saturday), flag(c.Sunday),
+			gtfsDate(c.StartDate),
+			gtfsDate(c.EndDate),
+		})
+	}
+	return t
+}
+
+func calendarDatesTable(calendars []domain.ServiceCalendar) table {
+	t := table{
+		name:   "calendar_dates.txt",
+		header: []string{"service_id", "date", "exception_type"},
+	}
+	for _, c := range calendars {
+		for _, e := range c.Exceptions {
+			// 1 adds service on the date, 2 removes it
+			exceptionType := "2"
+			if e.Type == domain.ExceptionAdded {
+				exceptionType = "1"
+			}
+			t.rows = append(t.rows, []string{calendarID(c.Id), gtfsDate(e.Date), exceptionType})
+		}
+	}
+	return t
+}
+
+func shapesTable(routes []domain.Route) table {
+	t := table{
+		name:   "shapes.txt",
+		header: []string{"shape_id", "shape_pt_lat", "shape_pt_lon", "shape_pt_sequence", "shape_dist_traveled"},
+	}
+	for _, rt := range routes {
+		if !hasShape(rt) {
+			continue
+		}
+		traveled := 0.0
+		coords := rt.LineStringGeoJSON.Coordinates
+		for i, c := range coords {
+			if i > 0 {
+				traveled += distanceMeters(coords[i-1][1], coords[i-1][0], c[1], c[0])
+			}
+			t.rows = append(t.rows, []string{
+				shapeID(rt.Id),
+				formatCoord(c[1]),
+				formatCoord(c[0]),
+				strconv.Itoa(i + 1),
+				strconv.FormatFloat(traveled, 'f', 1, 64),
+			})
+		}
+	}
+	return t
+}
+
+// fareTables prices every pair of stops on each route with the fare model.
+// Each distinct price becomes a fare attribute and every stop is its own
+// zone, so a fare rule maps an origin and destination stop to a price.
+func (s *service) fareTables(routes []domain.Route) (table, table, error) {
+	attributes := table{
+		name:   "fare_attributes.txt",
+		header: []string{"fare_id", "price", "currency_type", "payment_method", "transfers", "agency_id"},
+	}
+	rules := table{
+		name:   "fare_rules.txt",
+		header: []string{"fare_id", "route_id", "origin_id", "destination_id"},
+	}
+
+	prices := make(map[string]bool)
+	for _, rt := range routes {
+		for i := 0; i < len(rt.Stops); i++ {
+			for j := i + 1; j < len(rt.Stops); j++ {
+				from, to := rt.Stops[i], rt.Stops[j]
+				if from.Name == to.Name {
+					continue
+				}
+				fare, err := s.fareSvc.CalculateFare(rt.Id, from.Name, to.Name, time.Now())
+				if err != nil {
+					return table{}, table{}, fmt.Errorf("failed to price %s to %s on route %d: %w", from.Name, to.Name, rt.Id, err)
+				}
+				price := strconv.FormatFloat(fare, 'f', 2, 64)
+				fareID := "fare_" + price
+				prices[price] = true
+				rules.rows = append(rules.rows,
+					[]string{fareID, routeID(rt.Id), zoneID(from.Id), zoneID(to.Id)},
+					[]string{fareID, routeID(rt.Id), zoneID(to.Id), zoneID(from.Id)},
+				)
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(prices))
+	for price := range prices {
+		sorted = append(sorted, price)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, _ := strconv.ParseFloat(sorted[i], 64)
+		b, _ := strconv.ParseFloat(sorted[j], 64)
+		return a < b
+	})
+	for _, price := range sorted {
+		// Paid before boarding, no free transfers
+		attributes.rows = append(attributes.rows, []string{"fare_" + price, price, s.agency.Currency, "1", "0", agencyID})
+	}
+
+	return attributes, rules, nil
+}
+
+func hasShape(rt domain.Route) bool {
+	return rt.LineStringGeoJSON != nil && len(rt.LineStringGeoJSON.Coordinates) >= 2
+}
+
+func routeID(id int64) string { return "route_" + strconv.FormatInt(id, 10) }
+func tripID(id int64) string  { return "trip_" + strconv.FormatInt(id, 10) }
+func shapeID(id int64) string { return "shape_" + strconv.FormatInt(id, 10) }
+func stopID(id int64) string  { return "stop_" + strconv.FormatInt(id, 10) }
+func zoneID(id int64) string  { return "zone_" + strconv.FormatInt(id, 10) }
+
+func scheduledTripID(id int64) string { return "scheduled_trip_" + strconv.FormatInt(id, 10) }
+func calendarID(id int64) string      { return "calendar_" + strconv.FormatInt(id, 10) }
+
+func flag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// gtfsDate turns a domain.DateLayout date into the YYYYMMDD GTFS uses.
+func gtfsDate(date string) string {
+	return strings.ReplaceAll(date, "-", "")
+}
+
+func formatCoord(v float64) string {
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
+
+// formatTime writes a duration since midnight as HH:MM:SS; GTFS allows hours
+// past 24 for trips running after midnight.
+func formatTime(d time.Duration) string {
+	secs := int(d.Seconds())
+	return fmt.Sprintf("%02d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+}
+
+func distanceMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371000.0
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,473 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"swift_transit/domain"
+	"time"
+)
+
+// csvFile is a parsed feed file; lines holds the file line of each row for
+// error reporting.
+type csvFile struct {
+	name   string
+	header map[string]int
+	rows   [][]string
+	lines  []int
+}
+
+func (f *csvFile) get(row []string, column string) string {
+	i, ok := f.header[column]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+type gtfsStop struct {
+	name string
+	lat  float64
+	lon  float64
+}
+
+type gtfsTrip struct {
+	id        string
+	routeId   string
+	direction int
+	shapeId   string
+	line      int
+}
+
+type stopTime struct {
+	sequence int
+	stopId   string
+}
+
+type shapePoint struct {
+	sequence int
+	lat      float64
+	lon      float64
+}
+
+// routeKey is one direction of a GTFS route, imported as one of our routes
+// the same way routes are split into up and down variants.
+type routeKey struct {
+	routeId   string
+	direction int
+}
+
+type importer struct {
+	errors   []ImportError
+	warnings []ImportError
+}
+
+func (im *importer) fail(file string, line int, format string, args ...any) {
+	im.errors = append(im.errors, ImportError{File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (im *importer) warn(file string, line int, format string, args ...any) {
+	im.warnings = append(im.warnings, ImportError{File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// record adds the route check's errors and warnings to the report, and
+// reports whether the route passed.
+func (im *importer) record(check *domain.RouteCheck, line int, routeId string) bool {
+	for _, msg := range check.Errors {
+		im.fail("trips.txt", line, "route %s: %s", routeId, msg)
+	}
+	for _, msg := range check.Warnings {
+		im.warn("trips.txt", line, "route %s: %s", routeId, msg)
+	}
+	return check.Valid
+}
+
+func (s *service) Import(r io.ReaderAt, size int64, effectiveFrom time.Time) (*ImportReport, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GTFS zip: %w", err)
+	}
+
+	stopsFile, err := readCSV(zr, "stops.txt", true, "stop_id", "stop_lat", "stop_lon")
+	if err != nil {
+		return nil, err
+	}
+	routesFile, err := readCSV(zr, "routes.txt", true, "route_id")
+	if err != nil {
+		return nil, err
+	}
+	tripsFile, err := readCSV(zr, "trips.txt", true, "route_id", "trip_id")
+	if err != nil {
+		return nil, err
+	}
+	stopTimesFile, err := readCSV(zr, "stop_times.txt", true, "trip_id", "stop_id", "stop_sequence")
+	if err != nil {
+		return nil, err
+	}
+	shapesFile, err := readCSV(zr, "shapes.txt", false, "shape_id", "shape_pt_lat", "shape_pt_lon", "shape_pt_sequence")
+	if err != nil {
+		return nil, err
+	}
+
+	im := &importer{}
+	stops := im.parseStops(stopsFile)
+	routeNames := im.parseRoutes(routesFile)
+	trips := im.parseTrips(tripsFile, routeNames)
+	stopTimes, badTrips := im.parseStopTimes(stopTimesFile, trips, stops)
+	shapes := im.parseShapes(shapesFile)
+
+	// Every route direction is built from its trip with the most stops
+	best := make(map[routeKey]*gtfsTrip)
+	directions := make(map[string]map[int]bool)
+	for _, trip := range trips {
+		if badTrips[trip.id] || len(stopTimes[trip.id]) < 2 {
+			continue
+		}
+		key := routeKey{trip.routeId, trip.direction}
+		if cur, ok := best[key]; !ok || len(stopTimes[trip.id]) > len(stopTimes[cur.id]) ||
+			(len(stopTimes[trip.id]) == len(stopTimes[cur.id]) && trip.id < cur.id) {
+			best[key] = trip
+		}
+		if directions[trip.routeId] == nil {
+			directions[trip.routeId] = make(map[int]bool)
+		}
+		directions[trip.routeId][trip.direction] = true
+	}
+	for routeId := range routeNames {
+		if len(directions[routeId]) == 0 {
+			im.fail("routes.txt", 0, "route %s has no trip with at least two valid stops", routeId)
+		}
+	}
+
+	keys := make([]routeKey, 0, len(best))
+	for key := range best {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].routeId != keys[j].routeId {
+			return keys[i].routeId < keys[j].routeId
+		}
+		return keys[i].direction < keys[j].direction
+	})
+
+	report := &ImportReport{Routes: []ImportedRoute{}}
+	for _, key := range keys {
+		trip := best[key]
+		route := domain.Route{Name: routeNames[key.routeId]}
+		if len(directions[key.routeId]) > 1 {
+			// Match the naming of routes created by hand
+			if key.direction == 0 {
+				route.Name += " Up"
+			} else {
+				route.Name += " Down"
+			}
+		}
+
+		ls := &domain.LineString{Type: "LineString"}
+		for i, st := range stopTimes[trip.id] {
+			stop := stops[st.stopId]
+			route.Stops = append(route.Stops, domain.Stop{Name: stop.name, Order: i + 1, Lat: stop.lat, Lon: stop.lon})
+			ls.Coordinates = append(ls.Coordinates, []float64{stop.lon, stop.lat})
+		}
+		if trip.shapeId != "" {
+			points, ok := shapes[trip.shapeId]
+			if !ok || len(points) < 2 {
+				im.fail("trips.txt", trip.line, "trip %s uses shape %s which is missing or has fewer than two points", trip.id, trip.shapeId)
+				continue
+			}
+			ls.Coordinates = ls.Coordinates[:0]
+			for _, p := range points {
+				ls.Coordinates = append(ls.Coordinates, []float64{p.lon, p.lat})
+			}
+		}
+		route.LineStringGeoJSON = ls
+
+		routeID, err := s.routeRepo.FindGTFSRoute(key.routeId, key.direction)
+		if err != nil {
+			im.fail("routes.txt", 0, "failed to load route %s: %v", key.routeId, err)
+			continue
+		}
+		if routeID == 0 {
+			// New routes pass the same check as routes built by hand, and
+			// their stops take their order along the line
+			check, err := s.routeEditor.CheckRoute(route)
+			if err != nil {
+				im.fail("trips.txt", trip.line, "failed to check route %s: %v", key.routeId, err)
+				continue
+			}
+			if !im.record(check, trip.line, key.routeId) {
+				continue
+			}
+			route.Stops = check.RouteStops()
+
+			saved, err := s.routeRepo.CreateGTFSRoute(key.routeId, key.direction, route)
+			if err != nil {
+				im.fail("routes.txt", 0, "failed to save route %s: %v", key.routeId, err)
+				continue
+			}
+			report.Created++
+			report.Routes = append(report.Routes, ImportedRoute{
+				GTFSRouteId: key.routeId,
+				Direction:   key.direction,
+				RouteId:     saved.Id,
+				Name:        saved.Name,
+				Stops:       len(saved.Stops),
+				Created:     true,
+			})
+			continue
+		}
+
+		// A re-import is an edit like any other: stops keep their ids by name,
+		// timetabled stops cannot be dropped, and it can be scheduled
+		v, check, err := s.routeEditor.CreateVersion(domain.RouteVersion{
+			RouteId:           routeID,
+			Name:              route.Name,
+			LineStringGeoJSON: route.LineStringGeoJSON,
+			Stops:             route.Stops,
+			EffectiveFrom:     effectiveFrom,
+		})
+		if err != nil {
+			im.fail("trips.txt", trip.line, "failed to update route %s: %v", key.routeId, err)
+			continue
+		}
+		if !im.record(check, trip.line, key.routeId) {
+			continue
+		}
+		report.Updated++
+		report.Routes = append(report.Routes, ImportedRoute{
+			GTFSRouteId: key.routeId,
+			Direction:   key.direction,
+			RouteId:     routeID,
+			Name:        v.Name,
+			Stops:       len(v.Stops),
+			Version:     v.Version,
+			Scheduled:   v.AppliedAt == nil,
+		})
+	}
+
+	report.Errors = im.errors
+	if report.Errors == nil {
+		report.Errors = []ImportError{}
+	}
+	report.Warnings = im.warnings
+	if report.Warnings == nil {
+		report.Warnings = []ImportError{}
+	}
+	return report, nil
+}
+
+func (im *importer) parseStops(f *csvFile) map[string]gtfsStop {
+	stops := make(map[string]gtfsStop)
+	for i, row := range f.rows {
+		id := f.get(row, "stop_id")
+		if id == "" {
+			im.fail(f.name, f.lines[i], "stop_id is required")
+			continue
+		}
+		// Stations, entrances and other non-boarding locations are not stops
+		if lt := f.get(row, "location_type"); lt != "" && lt != "0" {
+			continue
+		}
+		name := f.get(row, "stop_name")
+		if name == "" {
+			im.fail(f.name, f.lines[i], "stop %s has no stop_name", id)
+			continue
+		}
+		lat, errLat := strconv.ParseFloat(f.get(row, "stop_lat"), 64)
+		lon, errLon := strconv.ParseFloat(f.get(row, "stop_lon"), 64)
+		if errLat != nil || errLon != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			im.fail(f.name, f.lines[i], "stop %s has invalid coordinates", id)
+			continue
+		}
+		if _, dup := stops[id]; dup {
+			im.fail(f.name, f.lines[i], "duplicate stop_id %s", id)
+			continue
+		}
+		stops[id] = gtfsStop{name: name, lat: lat, lon: lon}
+	}
+	return stops
+}
+
+func (im *importer) parseRoutes(f *csvFile) map[string]string {
+	names := make(map[string]string)
+	for i, row := range f.rows {
+		id := f.get(row, "route_id")
+		if id == "" {
+			im.fail(f.name, f.lines[i], "route_id is required")
+			continue
+		}
+		name := f.get(row, "route_long_name")
+		if name == "" {
+			name = f.get(row, "route_short_name")
+		}
+		if name == "" {
+			im.fail(f.name, f.lines[i], "route %s needs a route_long_name or route_short_name", id)
+			continue
+		}
+		if _, dup := names[id]; dup {
+			im.fail(f.name, f.lines[i], "duplicate route_id %s", id)
+			continue
+		}
+		names[id] = name
+	}
+	return names
+}
+
+func (im *importer) parseTrips(f *csvFile, routes map[string]string) map[string]*gtfsTrip {
+	trips := make(map[string]*gtfsTrip)
+	for i, row := range f.rows {
+		trip := &gtfsTrip{
+			id:      f.get(row, "trip_id"),
+			routeId: f.get(row, "route_id"),
+			shapeId: f.get(row, "shape_id"),
+			line:    f.lines[i],
+		}
+		if trip.id == "" {
+			im.fail(f.name, trip.line, "trip_id is required")
+			continue
+		}
+		if _, ok := routes[trip.routeId]; !ok {
+			im.fail(f.name, trip.line, "trip %s references unknown route %s", trip.id, trip.routeId)
+			continue
+		}
+		switch f.get(row, "direction_id") {
+		case "", "0":
+		case "1":
+			trip.direction = 1
+		default:
+			im.fail(f.name, trip.line, "trip %s has invalid direction_id", trip.id)
+			continue
+		}
+		if _, dup := trips[trip.id]; dup {
+			im.fail(f.name, trip.line, "duplicate trip_id %s", trip.id)
+			continue
+		}
+		trips[trip.id] = trip
+	}
+	return trips
+}
+
+// parseStopTimes returns each trip's stops ordered by stop_sequence. Trips
+// with an invalid row are reported and left out entirely rather than
+// imported with a gap.
+func (im *importer) parseStopTimes(f *csvFile, trips map[string]*gtfsTrip, stops map[string]gtfsStop) (map[string][]stopTime, map[string]bool) {
+	byTrip := make(map[string][]stopTime)
+	bad := make(map[string]bool)
+	seen := make(map[string]map[int]bool)
+	for i, row := range f.rows {
+		tripId := f.get(row, "trip_id")
+		if _, ok := trips[tripId]; !ok {
+			im.fail(f.name, f.lines[i], "stop time references unknown trip %s", tripId)
+			continue
+		}
+		stopId := f.get(row, "stop_id")
+		if _, ok := stops[stopId]; !ok {
+			im.fail(f.name, f.lines[i], "trip %s references unknown stop %s", tripId, stopId)
+			bad[tripId] = true
+			continue
+		}
+		seq, err := strconv.Atoi(f.get(row, "stop_sequence"))
+		if err != nil || seq < 0 {
+			im.fail(f.name, f.lines[i], "trip %s has invalid stop_sequence", tripId)
+			bad[tripId] = true
+			continue
+		}
+		if seen[tripId] == nil {
+			seen[tripId] = make(map[int]bool)
+		}
+		if seen[tripId][seq] {
+			im.fail(f.name, f.lines[i], "trip %s repeats stop_sequence %d", tripId, seq)
+			bad[tripId] = true
+			continue
+		}
+		seen[tripId][seq] = true
+		byTrip[tripId] = append(byTrip[tripId], stopTime{sequence: seq, stopId: stopId})
+	}
+	for _, sts := range byTrip {
+		sort.Slice(sts, func(i, j int) bool { return sts[i].sequence < sts[j].sequence })
+	}
+	return byTrip, bad
+}
+
+func (im *importer) parseShapes(f *csvFile) map[string][]shapePoint {
+	shapes := make(map[string][]shapePoint)
+	if f == nil {
+		return shapes
+	}
+	for i, row := range f.rows {
+		id := f.get(row, "shape_id")
+		lat, errLat := strconv.ParseFloat(f.get(row, "shape_pt_lat"), 64)
+		lon, errLon := strconv.ParseFloat(f.get(row, "shape_pt_lon"), 64)
+		seq, errSeq := strconv.Atoi(f.get(row, "shape_pt_sequence"))
+		if id == "" || errLat != nil || errLon != nil || errSeq != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			im.fail(f.name, f.lines[i], "invalid shape point")
+			continue
+		}
+		shapes[id] = append(shapes[id], shapePoint{sequence: seq, lat: lat, lon: lon})
+	}
+	for _, points := range shapes {
+		sort.Slice(points, func(i, j int) bool { return points[i].sequence < points[j].sequence })
+	}
+	return shapes
+}
+
+// readCSV loads a feed file, which may sit in a folder inside the zip. A
+// missing optional file returns nil.
+func readCSV(zr *zip.Reader, name string, required bool, columns ...string) (*csvFile, error) {
+	var zf *zip.File
+	for _, f := range zr.File {
+		if path.Base(f.Name) == name {
+			zf = f
+			break
+		}
+	}
+	if zf == nil {
+		if required {
+			return nil, fmt.Errorf("missing required file %s", name)
+		}
+		return nil, nil
+	}
+
+	rc, err := zf.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer rc.Close()
+
+	cr := csv.NewReader(rc)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header of %s: %w", name, err)
+	}
+
+	f := &csvFile{name: name, header: make(map[string]int)}
+	for i, col := range header {
+		col = strings.TrimSpace(strings.TrimPrefix(col, "\ufeff"))
+		f.header[col] = i
+	}
+	for _, col := range columns {
+		if _, ok := f.header[col]; !ok {
+			return nil, fmt.Errorf("%s is missing required column %s", name, col)
+		}
+	}
+
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		line, _ := cr.FieldPos(0)
+		f.rows = append(f.rows, row)
+		f.lines = append(f.lines, line)
+	}
+	return f, nil
+}
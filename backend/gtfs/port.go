@@ -0,0 +1,74 @@
+package gtfs
+
+import (
+	"io"
+	"swift_transit/domain"
+	"time"
+)
+
+type Service interface {
+	// Export writes a GTFS static feed zip of all routes to w
+	Export(w io.Writer) error
+	// Import creates or updates routes from a GTFS static feed zip. Routes
+	// imported before are updated with a new route version that takes effect
+	// at effectiveFrom, or at once when it is unset or already past.
+	Import(r io.ReaderAt, size int64, effectiveFrom time.Time) (*ImportReport, error)
+}
+
+// RouteEditor checks new routes and saves edits of existing ones; see
+// route.Service.
+type RouteEditor interface {
+	CheckRoute(route domain.Route) (*domain.RouteCheck, error)
+	CreateVersion(v domain.RouteVersion) (*domain.RouteVersion, *domain.RouteCheck, error)
+}
+
+// Timetables lists scheduled trips and service calendars; see
+// schedule.Service, where a zero owner id means every operator.
+type Timetables interface {
+	GetCalendars(ownerId int64) ([]domain.ServiceCalendar, error)
+	GetTrips(ownerId, routeId int64) ([]domain.ScheduledTrip, error)
+}
+
+type RouteRepo interface {
+	FindAll() ([]domain.Route, error)
+	// FindGTFSRoute returns the id of the route imported from a GTFS route
+	// and direction, or 0 when there is none
+	FindGTFSRoute(gtfsRouteId string, direction int) (int64, error)
+	CreateGTFSRoute(gtfsRouteId string, direction int, route domain.Route) (*domain.Route, error)
+}
+
+// Agency describes the operator written to agency.txt and feed_info.txt.
+type Agency struct {
+	Name     string
+	URL      string
+	Timezone string
+	Currency string
+}
+
+// ImportError points at the feed row that could not be imported. Line is 0
+// for problems that are not tied to a single row.
+type ImportError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+type ImportedRoute struct {
+	GTFSRouteId string `json:"gtfs_route_id"`
+	Direction   int    `json:"direction"`
+	RouteId     int64  `json:"route_id"`
+	Name        string `json:"name"`
+	Stops       int    `json:"stops"`
+	Created     bool   `json:"created"`
+	// Set on updates, which wait for their effective time when Scheduled
+	Version   int  `json:"version,omitempty"`
+	Scheduled bool `json:"scheduled"`
+}
+
+type ImportReport struct {
+	Created  int             `json:"created"`
+	Updated  int             `json:"updated"`
+	Routes   []ImportedRoute `json:"routes"`
+	Errors   []ImportError   `json:"errors"`
+	Warnings []ImportError   `json:"warnings"`
+}
@@ -0,0 +1,131 @@
+package gtfs
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"swift_transit/location"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// GTFS-Realtime field numbers from gtfs-realtime.proto, encoded by hand to
+// avoid generating code for the whole schema.
+const (
+	feedMessageHeader = 1
+	feedMessageEntity = 2
+
+	feedHeaderVersion        = 1
+	feedHeaderIncrementality = 2
+	feedHeaderTimestamp      = 3
+
+	feedEntityId      = 1
+	feedEntityVehicle = 4
+
+	vehiclePositionTrip                = 1
+	vehiclePositionPosition            = 2
+	vehiclePositionTimestamp           = 5
+	vehiclePositionVehicle             = 8
+	vehiclePositionOccupancy           = 9
+	vehiclePositionOccupancyPercentage = 10
+
+	tripDescriptorRouteId = 5
+
+	vehicleDescriptorId = 1
+
+	positionLatitude  = 1
+	positionLongitude = 2
+	positionSpeed     = 5
+
+	realtimeVersion = "2.0"
+	fullDataset     = 0
+)
+
+// OccupancyStatus values for each crowding level
+var occupancyStatus = map[string]uint64{
+	location.CrowdingEmpty:     0, // EMPTY
+	location.CrowdingManySeats: 1, // MANY_SEATS_AVAILABLE
+	location.CrowdingFewSeats:  2, // FEW_SEATS_AVAILABLE
+	location.CrowdingStanding:  3, // STANDING_ROOM_ONLY
+	location.CrowdingFull:      5, // FULL
+}
+
+// EncodeVehiclePositions builds a GTFS-Realtime FeedMessage with one vehicle
+// entity per bus. Route ids match the ones in the static feed.
+func EncodeVehiclePositions(positions []location.VehiclePosition, at time.Time) []byte {
+	sort.Slice(positions, func(i, j int) bool { return positions[i].BusID < positions[j].BusID })
+
+	var header []byte
+	header = appendString(header, feedHeaderVersion, realtimeVersion)
+	header = appendVarint(header, feedHeaderIncrementality, fullDataset)
+	header = appendVarint(header, feedHeaderTimestamp, uint64(at.Unix()))
+
+	var msg []byte
+	msg = appendMessage(msg, feedMessageHeader, header)
+	for _, p := range positions {
+		msg = appendMessage(msg, feedMessageEntity, encodeEntity(p))
+	}
+	return msg
+}
+
+func encodeEntity(p location.VehiclePosition) []byte {
+	vehicleID := strconv.FormatInt(p.BusID, 10)
+
+	var trip []byte
+	if p.RouteID != 0 {
+		trip = appendString(trip, tripDescriptorRouteId, routeID(p.RouteID))
+	}
+
+	var vehicle []byte
+	vehicle = appendString(vehicle, vehicleDescriptorId, vehicleID)
+
+	var position []byte
+	position = appendFloat(position, positionLatitude, p.Latitude)
+	position = appendFloat(position, positionLongitude, p.Longitude)
+	if p.Speed > 0 {
+		// GTFS-Realtime speeds are in meters per second
+		position = appendFloat(position, positionSpeed, p.Speed/3.6)
+	}
+
+	var vp []byte
+	if trip != nil {
+		vp = appendMessage(vp, vehiclePositionTrip, trip)
+	}
+	vp = appendMessage(vp, vehiclePositionPosition, position)
+	vp = appendVarint(vp, vehiclePositionTimestamp, uint64(p.UpdatedAt.Unix()))
+	vp = appendMessage(vp, vehiclePositionVehicle, vehicle)
+	if c := p.Crowding; c != nil {
+		if status, ok := occupancyStatus[c.Level]; ok {
+			vp = appendVarint(vp, vehiclePositionOccupancy, status)
+		}
+		if c.Capacity > 0 {
+			vp = appendVarint(vp, vehiclePositionOccupancyPercentage, uint64(c.Occupancy*100/c.Capacity))
+		}
+	}
+
+	var entity []byte
+	entity = appendString(entity, feedEntityId, "vehicle_"+vehicleID)
+	entity = appendMessage(entity, feedEntityVehicle, vp)
+	return entity
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendFloat(b []byte, num protowire.Number, v float64) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed32Type)
+	return protowire.AppendFixed32(b, math.Float32bits(float32(v)))
+}
@@ -0,0 +1,97 @@
+package gtfs
+
+import (
+	"math"
+	"swift_transit/location"
+	"testing"
+	"time"
+
+	gtfsrt "github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+	"google.golang.org/protobuf/proto"
+)
+
+// The feed is encoded by hand, so decode it with the official bindings to
+// check every field number and wire type.
+func TestEncodeVehiclePositions(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	positions := []location.VehiclePosition{
+		{
+			LocationUpdate: location.LocationUpdate{
+				BusID:     7,
+				RouteID:   3,
+				Latitude:  23.7808,
+				Longitude: 90.4217,
+				Speed:     36,
+				Crowding:  &location.Crowding{Occupancy: 30, Capacity: 40, Level: location.CrowdingStanding},
+			},
+			UpdatedAt: at.Add(-10 * time.Second),
+		},
+		{
+			LocationUpdate: location.LocationUpdate{BusID: 2, Latitude: 23.75, Longitude: 90.39},
+			UpdatedAt:      at,
+		},
+	}
+
+	var feed gtfsrt.FeedMessage
+	if err := proto.Unmarshal(EncodeVehiclePositions(positions, at), &feed); err != nil {
+		t.Fatalf("feed does not decode: %v", err)
+	}
+
+	header := feed.GetHeader()
+	if header.GetGtfsRealtimeVersion() != realtimeVersion {
+		t.Errorf("version = %q, want %q", header.GetGtfsRealtimeVersion(), realtimeVersion)
+	}
+	if header.GetIncrementality() != gtfsrt.FeedHeader_FULL_DATASET {
+		t.Errorf("incrementality = %v, want FULL_DATASET", header.GetIncrementality())
+	}
+	if header.GetTimestamp() != uint64(at.Unix()) {
+		t.Errorf("timestamp = %d, want %d", header.GetTimestamp(), at.Unix())
+	}
+
+	if len(feed.GetEntity()) != 2 {
+		t.Fatalf("got %d entities, want 2", len(feed.GetEntity()))
+	}
+
+	// Entities are sorted by bus id
+	idle := feed.GetEntity()[0]
+	if idle.GetId() != "vehicle_2" {
+		t.Errorf("first entity id = %q, want vehicle_2", idle.GetId())
+	}
+	if idle.GetVehicle().Trip != nil {
+		t.Errorf("bus without a route has a trip: %v", idle.GetVehicle().Trip)
+	}
+	if idle.GetVehicle().OccupancyStatus != nil || idle.GetVehicle().OccupancyPercentage != nil {
+		t.Errorf("bus without crowding reports occupancy")
+	}
+
+	entity := feed.GetEntity()[1]
+	if entity.GetId() != "vehicle_7" {
+		t.Errorf("second entity id = %q, want vehicle_7", entity.GetId())
+	}
+	vp := entity.GetVehicle()
+	if vp.GetTrip().GetRouteId() != routeID(3) {
+		t.Errorf("route id = %q, want %q", vp.GetTrip().GetRouteId(), routeID(3))
+	}
+	if vp.GetVehicle().GetId() != "7" {
+		t.Errorf("vehicle id = %q, want 7", vp.GetVehicle().GetId())
+	}
+	if vp.GetTimestamp() != uint64(at.Add(-10*time.Second).Unix()) {
+		t.Errorf("vehicle timestamp = %d, want %d", vp.GetTimestamp(), at.Add(-10*time.Second).Unix())
+	}
+	pos := vp.GetPosition()
+	if math.Abs(float64(pos.GetLatitude())-23.7808) > 1e-4 || math.Abs(float64(pos.GetLongitude())-90.4217) > 1e-4 {
+		t.Errorf("position = %v,%v, want 23.7808,90.4217", pos.GetLatitude(), pos.GetLongitude())
+	}
+	if math.Abs(float64(pos.GetSpeed())-10) > 1e-4 {
+		t.Errorf("speed = %v m/s, want 10", pos.GetSpeed())
+	}
+	if vp.GetOccupancyStatus() != gtfsrt.VehiclePosition_STANDING_ROOM_ONLY {
+		t.Errorf("occupancy status = %v, want STANDING_ROOM_ONLY", vp.GetOccupancyStatus())
+	}
+	if vp.OccupancyPercentage == nil || vp.GetOccupancyPercentage() != 75 {
+		t.Errorf("occupancy percentage = %v, want 75", vp.OccupancyPercentage)
+	}
+	if len(vp.ProtoReflect().GetUnknown()) != 0 {
+		t.Errorf("vehicle position has unknown fields")
+	}
+}
@@ -0,0 +1,23 @@
+package gtfs
+
+import (
+	"swift_transit/fare"
+)
+
+type service struct {
+	routeRepo   RouteRepo
+	routeEditor RouteEditor
+	fareSvc     fare.Service
+	timetables  Timetables
+	agency      Agency
+}
+
+func NewService(routeRepo RouteRepo, routeEditor RouteEditor, fareSvc fare.Service, timetables Timetables, agency Agency) Service {
+	return &service{
+		routeRepo:   routeRepo,
+		routeEditor: routeEditor,
+		fareSvc:     fareSvc,
+		timetables:  timetables,
+		agency:      agency,
+	}
+}
@@ -0,0 +1,85 @@
+package location
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const locationChannel = "bus_locations"
+
+// Broker carries route messages to the hub of every API instance. Each
+// published message is delivered once on Updates of every instance, including
+// the one that published it.
+type Broker interface {
+	Publish(msg Envelope) error
+	Updates() <-chan Envelope
+}
+
+// memoryBroker delivers messages within a single instance.
+type memoryBroker struct {
+	updates chan Envelope
+}
+
+func NewMemoryBroker() Broker {
+	return &memoryBroker{
+		updates: make(chan Envelope, 256),
+	}
+}
+
+func (b *memoryBroker) Publish(msg Envelope) error {
+	b.updates <- msg
+	return nil
+}
+
+func (b *memoryBroker) Updates() <-chan Envelope {
+	return b.updates
+}
+
+// redisBroker shares messages between instances over Redis pub/sub. They are
+// only delivered through the subscription, never locally, so the publishing
+// instance does not see them twice.
+type redisBroker struct {
+	redis   *redis.Client
+	ctx     context.Context
+	updates chan Envelope
+}
+
+func NewRedisBroker(redis *redis.Client, ctx context.Context) Broker {
+	b := &redisBroker{
+		redis:   redis,
+		ctx:     ctx,
+		updates: make(chan Envelope, 256),
+	}
+	go b.subscribe()
+	return b
+}
+
+func (b *redisBroker) subscribe() {
+	// go-redis resubscribes on its own after a dropped connection
+	pubsub := b.redis.Subscribe(b.ctx, locationChannel)
+	defer pubsub.Close()
+
+	for msg := range pubsub.Channel() {
+		var env Envelope
+		if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil {
+			log.Printf("invalid location message: %v", err)
+			continue
+		}
+		b.updates <- env
+	}
+}
+
+func (b *redisBroker) Publish(msg Envelope) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return b.redis.Publish(b.ctx, locationChannel, data).Err()
+}
+
+func (b *redisBroker) Updates() <-chan Envelope {
+	return b.updates
+}
@@ -31,6 +31,14 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// Publisher identifies the bus allowed to publish on a connection, taken from
+// its token rather than from the messages it sends.
+type Publisher struct {
+	BusID              int64
+	RouteID            int64
+	RegistrationNumber string
+}
+
 type Client struct {
 	hub *Hub
 
@@ -38,13 +46,36 @@ type Client struct {
 	conn *websocket.Conn
 
 	// Buffered channel of outbound messages.
-	send chan LocationUpdate
+	send chan []byte
 
 	// Route ID the client is interested in
 	routeID int64
 
-	// Whether this client is allowed to publish location updates
-	canPublish bool
+	// Bus owner whose own messages the client receives instead of a route's
+	ownerID int64
+
+	// Message types a route subscriber receives; owners receive every type
+	types map[string]bool
+
+	// The authenticated bus behind this connection; nil for subscribers,
+	// which may not publish
+	publisher *Publisher
+
+	// Last known positions on the route, written before any live update
+	initial [][]byte
+}
+
+// wants reports whether the client receives messages of the given type.
+func (c *Client) wants(msgType string) bool {
+	return c.ownerID != 0 || c.types[msgType]
+}
+
+// key identifies the route or owner the client listens to.
+func (c *Client) key() int64 {
+	if c.ownerID != 0 {
+		return c.ownerID
+	}
+	return c.routeID
 }
 
 func (c *Client) readPump() {
@@ -64,16 +95,24 @@ func (c *Client) readPump() {
 			break
 		}
 
+		if c.publisher == nil {
+			log.Printf("closing subscriber connection that tried to publish")
+			c.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "subscribers cannot publish"),
+				time.Now().Add(writeWait))
+			break
+		}
+
 		var update LocationUpdate
 		if err := json.Unmarshal(message, &update); err != nil {
 			log.Printf("invalid location payload: %v", err)
 			continue
 		}
 
-		// Default to the subscribed route when the sender omits route_id
-		if update.RouteID == 0 {
-			update.RouteID = c.routeID
-		}
+		// The bus can only report its own position on its own route
+		update.BusID = c.publisher.BusID
+		update.RouteID = c.publisher.RouteID
+		update.RegistrationNumber = c.publisher.RegistrationNumber
 
 		c.hub.BroadcastLocation(update)
 	}
@@ -85,6 +124,15 @@ func (c *Client) writePump() {
 		ticker.Stop()
 		c.conn.Close()
 	}()
+
+	for _, message := range c.initial {
+		c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			return
+		}
+	}
+	c.initial = nil
+
 	for {
 		select {
 		case message, ok := <-c.send:
@@ -95,7 +143,7 @@ func (c *Client) writePump() {
 				return
 			}
 
-			c.conn.WriteJSON(message)
+			c.conn.WriteMessage(websocket.TextMessage, message)
 
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
@@ -106,13 +154,25 @@ func (c *Client) writePump() {
 	}
 }
 
-func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request, routeID int64, canPublish bool) {
+func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request, routeID int64, publisher *Publisher) {
+	positions, err := hub.RoutePositions(routeID)
+	if err != nil {
+		log.Printf("failed to load bus positions: %v", err)
+	}
+	var initial [][]byte
+	for _, p := range positions {
+		if msg, err := newEnvelope(routeID, MessageLocation, p.LocationUpdate); err == nil {
+			initial = append(initial, msg.Payload)
+		}
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
 		return
 	}
-	client := &Client{hub: hub, conn: conn, send: make(chan LocationUpdate, 256), routeID: routeID, canPublish: canPublish}
+	types := parseTypes(r.URL.Query().Get("types"))
+	client := &Client{hub: hub, conn: conn, send: make(chan []byte, 256), routeID: routeID, types: types, publisher: publisher, initial: initial}
 	client.hub.register <- client
 
 	// Allow collection of memory referenced by the caller by doing all work in
@@ -120,3 +180,18 @@ func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request, routeID int64, ca
 	go client.writePump()
 	go client.readPump()
 }
+
+// ServeOwnerWs streams the messages addressed to a bus owner, such as alerts
+// about their buses.
+func ServeOwnerWs(hub *Hub, w http.ResponseWriter, r *http.Request, ownerID int64) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	client := &Client{hub: hub, conn: conn, send: make(chan []byte, 256), ownerID: ownerID}
+	client.hub.register <- client
+
+	go client.writePump()
+	go client.readPump()
+}
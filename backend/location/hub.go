@@ -1,23 +1,69 @@
 package location
 
 import (
+	"log"
+	"swift_transit/domain"
 	"sync"
+	"time"
 )
 
 type LocationUpdate struct {
-	BusID     int64   `json:"bus_id"`
-	RouteID   int64   `json:"route_id"`
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
-	Speed     float64 `json:"speed"`
+	BusID              int64   `json:"bus_id"`
+	RouteID            int64   `json:"route_id"`
+	RegistrationNumber string  `json:"registration_number,omitempty"`
+	Latitude           float64 `json:"latitude"`
+	Longitude          float64 `json:"longitude"`
+	Speed              float64 `json:"speed"` // km/h
+	// Set by the hub from the bus's trip; never taken from the bus
+	Crowding *Crowding `json:"crowding,omitempty"`
+}
+
+// Crowding levels, from an empty bus to one that cannot take more riders
+const (
+	CrowdingEmpty     = "empty"
+	CrowdingManySeats = "many_seats"
+	CrowdingFewSeats  = "few_seats"
+	CrowdingStanding  = "standing_only"
+	CrowdingFull      = "full"
+)
+
+// Crowding tells riders how full a bus is. Level is empty when the seat
+// capacity of the bus is unknown.
+type Crowding struct {
+	Occupancy int    `json:"occupancy"`
+	Capacity  int    `json:"capacity"`
+	Level     string `json:"level,omitempty"`
+}
+
+// CrowdingSource reports how full a bus is, or nil when it is not on a trip.
+type CrowdingSource interface {
+	Crowding(busID int64) (*Crowding, error)
+}
+
+// VehiclePosition is the last location reported by a bus.
+type VehiclePosition struct {
+	LocationUpdate
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Observer reacts to bus positions after they are stored and published, and
+// may publish messages of its own through the hub.
+type Observer interface {
+	ObserveLocation(update LocationUpdate)
+}
+
+// StopObserver is an Observer that also wants the stop arrivals and
+// departures detected from the positions.
+type StopObserver interface {
+	ObserveStopEvent(event domain.StopEvent)
 }
 
 type Hub struct {
 	// Registered clients (users) listening for updates on specific routes
 	clients map[*Client]bool
 
-	// Inbound messages from buses
-	broadcast chan LocationUpdate
+	// Delivers updates published by buses on any instance
+	broker Broker
 
 	// Register requests from clients
 	register chan *Client
@@ -28,57 +74,189 @@ type Hub struct {
 	// Map routeID to list of clients
 	routeClients map[int64]map[*Client]bool
 
+	// Map ownerID to the bus owners' own connections
+	ownerClients map[int64]map[*Client]bool
+
+	// Last known position of every bus
+	positions PositionStore
+
+	// Detects arrivals and departures at stops
+	stops StopDetector
+
+	// Told about every position published from this instance
+	observers []Observer
+
+	// Fills in the crowding of published positions
+	crowding CrowdingSource
+
 	mu sync.RWMutex
 }
 
-func NewHub() *Hub {
+func NewHub(broker Broker, positions PositionStore, stops StopDetector) *Hub {
 	return &Hub{
-		broadcast:    make(chan LocationUpdate),
+		broker:       broker,
 		register:     make(chan *Client),
 		unregister:   make(chan *Client),
 		clients:      make(map[*Client]bool),
 		routeClients: make(map[int64]map[*Client]bool),
+		ownerClients: make(map[int64]map[*Client]bool),
+		positions:    positions,
+		stops:        stops,
 	}
 }
 
+// AddObserver registers an observer. It must be called before the hub starts
+// receiving locations.
+func (h *Hub) AddObserver(o Observer) {
+	h.observers = append(h.observers, o)
+}
+
+// SetCrowding sets where the crowding of buses comes from. Like AddObserver
+// it must be called before the hub starts receiving locations.
+func (h *Hub) SetCrowding(c CrowdingSource) {
+	h.crowding = c
+}
+
 func (h *Hub) Run() {
 	for {
 		select {
 		case client := <-h.register:
 			h.mu.Lock()
 			h.clients[client] = true
-			if _, ok := h.routeClients[client.routeID]; !ok {
-				h.routeClients[client.routeID] = make(map[*Client]bool)
+			group := h.group(client.ownerID)
+			if _, ok := group[client.key()]; !ok {
+				group[client.key()] = make(map[*Client]bool)
 			}
-			h.routeClients[client.routeID][client] = true
+			group[client.key()][client] = true
 			h.mu.Unlock()
 
 		case client := <-h.unregister:
 			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				delete(h.routeClients[client.routeID], client)
-				close(client.send)
-			}
+			h.remove(client)
 			h.mu.Unlock()
 
-		case update := <-h.broadcast:
-			h.mu.RLock()
-			clients := h.routeClients[update.RouteID]
-			for client := range clients {
+		case msg := <-h.broker.Updates():
+			// Never wait on a client here: a full buffer drops the client
+			h.mu.Lock()
+			key := msg.RouteID
+			if msg.OwnerID != 0 {
+				key = msg.OwnerID
+			}
+			for client := range h.group(msg.OwnerID)[key] {
+				if !client.wants(msg.Type) {
+					continue
+				}
 				select {
-				case client.send <- update:
+				case client.send <- msg.Payload:
 				default:
-					close(client.send)
-					delete(h.clients, client)
-					delete(h.routeClients[update.RouteID], client)
+					h.remove(client)
 				}
 			}
-			h.mu.RUnlock()
+			h.mu.Unlock()
 		}
 	}
 }
 
+// group returns the owner connections for owner messages and the route
+// subscribers otherwise. The caller holds h.mu.
+func (h *Hub) group(ownerID int64) map[int64]map[*Client]bool {
+	if ownerID != 0 {
+		return h.ownerClients
+	}
+	return h.routeClients
+}
+
+// remove drops a client and closes its send channel. The caller holds h.mu.
+func (h *Hub) remove(client *Client) {
+	if _, ok := h.clients[client]; !ok {
+		return
+	}
+	delete(h.clients, client)
+	delete(h.group(client.ownerID)[client.key()], client)
+	close(client.send)
+}
+
+// BroadcastLocation stores and fans out a bus position, with the crowding of
+// the bus and any stop arrival or departure it causes, then hands it to the
+// observers. The work is done by the caller so a slow store never holds up
+// the hub loop.
 func (h *Hub) BroadcastLocation(update LocationUpdate) {
-	h.broadcast <- update
+	update.Crowding = nil
+	if update.BusID != 0 && h.crowding != nil {
+		crowding, err := h.crowding.Crowding(update.BusID)
+		if err != nil {
+			log.Printf("failed to load bus crowding: %v", err)
+		}
+		update.Crowding = crowding
+	}
+	if update.BusID != 0 {
+		if err := h.positions.Save(VehiclePosition{LocationUpdate: update, UpdatedAt: time.Now()}); err != nil {
+			log.Printf("failed to save bus position: %v", err)
+		}
+	}
+	if err := h.Publish(update.RouteID, MessageLocation, update); err != nil {
+		log.Printf("failed to publish location update: %v", err)
+	}
+
+	if update.BusID == 0 {
+		return
+	}
+	if h.stops != nil {
+		events, err := h.stops.Detect(update)
+		if err != nil {
+			log.Printf("failed to detect stop events: %v", err)
+		}
+		for _, event := range events {
+			if err := h.Publish(event.RouteId, MessageStopEvent, event); err != nil {
+				log.Printf("failed to publish stop event: %v", err)
+			}
+			for _, o := range h.observers {
+				if so, ok := o.(StopObserver); ok {
+					so.ObserveStopEvent(event)
+				}
+			}
+		}
+	}
+	for _, o := range h.observers {
+		o.ObserveLocation(update)
+	}
+}
+
+// Publish sends a message of the given type to the subscribers of a route on
+// every instance.
+func (h *Hub) Publish(routeID int64, msgType string, v any) error {
+	msg, err := newEnvelope(routeID, msgType, v)
+	if err != nil {
+		return err
+	}
+	return h.broker.Publish(msg)
+}
+
+// PublishToOwner sends a message of the given type to the connections of a
+// bus owner on every instance.
+func (h *Hub) PublishToOwner(ownerID int64, msgType string, v any) error {
+	msg, err := newEnvelope(0, msgType, v)
+	if err != nil {
+		return err
+	}
+	msg.OwnerID = ownerID
+	return h.broker.Publish(msg)
+}
+
+// CurrentStop returns the stop a bus was last detected at, or nil.
+func (h *Hub) CurrentStop(busID int64) (*domain.Stop, error) {
+	if h.stops == nil {
+		return nil, nil
+	}
+	return h.stops.CurrentStop(busID)
+}
+
+// Positions returns the last position of every active bus.
+func (h *Hub) Positions() ([]VehiclePosition, error) {
+	return h.positions.All()
+}
+
+// RoutePositions returns the last position of every active bus on a route.
+func (h *Hub) RoutePositions(routeID int64) ([]VehiclePosition, error) {
+	return h.positions.ByRoute(routeID)
 }
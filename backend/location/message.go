@@ -0,0 +1,58 @@
+package location
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Message types, sent to subscribers in the "type" field
+const (
+	MessageLocation    = "location"
+	MessageStopEvent   = "stop_event"
+	MessageETA         = "eta"
+	MessageAlert       = "alert"
+	MessageTicketCheck = "ticket_check"
+	MessageReplayEnd   = "replay_end"
+)
+
+// Envelope is one message for the subscribers of a route, or of a bus owner
+// when OwnerID is set. Payload is the JSON written to their sockets as is.
+type Envelope struct {
+	RouteID int64           `json:"route_id"`
+	OwnerID int64           `json:"owner_id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// newEnvelope encodes v, which must encode to a JSON object, and adds its
+// message type to it.
+func newEnvelope(routeID int64, msgType string, v any) (Envelope, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return Envelope{}, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return Envelope{}, err
+	}
+	fields["type"], _ = json.Marshal(msgType)
+
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return Envelope{}, err
+	}
+	return Envelope{RouteID: routeID, Type: msgType, Payload: payload}, nil
+}
+
+// parseTypes reads the comma separated message types a route subscriber asked
+// for besides locations, e.g. "stop_event,eta". Older clients parse every
+// message as a location, so they get nothing else unless they ask.
+func parseTypes(list string) map[string]bool {
+	types := map[string]bool{MessageLocation: true}
+	for _, t := range strings.Split(list, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types[t] = true
+		}
+	}
+	return types
+}
@@ -0,0 +1,122 @@
+package location
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// PositionTTL is how long a bus stays active after its last update
+const PositionTTL = 5 * time.Minute
+
+const activeBusesKey = "active_buses"
+
+// PositionStore keeps the last known position of every bus so new
+// subscribers and REST clients do not wait for the next update.
+type PositionStore interface {
+	Save(p VehiclePosition) error
+	ByRoute(routeID int64) ([]VehiclePosition, error)
+	All() ([]VehiclePosition, error)
+}
+
+type redisPositionStore struct {
+	redis *redis.Client
+	ctx   context.Context
+}
+
+func NewRedisPositionStore(redis *redis.Client, ctx context.Context) PositionStore {
+	return &redisPositionStore{
+		redis: redis,
+		ctx:   ctx,
+	}
+}
+
+func positionKey(busID int64) string {
+	return fmt.Sprintf("bus_position:%d", busID)
+}
+
+func routeBusesKey(routeID int64) string {
+	return fmt.Sprintf("route_buses:%d", routeID)
+}
+
+func (s *redisPositionStore) Save(p VehiclePosition) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.redis.Pipelined(s.ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(s.ctx, positionKey(p.BusID), data, PositionTTL)
+		pipe.SAdd(s.ctx, routeBusesKey(p.RouteID), p.BusID)
+		pipe.Expire(s.ctx, routeBusesKey(p.RouteID), PositionTTL)
+		pipe.SAdd(s.ctx, activeBusesKey, p.BusID)
+		return nil
+	})
+	return err
+}
+
+func (s *redisPositionStore) ByRoute(routeID int64) ([]VehiclePosition, error) {
+	positions, err := s.load(routeBusesKey(routeID))
+	if err != nil {
+		return nil, err
+	}
+
+	// A bus that switched route is still listed under the old one until the
+	// set expires
+	onRoute := positions[:0]
+	for _, p := range positions {
+		if p.RouteID == routeID {
+			onRoute = append(onRoute, p)
+		}
+	}
+	return onRoute, nil
+}
+
+func (s *redisPositionStore) All() ([]VehiclePosition, error) {
+	return s.load(activeBusesKey)
+}
+
+// load reads the positions of the buses in a set and drops the ones whose
+// position has expired from it.
+func (s *redisPositionStore) load(setKey string) ([]VehiclePosition, error) {
+	ids, err := s.redis.SMembers(s.ctx, setKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	positions := []VehiclePosition{}
+	if len(ids) == 0 {
+		return positions, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		busID, _ := strconv.ParseInt(id, 10, 64)
+		keys[i] = positionKey(busID)
+	}
+	values, err := s.redis.MGet(s.ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []interface{}
+	for i, val := range values {
+		str, ok := val.(string)
+		if !ok {
+			expired = append(expired, ids[i])
+			continue
+		}
+		var p VehiclePosition
+		if err := json.Unmarshal([]byte(str), &p); err != nil {
+			continue
+		}
+		positions = append(positions, p)
+	}
+	if len(expired) > 0 {
+		s.redis.SRem(s.ctx, setKey, expired...)
+	}
+	return positions, nil
+}
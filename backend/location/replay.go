@@ -0,0 +1,79 @@
+package location
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Quiet stretches of a replay, such as a long wait at a terminal, are
+// shortened to at most this long
+const maxReplayGap = 5 * time.Second
+
+// Frame is one recorded message of a replay, sent as a message of type Type
+// with Data as its payload.
+type Frame struct {
+	At   time.Time
+	Type string
+	Data any
+}
+
+// ServeReplay writes the frames to a new socket in the live message format,
+// spaced by their recorded gaps divided by speed, and closes it with a
+// MessageReplayEnd message. It stops early when the peer goes away.
+func ServeReplay(w http.ResponseWriter, r *http.Request, routeID int64, frames []Frame, speed float64) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer conn.Close()
+
+	// The peer only sends control frames; reading handles them and notices
+	// when it disconnects
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn.SetReadLimit(maxMessageSize)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(msgType string, v any) bool {
+		msg, err := newEnvelope(routeID, msgType, v)
+		if err != nil {
+			log.Printf("failed to encode replay frame: %v", err)
+			return true
+		}
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		return conn.WriteMessage(websocket.TextMessage, msg.Payload) == nil
+	}
+
+	for i, frame := range frames {
+		if i > 0 {
+			gap := time.Duration(float64(frame.At.Sub(frames[i-1].At)) / speed)
+			if gap > maxReplayGap {
+				gap = maxReplayGap
+			}
+			if gap > 0 {
+				select {
+				case <-time.After(gap):
+				case <-done:
+					return
+				}
+			}
+		}
+		if !write(frame.Type, frame.Data) {
+			return
+		}
+	}
+
+	write(MessageReplayEnd, map[string]int{"frames": len(frames)})
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
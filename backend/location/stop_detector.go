@@ -0,0 +1,112 @@
+package location
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"swift_transit/domain"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// A bus that stops reporting inside a stop area is forgotten after this long
+const atStopTTL = 30 * time.Minute
+
+type StopRepo interface {
+	FindStopAt(routeId int64, lat, lon float64) (*domain.Stop, error)
+	CreateStopEvent(event domain.StopEvent) (*domain.StopEvent, error)
+}
+
+// StopDetector turns position updates into stop arrivals and departures
+// using the stops' area polygons.
+type StopDetector interface {
+	Detect(update LocationUpdate) ([]domain.StopEvent, error)
+	// CurrentStop returns the stop the bus is at, or nil between stops
+	CurrentStop(busID int64) (*domain.Stop, error)
+}
+
+// stopDetector keeps the stop each bus is at in Redis, so detection works
+// whichever instance the bus is connected to.
+type stopDetector struct {
+	repo  StopRepo
+	redis *redis.Client
+	ctx   context.Context
+}
+
+func NewStopDetector(repo StopRepo, redis *redis.Client, ctx context.Context) StopDetector {
+	return &stopDetector{
+		repo:  repo,
+		redis: redis,
+		ctx:   ctx,
+	}
+}
+
+func atStopKey(busID int64) string {
+	return fmt.Sprintf("bus_at_stop:%d", busID)
+}
+
+func (d *stopDetector) CurrentStop(busID int64) (*domain.Stop, error) {
+	val, err := d.redis.Get(d.ctx, atStopKey(busID)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var stop domain.Stop
+	if err := json.Unmarshal([]byte(val), &stop); err != nil {
+		return nil, err
+	}
+	return &stop, nil
+}
+
+func (d *stopDetector) Detect(update LocationUpdate) ([]domain.StopEvent, error) {
+	current, err := d.CurrentStop(update.BusID)
+	if err != nil {
+		return nil, err
+	}
+	stop, err := d.repo.FindStopAt(update.RouteID, update.Latitude, update.Longitude)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && stop != nil && current.Id == stop.Id {
+		// Still inside the same stop; keep it from expiring while waiting
+		d.redis.Expire(d.ctx, atStopKey(update.BusID), atStopTTL)
+		return nil, nil
+	}
+
+	now := time.Now()
+	var events []domain.StopEvent
+	if current != nil {
+		d.redis.Del(d.ctx, atStopKey(update.BusID))
+		event, err := d.record(update.BusID, current, domain.StopDeparted, now)
+		if err != nil {
+			return events, err
+		}
+		events = append(events, *event)
+	}
+	if stop != nil {
+		data, _ := json.Marshal(stop)
+		d.redis.Set(d.ctx, atStopKey(update.BusID), data, atStopTTL)
+		event, err := d.record(update.BusID, stop, domain.StopArrived, now)
+		if err != nil {
+			return events, err
+		}
+		events = append(events, *event)
+	}
+	return events, nil
+}
+
+func (d *stopDetector) record(busID int64, stop *domain.Stop, kind string, at time.Time) (*domain.StopEvent, error) {
+	stopID := stop.Id
+	return d.repo.CreateStopEvent(domain.StopEvent{
+		BusId:      busID,
+		RouteId:    stop.RouteId,
+		StopId:     &stopID,
+		StopName:   stop.Name,
+		StopOrder:  stop.Order,
+		Event:      kind,
+		OccurredAt: at,
+	})
+}
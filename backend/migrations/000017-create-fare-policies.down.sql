@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE IF EXISTS fare_tables;
+DROP TABLE IF EXISTS fare_policies;
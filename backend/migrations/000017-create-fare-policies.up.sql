@@ -0,0 +1,20 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS fare_policies (
+    route_id INT PRIMARY KEY REFERENCES routes(id) ON DELETE CASCADE,
+    base_fare FLOAT NOT NULL DEFAULT 0,
+    per_km_rate FLOAT NOT NULL DEFAULT 2.5,
+    min_fare FLOAT NOT NULL DEFAULT 10,
+    max_fare FLOAT,
+    rounding_mode VARCHAR(20) NOT NULL DEFAULT 'ceil',
+    rounding_step FLOAT NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS fare_tables (
+    id SERIAL PRIMARY KEY,
+    route_id INT NOT NULL REFERENCES routes(id) ON DELETE CASCADE,
+    from_stop TEXT NOT NULL,
+    to_stop TEXT NOT NULL,
+    fare FLOAT NOT NULL,
+    UNIQUE (route_id, from_stop, to_stop)
+);
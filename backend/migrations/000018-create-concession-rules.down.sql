@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE tickets DROP COLUMN IF EXISTS discount;
+DROP TABLE IF EXISTS concession_rules;
@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS concession_rules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    discount_percent FLOAT NOT NULL CHECK (discount_percent > 0 AND discount_percent <= 100),
+    valid_from VARCHAR(5) NOT NULL DEFAULT '',
+    valid_until VARCHAR(5) NOT NULL DEFAULT '',
+    route_ids INT[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS discount FLOAT NOT NULL DEFAULT 0;
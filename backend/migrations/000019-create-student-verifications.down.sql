@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE users DROP COLUMN IF EXISTS student_verified_until;
+DROP TABLE IF EXISTS student_verifications;
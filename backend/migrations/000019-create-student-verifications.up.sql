@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS student_verifications (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    institution_id VARCHAR(100) NOT NULL,
+    expires_at DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    note TEXT NOT NULL DEFAULT '',
+    reviewed_by INT REFERENCES admins(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_verifications_user ON student_verifications(user_id);
+CREATE INDEX IF NOT EXISTS idx_student_verifications_status ON student_verifications(status);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS student_verified_until DATE;
@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP TABLE IF EXISTS pass_usages;
+DROP TABLE IF EXISTS passes;
+DROP TABLE IF EXISTS pass_plans;
@@ -0,0 +1,41 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS pass_plans (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    period VARCHAR(10) NOT NULL CHECK (period IN ('DAILY', 'WEEKLY', 'MONTHLY')),
+    route_id INT REFERENCES routes(id) ON DELETE CASCADE,
+    price FLOAT NOT NULL CHECK (price > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS passes (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plan_id INT NOT NULL REFERENCES pass_plans(id),
+    route_id INT REFERENCES routes(id) ON DELETE CASCADE,
+    price FLOAT NOT NULL,
+    qr_code VARCHAR(100) NOT NULL UNIQUE,
+    payment_method VARCHAR(20) NOT NULL,
+    payment_status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    tran_id VARCHAR(100) UNIQUE,
+    valid_from TIMESTAMP,
+    valid_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_passes_user ON passes(user_id);
+
+CREATE TABLE IF NOT EXISTS pass_usages (
+    id SERIAL PRIMARY KEY,
+    pass_id INT NOT NULL REFERENCES passes(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    route_id INT NOT NULL,
+    registration_number VARCHAR(255) NOT NULL,
+    stop VARCHAR(255) NOT NULL DEFAULT '',
+    source VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pass_usages_pass ON pass_usages(pass_id);
+CREATE INDEX IF NOT EXISTS idx_pass_usages_registration ON pass_usages(registration_number);
@@ -0,0 +1,22 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS rfid_journeys (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    route_id INT NOT NULL,
+    bus_name VARCHAR(255) NOT NULL,
+    start_stop VARCHAR(255) NOT NULL,
+    end_stop VARCHAR(255),
+    hold_amount FLOAT NOT NULL,
+    -- The concession discount the hold was priced with, charged as is when
+    -- the rider never taps off
+    hold_discount FLOAT NOT NULL DEFAULT 0,
+    fare FLOAT,
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN',
+    ticket_id INT REFERENCES tickets(id) ON DELETE SET NULL,
+    tapped_on_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    tapped_off_at TIMESTAMP
+);
+
+-- At most one open journey per rider
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rfid_journeys_open_user ON rfid_journeys(user_id) WHERE status = 'OPEN';
+CREATE INDEX IF NOT EXISTS idx_rfid_journeys_status ON rfid_journeys(status, tapped_on_at);
@@ -0,0 +1,8 @@
+-- +migrate Down
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS checked_at,
+    DROP COLUMN IF EXISTS transfer_discount,
+    DROP COLUMN IF EXISTS leg_index,
+    DROP COLUMN IF EXISTS journey_id;
+
+DROP TABLE IF EXISTS multi_leg_journeys;
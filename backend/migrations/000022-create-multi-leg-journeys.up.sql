@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS multi_leg_journeys (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    total_fare FLOAT NOT NULL,
+    transfer_discount_percent FLOAT NOT NULL DEFAULT 0,
+    transfer_window_minutes INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE tickets
+    ADD COLUMN IF NOT EXISTS journey_id INT REFERENCES multi_leg_journeys(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS leg_index INT,
+    ADD COLUMN IF NOT EXISTS transfer_discount FLOAT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS checked_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tickets_journey ON tickets(journey_id);
@@ -0,0 +1,6 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_routes_gtfs;
+
+ALTER TABLE routes
+    DROP COLUMN IF EXISTS gtfs_direction,
+    DROP COLUMN IF EXISTS gtfs_route_id;
@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE routes
+    ADD COLUMN IF NOT EXISTS gtfs_route_id TEXT,
+    ADD COLUMN IF NOT EXISTS gtfs_direction INT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_routes_gtfs ON routes(gtfs_route_id, gtfs_direction) WHERE gtfs_route_id IS NOT NULL;
@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS stop_events (
+    id SERIAL PRIMARY KEY,
+    bus_id INT NOT NULL REFERENCES bus_credentials(id) ON DELETE CASCADE,
+    route_id INT NOT NULL REFERENCES routes(id) ON DELETE CASCADE,
+    stop_id INT REFERENCES stops(id) ON DELETE SET NULL,
+    stop_name TEXT NOT NULL,
+    stop_order INT NOT NULL,
+    event VARCHAR(10) NOT NULL CHECK (event IN ('arrived', 'departed')),
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stop_events_bus ON stop_events(bus_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_stop_events_route ON stop_events(route_id, occurred_at);
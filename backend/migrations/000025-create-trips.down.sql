@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE IF EXISTS trip_points;
+DROP TABLE IF EXISTS trips;
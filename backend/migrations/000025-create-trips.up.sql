@@ -0,0 +1,27 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS trips (
+    id SERIAL PRIMARY KEY,
+    bus_id INT NOT NULL REFERENCES bus_credentials(id) ON DELETE CASCADE,
+    route_id INT NOT NULL REFERENCES routes(id) ON DELETE CASCADE,
+    variant VARCHAR(10) NOT NULL,
+    driver_name VARCHAR(100) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed')),
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ended_at TIMESTAMP,
+    path GEOMETRY(LINESTRING, 4326),
+    distance_meters DOUBLE PRECISION NOT NULL DEFAULT 0
+);
+
+-- A bus drives one trip at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trips_active_bus ON trips(bus_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_trips_bus ON trips(bus_id, started_at);
+
+CREATE TABLE IF NOT EXISTS trip_points (
+    id BIGSERIAL PRIMARY KEY,
+    trip_id INT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+    geom GEOMETRY(POINT, 4326) NOT NULL,
+    speed DOUBLE PRECISION NOT NULL DEFAULT 0,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_points_trip ON trip_points(trip_id, recorded_at);
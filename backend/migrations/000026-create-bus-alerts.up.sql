@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS bus_alerts (
+    id SERIAL PRIMARY KEY,
+    bus_id INT NOT NULL REFERENCES bus_credentials(id) ON DELETE CASCADE,
+    owner_id INT REFERENCES bus_owners(id) ON DELETE SET NULL,
+    route_id INT NOT NULL REFERENCES routes(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('off_route', 'overspeed')),
+    message TEXT NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    speed DOUBLE PRECISION NOT NULL DEFAULT 0,
+    distance_meters DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bus_alerts_owner ON bus_alerts(owner_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_bus_alerts_created ON bus_alerts(created_at);
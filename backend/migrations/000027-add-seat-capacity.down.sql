@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE trips DROP COLUMN IF EXISTS seat_capacity;
+ALTER TABLE bus_credentials DROP COLUMN IF EXISTS seat_capacity;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE bus_credentials ADD COLUMN IF NOT EXISTS seat_capacity INT NOT NULL DEFAULT 0;
+
+-- Kept on the trip so crowding stays consistent if the bus is edited mid-trip
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS seat_capacity INT NOT NULL DEFAULT 0;
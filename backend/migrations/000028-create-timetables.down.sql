@@ -0,0 +1,10 @@
+-- +migrate Down
+ALTER TABLE trips
+    DROP COLUMN IF EXISTS delay_seconds,
+    DROP COLUMN IF EXISTS service_date,
+    DROP COLUMN IF EXISTS scheduled_trip_id;
+
+DROP TABLE IF EXISTS scheduled_stop_times;
+DROP TABLE IF EXISTS scheduled_trips;
+DROP TABLE IF EXISTS calendar_exceptions;
+DROP TABLE IF EXISTS service_calendars;
@@ -0,0 +1,57 @@
+-- +migrate Up
+-- Service calendars follow GTFS calendar.txt and calendar_dates.txt. A NULL
+-- owner_id marks a calendar shared by every operator.
+CREATE TABLE IF NOT EXISTS service_calendars (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    owner_id INT REFERENCES bus_owners(id) ON DELETE CASCADE,
+    monday BOOLEAN NOT NULL DEFAULT FALSE,
+    tuesday BOOLEAN NOT NULL DEFAULT FALSE,
+    wednesday BOOLEAN NOT NULL DEFAULT FALSE,
+    thursday BOOLEAN NOT NULL DEFAULT FALSE,
+    friday BOOLEAN NOT NULL DEFAULT FALSE,
+    saturday BOOLEAN NOT NULL DEFAULT FALSE,
+    sunday BOOLEAN NOT NULL DEFAULT FALSE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+CREATE TABLE IF NOT EXISTS calendar_exceptions (
+    id SERIAL PRIMARY KEY,
+    calendar_id INT NOT NULL REFERENCES service_calendars(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('added', 'removed')),
+    note TEXT NOT NULL DEFAULT '',
+    UNIQUE (calendar_id, date)
+);
+
+-- Times are seconds after midnight of the service day and may pass 24:00:00
+-- for trips that run past midnight
+CREATE TABLE IF NOT EXISTS scheduled_trips (
+    id SERIAL PRIMARY KEY,
+    route_id INT NOT NULL REFERENCES routes(id) ON DELETE CASCADE,
+    calendar_id INT NOT NULL REFERENCES service_calendars(id) ON DELETE RESTRICT,
+    owner_id INT REFERENCES bus_owners(id) ON DELETE CASCADE,
+    headsign VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_trips_route ON scheduled_trips(route_id);
+
+CREATE TABLE IF NOT EXISTS scheduled_stop_times (
+    id SERIAL PRIMARY KEY,
+    scheduled_trip_id INT NOT NULL REFERENCES scheduled_trips(id) ON DELETE CASCADE,
+    stop_id INT NOT NULL REFERENCES stops(id) ON DELETE CASCADE,
+    stop_order INT NOT NULL,
+    arrival_seconds INT NOT NULL CHECK (arrival_seconds >= 0),
+    departure_seconds INT NOT NULL,
+    CHECK (departure_seconds >= arrival_seconds),
+    UNIQUE (scheduled_trip_id, stop_order)
+);
+
+ALTER TABLE trips
+    ADD COLUMN IF NOT EXISTS scheduled_trip_id INT REFERENCES scheduled_trips(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS service_date DATE,
+    ADD COLUMN IF NOT EXISTS delay_seconds INT;
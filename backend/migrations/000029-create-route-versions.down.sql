@@ -0,0 +1,5 @@
+-- +migrate Down
+ALTER TABLE tickets DROP COLUMN IF EXISTS route_version_id;
+
+DROP TABLE IF EXISTS route_version_stops;
+DROP TABLE IF EXISTS route_versions;
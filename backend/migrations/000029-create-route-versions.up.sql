@@ -0,0 +1,45 @@
+-- +migrate Up
+-- Every edit of a route is kept as a version. routes.geom and stops hold the
+-- version applied last; the others are kept for the tickets sold on them.
+CREATE TABLE IF NOT EXISTS route_versions (
+    id SERIAL PRIMARY KEY,
+    route_id INT NOT NULL REFERENCES routes(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    name TEXT NOT NULL,
+    geom geometry(LineString, 4326),
+    effective_from TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    applied_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (route_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_route_versions_pending ON route_versions(effective_from) WHERE applied_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS route_version_stops (
+    id SERIAL PRIMARY KEY,
+    version_id INT NOT NULL REFERENCES route_versions(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    stop_order INT NOT NULL,
+    geom geometry(Point, 4326),
+    area_geom geometry(Polygon, 4326)
+);
+
+CREATE INDEX IF NOT EXISTS idx_route_version_stops_version ON route_version_stops(version_id, name);
+
+-- Existing routes become version 1, in effect since before any ticket
+INSERT INTO route_versions (route_id, version, name, geom, effective_from, applied_at)
+SELECT id, 1, name, geom, 'epoch', 'epoch'
+FROM routes
+ON CONFLICT (route_id, version) DO NOTHING;
+
+INSERT INTO route_version_stops (version_id, name, stop_order, geom, area_geom)
+SELECT v.id, s.name, s.stop_order, s.geom, s.area_geom
+FROM stops s
+JOIN route_versions v ON v.route_id = s.route_id AND v.version = 1;
+
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS route_version_id INT REFERENCES route_versions(id) ON DELETE SET NULL;
+
+UPDATE tickets t
+SET route_version_id = v.id
+FROM route_versions v
+WHERE v.route_id = t.route_id AND v.version = 1 AND t.route_version_id IS NULL;
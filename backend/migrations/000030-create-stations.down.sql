@@ -0,0 +1,4 @@
+-- +migrate Down
+ALTER TABLE stops DROP COLUMN IF EXISTS station_id;
+
+DROP TABLE IF EXISTS stations;
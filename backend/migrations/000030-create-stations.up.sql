@@ -0,0 +1,19 @@
+-- +migrate Up
+-- A station is one physical stop. Each route keeps its own stops, with their
+-- own order, and points them at the station they serve.
+CREATE TABLE IF NOT EXISTS stations (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    geom geometry(Point, 4326) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stations_geom ON stations USING GIST (geom);
+CREATE INDEX IF NOT EXISTS idx_stations_name ON stations(lower(name));
+
+ALTER TABLE stops ADD COLUMN IF NOT EXISTS station_id INT REFERENCES stations(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_stops_station ON stops(station_id);
+
+-- Existing stops are linked to stations at startup by the same rule as new
+-- ones (see LinkStations in repo/station.go)
@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_stops_geography;
+DROP INDEX IF EXISTS idx_stops_name_key;
@@ -0,0 +1,4 @@
+-- +migrate Up
+-- Transfer points join stops by name and by walking distance
+CREATE INDEX IF NOT EXISTS idx_stops_name_key ON stops (lower(trim(name)));
+CREATE INDEX IF NOT EXISTS idx_stops_geography ON stops USING GIST ((geom::geography));
@@ -0,0 +1,66 @@
+package pass
+
+import (
+	"swift_transit/domain"
+	"time"
+)
+
+type BuyPassRequest struct {
+	UserId        int64  `json:"-"` // Extracted from JWT
+	PlanId        int64  `json:"plan_id"`
+	PaymentMethod string `json:"payment_method"` // "wallet" or "gateway"
+}
+
+type BuyPassResponse struct {
+	Pass       *domain.Pass `json:"pass"`
+	PaymentURL string       `json:"payment_url,omitempty"`
+	Message    string       `json:"message"`
+}
+
+// BoardRequest describes a single boarding made with a pass.
+type BoardRequest struct {
+	RouteId            int64
+	RegistrationNumber string
+	Stop               string
+	Source             string // QR, RFID
+}
+
+type Service interface {
+	GetPlans(activeOnly bool) ([]domain.PassPlan, error)
+	CreatePlan(plan domain.PassPlan) (*domain.PassPlan, error)
+	UpdatePlan(plan domain.PassPlan) error
+	DeletePlan(id int64) error
+
+	BuyPass(req BuyPassRequest) (*BuyPassResponse, error)
+	CompletePayment(tranID, valID string) (*domain.Pass, error)
+	CancelPayment(tranID string) error
+	GetUserPasses(userId int64) ([]domain.Pass, error)
+
+	// FindActivePass returns the rider's pass covering the route at the given
+	// time, or nil when there is none.
+	FindActivePass(userId, routeId int64, at time.Time) (*domain.Pass, error)
+	// BoardWithQR validates a pass QR code for the route and logs the boarding.
+	BoardWithQR(qrCode string, req BoardRequest) (*domain.Pass, error)
+	// Board logs a boarding for an already validated pass. It returns false
+	// when the same pass was just used on the same bus.
+	Board(p *domain.Pass, req BoardRequest) (bool, error)
+}
+
+type PassRepo interface {
+	GetPlans(activeOnly bool) ([]domain.PassPlan, error)
+	GetPlan(id int64) (*domain.PassPlan, error)
+	CreatePlan(plan domain.PassPlan) (*domain.PassPlan, error)
+	UpdatePlan(plan domain.PassPlan) error
+	DeletePlan(id int64) error
+
+	Create(p domain.Pass) (*domain.Pass, error)
+	GetByTranID(tranID string) (*domain.Pass, error)
+	GetByQRCode(qrCode string) (*domain.Pass, error)
+	Activate(id int64, validFrom, validUntil time.Time) (bool, error)
+	UpdatePaymentStatus(id int64, status string) error
+	GetByUserID(userId int64) ([]domain.Pass, error)
+	FindActive(userId, routeId int64, at time.Time) (*domain.Pass, error)
+
+	CreateUsage(usage domain.PassUsage) error
+	GetLatestUsage(passId int64) (*domain.PassUsage, error)
+}
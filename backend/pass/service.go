@@ -0,0 +1,305 @@
+package pass
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"swift_transit/domain"
+	"swift_transit/infra/payment"
+	"swift_transit/model"
+	"swift_transit/user"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QRPrefix marks pass QR codes so checkers can tell them apart from tickets.
+const QRPrefix = "PASS-"
+
+// A pass scanned again on the same bus within this window is treated as the
+// same boarding.
+const duplicateWindow = 5 * time.Minute
+
+type TransactionRepo interface {
+	Create(t model.Transaction) error
+}
+
+type service struct {
+	repo            PassRepo
+	userRepo        user.UserRepo
+	transactionRepo TransactionRepo
+	sslCommerz      *payment.SSLCommerz
+	publicBaseURL   string
+}
+
+func NewService(repo PassRepo, userRepo user.UserRepo, transactionRepo TransactionRepo, sslCommerz *payment.SSLCommerz, publicBaseURL string) Service {
+	return &service{
+		repo:            repo,
+		userRepo:        userRepo,
+		transactionRepo: transactionRepo,
+		sslCommerz:      sslCommerz,
+		publicBaseURL:   strings.TrimRight(publicBaseURL, "/"),
+	}
+}
+
+func (s *service) GetPlans(activeOnly bool) ([]domain.PassPlan, error) {
+	return s.repo.GetPlans(activeOnly)
+}
+
+func (s *service) CreatePlan(plan domain.PassPlan) (*domain.PassPlan, error) {
+	if err := validatePlan(&plan); err != nil {
+		return nil, err
+	}
+	return s.repo.CreatePlan(plan)
+}
+
+func (s *service) UpdatePlan(plan domain.PassPlan) error {
+	if err := validatePlan(&plan); err != nil {
+		return err
+	}
+	return s.repo.UpdatePlan(plan)
+}
+
+func (s *service) DeletePlan(id int64) error {
+	return s.repo.DeletePlan(id)
+}
+
+func (s *service) BuyPass(req BuyPassRequest) (*BuyPassResponse, error) {
+	if req.UserId == 0 || req.PlanId == 0 {
+		return nil, fmt.Errorf("invalid request")
+	}
+
+	plan, err := s.repo.GetPlan(req.PlanId)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.Active {
+		return nil, fmt.Errorf("pass plan is not available")
+	}
+
+	p := domain.Pass{
+		UserId:  req.UserId,
+		PlanId:  plan.Id,
+		RouteId: plan.RouteId,
+		Price:   plan.Price,
+		QRCode:  QRPrefix + uuid.New().String(),
+	}
+
+	switch req.PaymentMethod {
+	case "wallet":
+		if err := s.userRepo.DeductBalance(req.UserId, plan.Price); err != nil {
+			return nil, fmt.Errorf("payment failed: %w", err)
+		}
+
+		validFrom := time.Now()
+		validUntil := validUntil(plan.Period, validFrom)
+		p.PaymentMethod = "wallet"
+		p.PaymentStatus = "paid"
+		p.ValidFrom = &validFrom
+		p.ValidUntil = &validUntil
+
+		created, err := s.repo.Create(p)
+		if err != nil {
+			s.userRepo.CreditBalance(req.UserId, plan.Price)
+			return nil, fmt.Errorf("failed to create pass: %w", err)
+		}
+
+		s.transactionRepo.Create(model.Transaction{
+			UserID:        int(req.UserId),
+			Amount:        plan.Price,
+			Type:          "purchase",
+			Description:   fmt.Sprintf("Travel pass - %s", plan.Name),
+			PaymentMethod: "wallet",
+			CreatedAt:     time.Now(),
+		})
+
+		return &BuyPassResponse{Pass: created, Message: "Pass purchased successfully"}, nil
+
+	case "gateway":
+		tranID := fmt.Sprintf("PASS-%d-%s", req.UserId, uuid.NewString()[:8])
+		p.PaymentMethod = "SSLCommerz"
+		p.PaymentStatus = "pending"
+		p.TranId = &tranID
+
+		created, err := s.repo.Create(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create pass: %w", err)
+		}
+
+		successURL := fmt.Sprintf("%s/pass/payment/success?tran_id=%s", s.publicBaseURL, tranID)
+		failURL := fmt.Sprintf("%s/pass/payment/fail?tran_id=%s", s.publicBaseURL, tranID)
+		cancelURL := fmt.Sprintf("%s/pass/payment/cancel?tran_id=%s", s.publicBaseURL, tranID)
+
+		gatewayURL, err := s.sslCommerz.InitPayment(plan.Price, tranID, successURL, failURL, cancelURL)
+		if err != nil {
+			s.repo.UpdatePaymentStatus(created.Id, "cancelled")
+			return nil, fmt.Errorf("failed to initiate payment: %w", err)
+		}
+
+		return &BuyPassResponse{Pass: created, PaymentURL: gatewayURL, Message: "Complete the payment to activate your pass"}, nil
+
+	default:
+		return nil, fmt.Errorf("invalid payment method")
+	}
+}
+
+func (s *service) CompletePayment(tranID, valID string) (*domain.Pass, error) {
+	p, err := s.repo.GetByTranID(tranID)
+	if err != nil {
+		return nil, err
+	}
+	if p.PaymentStatus == "paid" {
+		return p, nil
+	}
+	if p.PaymentStatus != "pending" {
+		return nil, fmt.Errorf("pass payment was %s", p.PaymentStatus)
+	}
+
+	resp, err := s.sslCommerz.ValidateTransaction(valID)
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if resp.Status != "VALID" && resp.Status != "VALIDATED" {
+		return nil, fmt.Errorf("invalid transaction status: %s", resp.Status)
+	}
+	if resp.TranID != "" && resp.TranID != tranID {
+		return nil, fmt.Errorf("transaction mismatch")
+	}
+	amount, err := strconv.ParseFloat(resp.Amount, 64)
+	if err != nil {
+		return nil, err
+	}
+	if amount != p.Price {
+		return nil, fmt.Errorf("amount mismatch: expected %.2f, got %.2f", p.Price, amount)
+	}
+
+	// The validity window starts when the payment clears, not when checkout began
+	validFrom := time.Now()
+	validUntil := validUntil(p.Period, validFrom)
+	activated, err := s.repo.Activate(p.Id, validFrom, validUntil)
+	if err != nil {
+		return nil, err
+	}
+	if !activated {
+		// A concurrent callback activated it and recorded the purchase
+		return s.repo.GetByTranID(tranID)
+	}
+
+	if err := s.transactionRepo.Create(model.Transaction{
+		UserID:        int(p.UserId),
+		Amount:        p.Price,
+		Type:          "purchase",
+		Description:   fmt.Sprintf("Travel pass - %s", p.PlanName),
+		PaymentMethod: "SSLCommerz",
+		CreatedAt:     time.Now(),
+	}); err != nil {
+		// The pass is paid for either way; a retried callback would not
+		// record the purchase again
+		log.Printf("failed to record purchase of pass %d: %v", p.Id, err)
+	}
+
+	p.PaymentStatus = "paid"
+	p.ValidFrom = &validFrom
+	p.ValidUntil = &validUntil
+	return p, nil
+}
+
+func (s *service) CancelPayment(tranID string) error {
+	p, err := s.repo.GetByTranID(tranID)
+	if err != nil {
+		return err
+	}
+	if p.PaymentStatus != "pending" {
+		return nil
+	}
+	return s.repo.UpdatePaymentStatus(p.Id, "cancelled")
+}
+
+func (s *service) GetUserPasses(userId int64) ([]domain.Pass, error) {
+	return s.repo.GetByUserID(userId)
+}
+
+func (s *service) FindActivePass(userId, routeId int64, at time.Time) (*domain.Pass, error) {
+	return s.repo.FindActive(userId, routeId, at)
+}
+
+func (s *service) BoardWithQR(qrCode string, req BoardRequest) (*domain.Pass, error) {
+	p, err := s.repo.GetByQRCode(qrCode)
+	if err != nil {
+		return nil, fmt.Errorf("pass not found")
+	}
+	if p.PaymentStatus != "paid" {
+		return nil, fmt.Errorf("pass is unpaid")
+	}
+
+	now := time.Now()
+	if p.ValidFrom == nil || p.ValidUntil == nil || now.Before(*p.ValidFrom) || !now.Before(*p.ValidUntil) {
+		return nil, fmt.Errorf("pass has expired")
+	}
+	if p.RouteId != nil && *p.RouteId != req.RouteId {
+		return nil, fmt.Errorf("pass is not valid for this route")
+	}
+
+	boarded, err := s.Board(p, req)
+	if err != nil {
+		return nil, err
+	}
+	if !boarded {
+		return nil, fmt.Errorf("pass already used on this bus")
+	}
+	return p, nil
+}
+
+func (s *service) Board(p *domain.Pass, req BoardRequest) (bool, error) {
+	last, err := s.repo.GetLatestUsage(p.Id)
+	if err != nil {
+		return false, err
+	}
+	if last != nil && last.RegistrationNumber == req.RegistrationNumber && time.Since(last.CreatedAt) < duplicateWindow {
+		return false, nil
+	}
+
+	err = s.repo.CreateUsage(domain.PassUsage{
+		PassId:             p.Id,
+		UserId:             p.UserId,
+		RouteId:            req.RouteId,
+		RegistrationNumber: req.RegistrationNumber,
+		Stop:               req.Stop,
+		Source:             req.Source,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to log pass usage: %w", err)
+	}
+	return true, nil
+}
+
+func validUntil(period string, from time.Time) time.Time {
+	switch period {
+	case domain.PassPeriodWeekly:
+		return from.AddDate(0, 0, 7)
+	case domain.PassPeriodMonthly:
+		return from.AddDate(0, 1, 0)
+	default:
+		return from.AddDate(0, 0, 1)
+	}
+}
+
+func validatePlan(plan *domain.PassPlan) error {
+	if plan.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	plan.Period = strings.ToUpper(plan.Period)
+	switch plan.Period {
+	case domain.PassPeriodDaily, domain.PassPeriodWeekly, domain.PassPeriodMonthly:
+	default:
+		return fmt.Errorf("period must be DAILY, WEEKLY or MONTHLY")
+	}
+	if plan.Price <= 0 {
+		return fmt.Errorf("price must be positive")
+	}
+	if plan.RouteId != nil && *plan.RouteId == 0 {
+		plan.RouteId = nil
+	}
+	return nil
+}
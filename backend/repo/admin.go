@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"swift_transit/domain"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -34,6 +35,10 @@ type AdminRepo interface {
 	// Routes
 	GetAllRoutes(limit, offset int) ([]domain.Route, int, error)
 	DeleteRoute(id int64) error
+	CountRouteTickets(routeId int64) (int, error)
+
+	// Stations
+	GetStationActivity(from, to time.Time, limit int) ([]domain.StationActivity, error)
 
 	// Tickets
 	GetAllTickets(limit, offset int) ([]domain.Ticket, int, error)
@@ -148,6 +153,11 @@ func (r *adminRepo) GetDashboardStats() (map[string]interface{}, error) {
 	r.db.QueryRow(`SELECT COUNT(*) FROM routes`).Scan(&totalRoutes)
 	stats["total_routes"] = totalRoutes
 
+	// Total stations
+	var totalStations int
+	r.db.QueryRow(`SELECT COUNT(*) FROM stations`).Scan(&totalStations)
+	stats["total_stations"] = totalStations
+
 	// Total tickets
 	var totalTickets int
 	r.db.QueryRow(`SELECT COUNT(*) FROM tickets`).Scan(&totalTickets)
@@ -237,7 +247,7 @@ func (r *adminRepo) GetAllBuses(limit, offset int) ([]domain.BusCredential, int,
 	var total int
 	r.db.QueryRow(`SELECT COUNT(*) FROM bus_credentials`).Scan(&total)
 
-	query := `SELECT id, registration_number, route_id_up, route_id_down, owner_id FROM bus_credentials ORDER BY id DESC LIMIT $1 OFFSET $2`
+	query := `SELECT id, registration_number, route_id_up, route_id_down, owner_id, seat_capacity FROM bus_credentials ORDER BY id DESC LIMIT $1 OFFSET $2`
 	rows, err := r.db.Query(query, limit, offset)
 	if err != nil {
 		return nil, 0, err
@@ -248,7 +258,7 @@ func (r *adminRepo) GetAllBuses(limit, offset int) ([]domain.BusCredential, int,
 	for rows.Next() {
 		var bus domain.BusCredential
 		var ownerID sql.NullInt64
-		rows.Scan(&bus.Id, &bus.RegistrationNumber, &bus.RouteIdUp, &bus.RouteIdDown, &ownerID)
+		rows.Scan(&bus.Id, &bus.RegistrationNumber, &bus.RouteIdUp, &bus.RouteIdDown, &ownerID, &bus.SeatCapacity)
 		if ownerID.Valid {
 			id := ownerID.Int64
 			bus.OwnerId = &id
@@ -261,8 +271,8 @@ func (r *adminRepo) GetAllBuses(limit, offset int) ([]domain.BusCredential, int,
 func (r *adminRepo) GetBusByID(id int64) (*domain.BusCredential, error) {
 	var bus domain.BusCredential
 	var ownerID sql.NullInt64
-	query := `SELECT id, registration_number, route_id_up, route_id_down, owner_id FROM bus_credentials WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&bus.Id, &bus.RegistrationNumber, &bus.RouteIdUp, &bus.RouteIdDown, &ownerID)
+	query := `SELECT id, registration_number, route_id_up, route_id_down, owner_id, seat_capacity FROM bus_credentials WHERE id = $1`
+	err := r.db.QueryRow(query, id).Scan(&bus.Id, &bus.RegistrationNumber, &bus.RouteIdUp, &bus.RouteIdDown, &ownerID, &bus.SeatCapacity)
 	if err != nil {
 		return nil, err
 	}
@@ -274,8 +284,8 @@ func (r *adminRepo) GetBusByID(id int64) (*domain.BusCredential, error) {
 }
 
 func (r *adminRepo) UpdateBus(bus domain.BusCredential) error {
-	query := `UPDATE bus_credentials SET registration_number = $1, route_id_up = $2, route_id_down = $3 WHERE id = $4`
-	_, err := r.db.Exec(query, bus.RegistrationNumber, bus.RouteIdUp, bus.RouteIdDown, bus.Id)
+	query := `UPDATE bus_credentials SET registration_number = $1, route_id_up = $2, route_id_down = $3, seat_capacity = $4 WHERE id = $5`
+	_, err := r.db.Exec(query, bus.RegistrationNumber, bus.RouteIdUp, bus.RouteIdDown, bus.SeatCapacity, bus.Id)
 	return err
 }
 
@@ -310,6 +320,46 @@ func (r *adminRepo) DeleteRoute(id int64) error {
 	return err
 }
 
+func (r *adminRepo) CountRouteTickets(routeId int64) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM tickets WHERE route_id = $1`, routeId).Scan(&count)
+	return count, err
+}
+
+// Stations
+
+// GetStationActivity counts the paid tickets bought between from and to by
+// the station they start and end at, across every route, busiest first.
+func (r *adminRepo) GetStationActivity(from, to time.Time, limit int) ([]domain.StationActivity, error) {
+	query := `
+		SELECT st.id, st.name,
+			COUNT(*) FILTER (WHERE s.name = t.start_destination) AS boardings,
+			COUNT(*) FILTER (WHERE s.name = t.end_destination) AS alightings
+		FROM tickets t
+		JOIN stops s ON s.route_id = t.route_id AND s.name IN (t.start_destination, t.end_destination)
+		JOIN stations st ON st.id = s.station_id
+		WHERE t.payment_status = 'paid' AND t.created_at >= $1 AND t.created_at < $2
+		GROUP BY st.id, st.name
+		ORDER BY COUNT(*) DESC, st.name
+		LIMIT $3
+	`
+	rows, err := r.db.Query(query, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get station activity: %w", err)
+	}
+	defer rows.Close()
+
+	activity := []domain.StationActivity{}
+	for rows.Next() {
+		var a domain.StationActivity
+		if err := rows.Scan(&a.StationId, &a.Name, &a.Boardings, &a.Alightings); err != nil {
+			return nil, err
+		}
+		activity = append(activity, a)
+	}
+	return activity, rows.Err()
+}
+
 // Tickets
 func (r *adminRepo) GetAllTickets(limit, offset int) ([]domain.Ticket, int, error) {
 	var total int
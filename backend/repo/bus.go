@@ -37,14 +37,7 @@ func (r *busRepo) FindBus(start, end string) ([]domain.Bus, error) {
 					ST_LineLocatePoint(r.geom, s1.geom), 
 					ST_LineLocatePoint(r.geom, s2.geom)
 				)
-			) as linestring_geojson,
-			GREATEST(10, (ST_Length(
-				ST_LineSubstring(
-					r.geom, 
-					ST_LineLocatePoint(r.geom, s1.geom), 
-					ST_LineLocatePoint(r.geom, s2.geom)
-				)::geography
-			) / 1000)*2.5) as fare
+			) as linestring_geojson
 		FROM routes r
 		JOIN stops s1 ON r.id = s1.route_id
 		JOIN stops s2 ON r.id = s2.route_id
@@ -86,9 +79,9 @@ func (r *busRepo) GetBusByRegistrationNumber(regNum string) (*domain.BusCredenti
 
 func (r *busRepo) Create(busCred domain.BusCredential) (*domain.BusCredential, error) {
 	query := `
-                INSERT INTO bus_credentials (registration_number, password, route_id_up, route_id_down, owner_id)
-                VALUES ($1, $2, $3, $4, $5)
-                RETURNING id, registration_number, password, route_id_up, route_id_down, owner_id
+                INSERT INTO bus_credentials (registration_number, password, route_id_up, route_id_down, owner_id, seat_capacity)
+                VALUES ($1, $2, $3, $4, $5, $6)
+                RETURNING id, registration_number, password, route_id_up, route_id_down, owner_id, seat_capacity
         `
 	createdBus := domain.BusCredential{}
 	err := r.dbCon.Get(
@@ -99,6 +92,7 @@ func (r *busRepo) Create(busCred domain.BusCredential) (*domain.BusCredential, e
 		busCred.RouteIdUp,
 		busCred.RouteIdDown,
 		busCred.OwnerId,
+		busCred.SeatCapacity,
 	)
 	if err != nil {
 		return nil, err
@@ -110,3 +104,70 @@ func (r *busRepo) CreateCredential(busCred domain.BusCredential) error {
 	_, err := r.Create(busCred)
 	return err
 }
+
+// FindBusByLocation picks, on every route, the boarding stop near the origin
+// and the alighting stop further along near the destination with the least
+// combined walk. A point inside a stop's area counts as no walk.
+func (r *busRepo) FindBusByLocation(originLat, originLon, destLat, destLon, radius float64) ([]domain.Bus, error) {
+	buses := []domain.Bus{}
+	query := `
+		WITH o AS (
+			SELECT s.route_id, s.name, s.stop_order, s.geom,
+				CASE WHEN s.area_geom IS NOT NULL AND ST_Covers(s.area_geom, p.pt) THEN 0
+					ELSE ST_Distance(s.geom::geography, p.pt::geography)
+				END AS walk
+			FROM stops s, (SELECT ST_SetSRID(ST_MakePoint($2, $1), 4326) AS pt) p
+			WHERE ST_DWithin(s.geom::geography, p.pt::geography, $5)
+				OR (s.area_geom IS NOT NULL AND ST_Covers(s.area_geom, p.pt))
+		), d AS (
+			SELECT s.route_id, s.name, s.stop_order, s.geom,
+				CASE WHEN s.area_geom IS NOT NULL AND ST_Covers(s.area_geom, p.pt) THEN 0
+					ELSE ST_Distance(s.geom::geography, p.pt::geography)
+				END AS walk
+			FROM stops s, (SELECT ST_SetSRID(ST_MakePoint($4, $3), 4326) AS pt) p
+			WHERE ST_DWithin(s.geom::geography, p.pt::geography, $5)
+				OR (s.area_geom IS NOT NULL AND ST_Covers(s.area_geom, p.pt))
+		)
+		SELECT * FROM (
+			SELECT DISTINCT ON (r.id)
+				r.id,
+				r.name,
+				ST_AsGeoJSON(
+					ST_LineSubstring(
+						r.geom,
+						ST_LineLocatePoint(r.geom, o.geom),
+						ST_LineLocatePoint(r.geom, d.geom)
+					)
+				) as linestring_geojson,
+				o.name AS boarding_stop,
+				d.name AS alighting_stop,
+				o.walk + d.walk AS walk_distance
+			FROM routes r
+			JOIN o ON o.route_id = r.id
+			JOIN d ON d.route_id = r.id AND o.stop_order < d.stop_order
+			ORDER BY r.id, o.walk + d.walk, d.stop_order - o.stop_order
+		) best
+		ORDER BY walk_distance
+	`
+	err := r.dbCon.Select(&buses, query, originLat, originLon, destLat, destLon, radius)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range buses {
+		var stops []domain.Stop
+		stopQuery := `
+			SELECT id, route_id, stop_order, name, ST_X(geom::geometry) as lon, ST_Y(geom::geometry) as lat 
+			FROM stops 
+			WHERE route_id = $1 AND (name = $2 OR name = $3)
+			ORDER BY stop_order
+		`
+		err = r.dbCon.Select(&stops, stopQuery, buses[i].Id, buses[i].BoardingStop, buses[i].AlightingStop)
+		if err != nil {
+			return nil, err
+		}
+		buses[i].Stops = stops
+	}
+
+	return buses, nil
+}
@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"swift_transit/alert"
+	"swift_transit/domain"
+	"swift_transit/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type BusAlertRepo interface {
+	alert.AlertRepo
+}
+
+type busAlertRepo struct {
+	dbCon       *sqlx.DB
+	utilHandler *utils.Handler
+}
+
+func NewBusAlertRepo(dbcon *sqlx.DB, utilHandler *utils.Handler) BusAlertRepo {
+	return &busAlertRepo{
+		dbCon:       dbcon,
+		utilHandler: utilHandler,
+	}
+}
+
+// DistanceFromRoute returns 0 for routes without geometry, so their buses are
+// never flagged off-route.
+func (r *busAlertRepo) DistanceFromRoute(routeId int64, lat, lon float64) (float64, error) {
+	var distance float64
+	query := `
+		SELECT COALESCE(ST_Distance(geom::geography, ST_SetSRID(ST_MakePoint($3, $2), 4326)::geography), 0)
+		FROM routes
+		WHERE id = $1
+	`
+	if err := r.dbCon.Get(&distance, query, routeId, lat, lon); err != nil {
+		return 0, err
+	}
+	return distance, nil
+}
+
+func (r *busAlertRepo) Create(alert domain.BusAlert) (*domain.BusAlert, error) {
+	query := `
+		INSERT INTO bus_alerts (bus_id, owner_id, route_id, type, message, latitude, longitude, speed, distance_meters, created_at)
+		SELECT b.id, b.owner_id, $2, $3, $4, $5, $6, $7, $8, $9
+		FROM bus_credentials b
+		WHERE b.id = $1
+		RETURNING id, owner_id
+	`
+	err := r.dbCon.QueryRow(query, alert.BusId, alert.RouteId, alert.Type, alert.Message, alert.Latitude, alert.Longitude,
+		alert.Speed, alert.DistanceMeters, alert.CreatedAt).Scan(&alert.Id, &alert.OwnerId)
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+const busAlertSelect = `
+	SELECT a.id, a.bus_id, b.registration_number, a.owner_id, a.route_id, a.type, a.message,
+		a.latitude, a.longitude, a.speed, a.distance_meters, a.created_at
+	FROM bus_alerts a
+	JOIN bus_credentials b ON b.id = a.bus_id
+`
+
+func (r *busAlertRepo) FindByOwner(ownerId int64, limit, offset int) ([]domain.BusAlert, int, error) {
+	var total int
+	if err := r.dbCon.Get(&total, `SELECT COUNT(*) FROM bus_alerts WHERE owner_id = $1`, ownerId); err != nil {
+		return nil, 0, err
+	}
+
+	alerts := []domain.BusAlert{}
+	query := busAlertSelect + ` WHERE a.owner_id = $1 ORDER BY a.created_at DESC LIMIT $2 OFFSET $3`
+	if err := r.dbCon.Select(&alerts, query, ownerId, limit, offset); err != nil {
+		return nil, 0, err
+	}
+	return alerts, total, nil
+}
+
+func (r *busAlertRepo) FindAll(limit, offset int) ([]domain.BusAlert, int, error) {
+	var total int
+	if err := r.dbCon.Get(&total, `SELECT COUNT(*) FROM bus_alerts`); err != nil {
+		return nil, 0, err
+	}
+
+	alerts := []domain.BusAlert{}
+	query := busAlertSelect + ` ORDER BY a.created_at DESC LIMIT $1 OFFSET $2`
+	if err := r.dbCon.Select(&alerts, query, limit, offset); err != nil {
+		return nil, 0, err
+	}
+	return alerts, total, nil
+}
@@ -61,7 +61,7 @@ func (r *busOwnerRepo) CountBusesByRoute(ownerId int64, routeId int64) (int, err
 }
 
 func (r *busOwnerRepo) GetBusesByOwner(ownerId int64) ([]domain.BusCredential, error) {
-	query := `SELECT id, registration_number, password, route_id_up, route_id_down, owner_id FROM bus_credentials WHERE owner_id = $1`
+	query := `SELECT id, registration_number, password, route_id_up, route_id_down, owner_id, seat_capacity FROM bus_credentials WHERE owner_id = $1`
 	rows, err := r.db.Query(query, ownerId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get buses: %w", err)
@@ -71,7 +71,7 @@ func (r *busOwnerRepo) GetBusesByOwner(ownerId int64) ([]domain.BusCredential, e
 	var buses []domain.BusCredential
 	for rows.Next() {
 		var bus domain.BusCredential
-		if err := rows.Scan(&bus.Id, &bus.RegistrationNumber, &bus.Password, &bus.RouteIdUp, &bus.RouteIdDown, &bus.OwnerId); err != nil {
+		if err := rows.Scan(&bus.Id, &bus.RegistrationNumber, &bus.Password, &bus.RouteIdUp, &bus.RouteIdDown, &bus.OwnerId, &bus.SeatCapacity); err != nil {
 			return nil, err
 		}
 		buses = append(buses, bus)
@@ -84,6 +84,7 @@ func (r *busOwnerRepo) GetAnalytics(ownerId int64) (*domain.BusOwnerAnalytics, e
 		SELECT 
 			COALESCE(SUM(t.fare), 0) as total_revenue,
 			COUNT(t.id) as total_tickets,
+			COALESCE(SUM(t.discount), 0) as concession_discount,
 			COALESCE(SUM(CASE WHEN t.created_at >= CURRENT_DATE THEN t.fare ELSE 0 END), 0) as today_revenue,
 			COUNT(CASE WHEN t.created_at >= CURRENT_DATE THEN 1 END) as today_tickets,
 			COALESCE(SUM(CASE WHEN t.created_at >= DATE_TRUNC('week', CURRENT_DATE) THEN t.fare ELSE 0 END), 0) as weekly_revenue,
@@ -99,6 +100,7 @@ func (r *busOwnerRepo) GetAnalytics(ownerId int64) (*domain.BusOwnerAnalytics, e
 	err := r.db.QueryRow(query, ownerId).Scan(
 		&analytics.TotalRevenue,
 		&analytics.TotalTickets,
+		&analytics.ConcessionDiscount,
 		&analytics.Today.Revenue,
 		&analytics.Today.Tickets,
 		&analytics.Weekly.Revenue,
@@ -110,19 +112,46 @@ func (r *busOwnerRepo) GetAnalytics(ownerId int64) (*domain.BusOwnerAnalytics, e
 		return nil, fmt.Errorf("failed to get analytics: %w", err)
 	}
 
+	passQuery := `
+		SELECT COUNT(u.id), COALESCE(SUM(u.attributed), 0)
+		FROM (` + passUsageAttribution + `) u
+		JOIN bus_credentials b ON u.registration_number = b.registration_number
+		WHERE b.owner_id = $1
+	`
+	if err := r.db.QueryRow(passQuery, ownerId).Scan(&analytics.PassBoardings, &analytics.PassRevenue); err != nil {
+		return nil, fmt.Errorf("failed to get pass analytics: %w", err)
+	}
+
 	return &analytics, nil
 }
 
+// passUsageAttribution splits each pass's price evenly across the boardings
+// made with it, so every bus that carried the rider gets a share.
+const passUsageAttribution = `
+	SELECT pu.id, pu.registration_number,
+		p.price / COUNT(*) OVER (PARTITION BY pu.pass_id) AS attributed
+	FROM pass_usages pu
+	JOIN passes p ON p.id = pu.pass_id
+`
+
 func (r *busOwnerRepo) GetPerBusAnalytics(ownerId int64) ([]domain.BusAnalytics, error) {
 	query := `
 		SELECT 
 			b.registration_number,
 			COUNT(t.id) as tickets,
-			COALESCE(SUM(t.fare), 0) as revenue
+			COALESCE(SUM(t.fare), 0) as revenue,
+			COALESCE(SUM(t.discount), 0) as concession_discount,
+			COALESCE(pu.boardings, 0) as pass_boardings,
+			COALESCE(pu.revenue, 0) as pass_revenue
 		FROM bus_credentials b
 		LEFT JOIN tickets t ON t.registration_number = b.registration_number AND t.payment_status = 'paid'
+		LEFT JOIN (
+			SELECT u.registration_number, COUNT(*) as boardings, SUM(u.attributed) as revenue
+			FROM (` + passUsageAttribution + `) u
+			GROUP BY u.registration_number
+		) pu ON pu.registration_number = b.registration_number
 		WHERE b.owner_id = $1
-		GROUP BY b.registration_number
+		GROUP BY b.registration_number, pu.boardings, pu.revenue
 		ORDER BY revenue DESC
 	`
 
@@ -135,7 +164,7 @@ func (r *busOwnerRepo) GetPerBusAnalytics(ownerId int64) ([]domain.BusAnalytics,
 	var analytics []domain.BusAnalytics
 	for rows.Next() {
 		var busAnalytics domain.BusAnalytics
-		if err := rows.Scan(&busAnalytics.RegistrationNumber, &busAnalytics.Tickets, &busAnalytics.Revenue); err != nil {
+		if err := rows.Scan(&busAnalytics.RegistrationNumber, &busAnalytics.Tickets, &busAnalytics.Revenue, &busAnalytics.ConcessionDiscount, &busAnalytics.PassBoardings, &busAnalytics.PassRevenue); err != nil {
 			return nil, err
 		}
 		analytics = append(analytics, busAnalytics)
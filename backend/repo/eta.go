@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"swift_transit/domain"
+	"swift_transit/eta"
+	"swift_transit/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ETARepo interface {
+	eta.ETARepo
+}
+
+type etaRepo struct {
+	dbCon       *sqlx.DB
+	utilHandler *utils.Handler
+}
+
+func NewETARepo(dbcon *sqlx.DB, utilHandler *utils.Handler) ETARepo {
+	return &etaRepo{
+		dbCon:       dbcon,
+		utilHandler: utilHandler,
+	}
+}
+
+func (r *etaRepo) GetStopProgress(routeId int64) ([]domain.StopProgress, error) {
+	var stops []domain.StopProgress
+	query := `
+		SELECT
+			s.id AS stop_id,
+			s.name,
+			s.stop_order,
+			ST_LineLocatePoint(r.geom, s.geom) * ST_Length(r.geom::geography) AS distance_along
+		FROM stops s
+		JOIN routes r ON r.id = s.route_id
+		WHERE r.id = $1 AND r.geom IS NOT NULL
+		ORDER BY s.stop_order
+	`
+	if err := r.dbCon.Select(&stops, query, routeId); err != nil {
+		return nil, err
+	}
+	return stops, nil
+}
+
+func (r *etaRepo) GetBusProgress(routeId int64, lat, lon float64) (float64, error) {
+	var along float64
+	query := `
+		SELECT ST_LineLocatePoint(geom, ST_SetSRID(ST_MakePoint($3, $2), 4326)) * ST_Length(geom::geography)
+		FROM routes
+		WHERE id = $1 AND geom IS NOT NULL
+	`
+	if err := r.dbCon.Get(&along, query, routeId, lat, lon); err != nil {
+		return 0, err
+	}
+	return along, nil
+}
+
+// GetSegmentTimes averages how long buses took from leaving a stop to
+// arriving at the next one, keyed by the order of the stop they left.
+func (r *etaRepo) GetSegmentTimes(routeId int64, since time.Time) (map[int]float64, error) {
+	query := `
+		SELECT d.stop_order, AVG(EXTRACT(EPOCH FROM (a.occurred_at - d.occurred_at)))::float8 AS seconds
+		FROM stop_events d
+		JOIN LATERAL (
+			SELECT occurred_at, stop_order
+			FROM stop_events
+			WHERE bus_id = d.bus_id AND route_id = d.route_id AND event = 'arrived' AND occurred_at > d.occurred_at
+			ORDER BY occurred_at
+			LIMIT 1
+		) a ON a.stop_order = d.stop_order + 1
+		WHERE d.route_id = $1 AND d.event = 'departed' AND d.occurred_at >= $2
+			AND a.occurred_at - d.occurred_at < INTERVAL '2 hours'
+		GROUP BY d.stop_order
+	`
+	rows, err := r.dbCon.Query(query, routeId, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	times := make(map[int]float64)
+	for rows.Next() {
+		var order int
+		var seconds float64
+		if err := rows.Scan(&order, &seconds); err != nil {
+			return nil, err
+		}
+		times[order] = seconds
+	}
+	return times, rows.Err()
+}
@@ -0,0 +1,214 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"swift_transit/domain"
+	"swift_transit/fare"
+	"swift_transit/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type FareRepo interface {
+	fare.FareRepo
+}
+
+type fareRepo struct {
+	dbCon       *sqlx.DB
+	utilHandler *utils.Handler
+}
+
+func NewFareRepo(dbcon *sqlx.DB, utilHandler *utils.Handler) FareRepo {
+	return &fareRepo{
+		dbCon:       dbcon,
+		utilHandler: utilHandler,
+	}
+}
+
+func (r *fareRepo) GetPolicy(routeId int64) (*domain.FarePolicy, error) {
+	var policy domain.FarePolicy
+	query := `
+		SELECT route_id, base_fare, per_km_rate, min_fare, max_fare, rounding_mode, rounding_step
+		FROM fare_policies
+		WHERE route_id = $1
+	`
+	err := r.dbCon.Get(&policy, query, routeId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var table []domain.FareTableEntry
+	tableQuery := `SELECT route_id, from_stop, to_stop, fare FROM fare_tables WHERE route_id = $1 ORDER BY id`
+	if err := r.dbCon.Select(&table, tableQuery, routeId); err != nil {
+		return nil, err
+	}
+	policy.FareTable = table
+
+	return &policy, nil
+}
+
+func (r *fareRepo) UpsertPolicy(policy domain.FarePolicy) error {
+	tx, err := r.dbCon.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO fare_policies (route_id, base_fare, per_km_rate, min_fare, max_fare, rounding_mode, rounding_step, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (route_id) DO UPDATE SET
+			base_fare = EXCLUDED.base_fare,
+			per_km_rate = EXCLUDED.per_km_rate,
+			min_fare = EXCLUDED.min_fare,
+			max_fare = EXCLUDED.max_fare,
+			rounding_mode = EXCLUDED.rounding_mode,
+			rounding_step = EXCLUDED.rounding_step,
+			updated_at = NOW()
+	`
+	_, err = tx.Exec(query, policy.RouteId, policy.BaseFare, policy.PerKmRate, policy.MinFare, policy.MaxFare, policy.RoundingMode, policy.RoundingStep)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM fare_tables WHERE route_id = $1`, policy.RouteId); err != nil {
+		return err
+	}
+
+	for _, entry := range policy.FareTable {
+		var known int
+		err := tx.Get(&known, `SELECT COUNT(DISTINCT name) FROM stops WHERE route_id = $1 AND name IN ($2, $3)`, policy.RouteId, entry.FromStop, entry.ToStop)
+		if err != nil {
+			return err
+		}
+		if known != 2 {
+			return fmt.Errorf("unknown stop in fare table: %s -> %s", entry.FromStop, entry.ToStop)
+		}
+
+		_, err = tx.Exec(`INSERT INTO fare_tables (route_id, from_stop, to_stop, fare) VALUES ($1, $2, $3, $4)`, policy.RouteId, entry.FromStop, entry.ToStop, entry.Fare)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetTripDistance measures the trip on the route version applied last
+// before at, falling back to the route as it is now when no version has both
+// stops.
+func (r *fareRepo) GetTripDistance(routeId int64, start, end string, at time.Time) (float64, error) {
+	var distance float64
+	versionQuery := `
+		WITH v AS (
+			SELECT id, geom
+			FROM route_versions
+			WHERE route_id = $1 AND applied_at <= $4
+			ORDER BY applied_at DESC, version DESC
+			LIMIT 1
+		)
+		SELECT
+			ST_Length(
+				ST_LineSubstring(
+					v.geom,
+					LEAST(ST_LineLocatePoint(v.geom, s1.geom), ST_LineLocatePoint(v.geom, s2.geom)),
+					GREATEST(ST_LineLocatePoint(v.geom, s1.geom), ST_LineLocatePoint(v.geom, s2.geom))
+				)::geography
+			) / 1000 as distance_km
+		FROM v
+		JOIN route_version_stops s1 ON s1.version_id = v.id AND s1.name = $2
+		JOIN route_version_stops s2 ON s2.version_id = v.id AND s2.name = $3
+	`
+	err := r.dbCon.Get(&distance, versionQuery, routeId, start, end, at)
+	if err == nil {
+		return distance, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	query := `
+		SELECT 
+			ST_Length(
+				ST_LineSubstring(
+					r.geom, 
+					LEAST(ST_LineLocatePoint(r.geom, s1.geom), ST_LineLocatePoint(r.geom, s2.geom)), 
+					GREATEST(ST_LineLocatePoint(r.geom, s1.geom), ST_LineLocatePoint(r.geom, s2.geom))
+				)::geography
+			) / 1000 as distance_km
+		FROM routes r
+		JOIN stops s1 ON r.id = s1.route_id
+		JOIN stops s2 ON r.id = s2.route_id
+		WHERE r.id = $1 AND s1.name = $2 AND s2.name = $3
+	`
+	err = r.dbCon.Get(&distance, query, routeId, start, end)
+	if err != nil {
+		return 0, err
+	}
+	return distance, nil
+}
+
+func (r *fareRepo) GetConcessions() ([]domain.ConcessionRule, error) {
+	query := `SELECT id, name, discount_percent, valid_from, valid_until, route_ids, active FROM concession_rules ORDER BY id`
+	rows, err := r.dbCon.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []domain.ConcessionRule{}
+	for rows.Next() {
+		var rule domain.ConcessionRule
+		var routeIds pq.Int64Array
+		if err := rows.Scan(&rule.Id, &rule.Name, &rule.DiscountPercent, &rule.ValidFrom, &rule.ValidUntil, &routeIds, &rule.Active); err != nil {
+			return nil, err
+		}
+		rule.RouteIds = routeIds
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *fareRepo) CreateConcession(rule domain.ConcessionRule) (*domain.ConcessionRule, error) {
+	query := `
+		INSERT INTO concession_rules (name, discount_percent, valid_from, valid_until, route_ids, active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	err := r.dbCon.QueryRow(query, rule.Name, rule.DiscountPercent, rule.ValidFrom, rule.ValidUntil, pq.Array(rule.RouteIds), rule.Active).Scan(&rule.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *fareRepo) UpdateConcession(rule domain.ConcessionRule) error {
+	query := `
+		UPDATE concession_rules
+		SET name = $1, discount_percent = $2, valid_from = $3, valid_until = $4, route_ids = $5, active = $6
+		WHERE id = $7
+	`
+	res, err := r.dbCon.Exec(query, rule.Name, rule.DiscountPercent, rule.ValidFrom, rule.ValidUntil, pq.Array(rule.RouteIds), rule.Active, rule.Id)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("concession rule not found")
+	}
+	return nil
+}
+
+func (r *fareRepo) DeleteConcession(id int64) error {
+	_, err := r.dbCon.Exec(`DELETE FROM concession_rules WHERE id = $1`, id)
+	return err
+}
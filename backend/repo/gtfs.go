@@ -0,0 +1,48 @@
+package repo
+
+import (
+	"database/sql"
+	"swift_transit/domain"
+)
+
+func (r *routeRepo) FindGTFSRoute(gtfsRouteId string, direction int) (int64, error) {
+	var routeID int64
+	err := r.dbCon.Get(&routeID, `SELECT id FROM routes WHERE gtfs_route_id = $1 AND gtfs_direction = $2`, gtfsRouteId, direction)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return routeID, nil
+}
+
+// CreateGTFSRoute creates the route imported from a GTFS route and direction.
+// Later imports of it are saved as route versions.
+func (r *routeRepo) CreateGTFSRoute(gtfsRouteId string, direction int, route domain.Route) (*domain.Route, error) {
+	tx, err := r.dbCon.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO routes (name, geom, gtfs_route_id, gtfs_direction)
+		VALUES ($1, ST_Force2D(ST_GeomFromGeoJSON($2)), $3, $4)
+		RETURNING id
+	`
+	if err := tx.QueryRowx(query, route.Name, route.LineStringGeoJSON, gtfsRouteId, direction).Scan(&route.Id); err != nil {
+		return nil, err
+	}
+	if err := insertStops(tx, route.Id, route.Stops); err != nil {
+		return nil, err
+	}
+	if err := snapshotVersion(tx, route.Id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
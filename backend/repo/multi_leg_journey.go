@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+
+	"swift_transit/domain"
+)
+
+// CreateMultiLegJourney writes the journey and a ticket for each of its legs
+// in one transaction, so a journey is never left with only some of its legs.
+func (r *ticketRepo) CreateMultiLegJourney(journey domain.MultiLegJourney) (*domain.MultiLegJourney, error) {
+	tx, err := r.dbCon.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var created domain.MultiLegJourney
+	query := `
+		INSERT INTO multi_leg_journeys (user_id, total_fare, transfer_discount_percent, transfer_window_minutes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, user_id, total_fare, transfer_discount_percent, transfer_window_minutes, created_at
+	`
+	err = tx.Get(&created, query, journey.UserId, journey.TotalFare, journey.TransferDiscountPercent, journey.TransferWindowMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	created.Legs = make([]domain.Ticket, len(journey.Legs))
+	for i, leg := range journey.Legs {
+		leg.JourneyId = &created.Id
+		ticket, err := insertTicket(tx, leg)
+		if err != nil {
+			return nil, fmt.Errorf("leg %d: %w", i+1, err)
+		}
+		created.Legs[i] = *ticket
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (r *ticketRepo) GetMultiLegJourney(id int64) (*domain.MultiLegJourney, error) {
+	var journey domain.MultiLegJourney
+	query := `
+		SELECT id, user_id, total_fare, transfer_discount_percent, transfer_window_minutes, created_at
+		FROM multi_leg_journeys WHERE id = $1
+	`
+	if err := r.dbCon.Get(&journey, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("journey not found")
+		}
+		return nil, err
+	}
+
+	journey.Legs = []domain.Ticket{}
+	if err := r.dbCon.Select(&journey.Legs, `SELECT * FROM tickets WHERE journey_id = $1 ORDER BY leg_index`, id); err != nil {
+		return nil, err
+	}
+	return &journey, nil
+}
+
+// PreviousLegStatus reports whether the leg before legIndex has been checked
+// and whether the journey's transfer window since that check is still open.
+// Both timestamps come from the database clock.
+func (r *ticketRepo) PreviousLegStatus(journeyId int64, legIndex int) (bool, bool, error) {
+	var status struct {
+		Checked bool `db:"checked"`
+		Open    bool `db:"open"`
+	}
+	query := `
+		SELECT
+			t.checked AND t.checked_at IS NOT NULL AS checked,
+			COALESCE(LOCALTIMESTAMP <= t.checked_at + make_interval(mins => j.transfer_window_minutes), FALSE) AS open
+		FROM tickets t
+		JOIN multi_leg_journeys j ON j.id = t.journey_id
+		WHERE t.journey_id = $1 AND t.leg_index = $2
+	`
+	if err := r.dbCon.Get(&status, query, journeyId, legIndex-1); err != nil {
+		return false, false, err
+	}
+	return status.Checked, status.Open, nil
+}
+
+func (r *ticketRepo) GetBatchTotal(batchID string) (float64, error) {
+	var total float64
+	err := r.dbCon.Get(&total, `SELECT COALESCE(SUM(fare), 0) FROM tickets WHERE batch_id = $1`, batchID)
+	return total, err
+}
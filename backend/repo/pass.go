@@ -0,0 +1,213 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"swift_transit/domain"
+	"swift_transit/pass"
+	"swift_transit/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PassRepo interface {
+	pass.PassRepo
+}
+
+type passRepo struct {
+	dbCon       *sqlx.DB
+	utilHandler *utils.Handler
+}
+
+func NewPassRepo(dbcon *sqlx.DB, utilHandler *utils.Handler) PassRepo {
+	return &passRepo{
+		dbCon:       dbcon,
+		utilHandler: utilHandler,
+	}
+}
+
+const passSelect = `
+	SELECT p.id, p.user_id, p.plan_id, pp.name AS plan_name, pp.period, p.route_id, p.price, p.qr_code,
+		p.payment_method, p.payment_status, p.tran_id, p.valid_from, p.valid_until, p.created_at
+	FROM passes p
+	JOIN pass_plans pp ON pp.id = p.plan_id
+`
+
+func (r *passRepo) GetPlans(activeOnly bool) ([]domain.PassPlan, error) {
+	query := `SELECT id, name, period, route_id, price, active FROM pass_plans`
+	if activeOnly {
+		query += ` WHERE active = TRUE`
+	}
+	query += ` ORDER BY price ASC`
+
+	plans := []domain.PassPlan{}
+	if err := r.dbCon.Select(&plans, query); err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+func (r *passRepo) GetPlan(id int64) (*domain.PassPlan, error) {
+	var plan domain.PassPlan
+	err := r.dbCon.Get(&plan, `SELECT id, name, period, route_id, price, active FROM pass_plans WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("pass plan not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *passRepo) CreatePlan(plan domain.PassPlan) (*domain.PassPlan, error) {
+	query := `
+		INSERT INTO pass_plans (name, period, route_id, price, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, name, period, route_id, price, active
+	`
+	var created domain.PassPlan
+	if err := r.dbCon.Get(&created, query, plan.Name, plan.Period, plan.RouteId, plan.Price, plan.Active); err != nil {
+		return nil, fmt.Errorf("failed to create pass plan: %w", err)
+	}
+	return &created, nil
+}
+
+func (r *passRepo) UpdatePlan(plan domain.PassPlan) error {
+	res, err := r.dbCon.Exec(`
+		UPDATE pass_plans SET name = $1, period = $2, route_id = $3, price = $4, active = $5
+		WHERE id = $6
+	`, plan.Name, plan.Period, plan.RouteId, plan.Price, plan.Active, plan.Id)
+	if err != nil {
+		return fmt.Errorf("failed to update pass plan: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return fmt.Errorf("pass plan not found")
+	}
+	return nil
+}
+
+// Plans that were already sold are deactivated instead of deleted so existing
+// passes keep their plan.
+func (r *passRepo) DeletePlan(id int64) error {
+	var sold bool
+	if err := r.dbCon.Get(&sold, `SELECT EXISTS(SELECT 1 FROM passes WHERE plan_id = $1)`, id); err != nil {
+		return err
+	}
+	if sold {
+		_, err := r.dbCon.Exec(`UPDATE pass_plans SET active = FALSE WHERE id = $1`, id)
+		return err
+	}
+	_, err := r.dbCon.Exec(`DELETE FROM pass_plans WHERE id = $1`, id)
+	return err
+}
+
+func (r *passRepo) Create(p domain.Pass) (*domain.Pass, error) {
+	query := `
+		INSERT INTO passes (user_id, plan_id, route_id, price, qr_code, payment_method, payment_status, tran_id, valid_from, valid_until)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+	var id int64
+	err := r.dbCon.Get(&id, query, p.UserId, p.PlanId, p.RouteId, p.Price, p.QRCode, p.PaymentMethod, p.PaymentStatus, p.TranId, p.ValidFrom, p.ValidUntil)
+	if err != nil {
+		return nil, err
+	}
+	return r.get(`WHERE p.id = $1`, id)
+}
+
+func (r *passRepo) GetByTranID(tranID string) (*domain.Pass, error) {
+	return r.get(`WHERE p.tran_id = $1`, tranID)
+}
+
+func (r *passRepo) GetByQRCode(qrCode string) (*domain.Pass, error) {
+	return r.get(`WHERE p.qr_code = $1`, qrCode)
+}
+
+func (r *passRepo) get(where string, arg interface{}) (*domain.Pass, error) {
+	var p domain.Pass
+	err := r.dbCon.Get(&p, passSelect+where, arg)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("pass not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Activate marks a pending pass paid. It reports false when the pass was no
+// longer pending, e.g. because another payment callback got there first.
+func (r *passRepo) Activate(id int64, validFrom, validUntil time.Time) (bool, error) {
+	res, err := r.dbCon.Exec(`
+		UPDATE passes SET payment_status = 'paid', valid_from = $1, valid_until = $2
+		WHERE id = $3 AND payment_status = 'pending'
+	`, validFrom, validUntil, id)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *passRepo) UpdatePaymentStatus(id int64, status string) error {
+	_, err := r.dbCon.Exec(`UPDATE passes SET payment_status = $1 WHERE id = $2`, status, id)
+	return err
+}
+
+func (r *passRepo) GetByUserID(userId int64) ([]domain.Pass, error) {
+	passes := []domain.Pass{}
+	query := passSelect + ` WHERE p.user_id = $1 AND p.payment_status = 'paid' ORDER BY p.valid_until DESC`
+	if err := r.dbCon.Select(&passes, query, userId); err != nil {
+		return nil, err
+	}
+	return passes, nil
+}
+
+// FindActive prefers a route-scoped pass over a network-wide one.
+func (r *passRepo) FindActive(userId, routeId int64, at time.Time) (*domain.Pass, error) {
+	var p domain.Pass
+	query := passSelect + `
+		WHERE p.user_id = $1
+			AND p.payment_status = 'paid'
+			AND (p.route_id IS NULL OR p.route_id = $2)
+			AND p.valid_from <= $3 AND p.valid_until > $3
+		ORDER BY p.route_id IS NULL, p.valid_until
+		LIMIT 1
+	`
+	err := r.dbCon.Get(&p, query, userId, routeId, at)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *passRepo) CreateUsage(usage domain.PassUsage) error {
+	_, err := r.dbCon.Exec(`
+		INSERT INTO pass_usages (pass_id, user_id, route_id, registration_number, stop, source)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, usage.PassId, usage.UserId, usage.RouteId, usage.RegistrationNumber, usage.Stop, usage.Source)
+	return err
+}
+
+func (r *passRepo) GetLatestUsage(passId int64) (*domain.PassUsage, error) {
+	var usage domain.PassUsage
+	err := r.dbCon.Get(&usage, `
+		SELECT id, pass_id, user_id, route_id, registration_number, stop, source, created_at
+		FROM pass_usages WHERE pass_id = $1
+		ORDER BY created_at DESC LIMIT 1
+	`, passId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
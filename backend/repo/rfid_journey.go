@@ -0,0 +1,111 @@
+package repo
+
+import (
+	"database/sql"
+	"time"
+
+	"swift_transit/domain"
+)
+
+// GetRouteTermini returns the first and last stop names of a route.
+func (r *ticketRepo) GetRouteTermini(routeId int64) (string, string, error) {
+	query := `
+		SELECT
+			(SELECT name FROM stops WHERE route_id = $1 ORDER BY stop_order ASC LIMIT 1),
+			(SELECT name FROM stops WHERE route_id = $1 ORDER BY stop_order DESC LIMIT 1)
+	`
+	var first, last sql.NullString
+	if err := r.dbCon.QueryRow(query, routeId).Scan(&first, &last); err != nil {
+		return "", "", err
+	}
+	if !first.Valid || !last.Valid {
+		return "", "", sql.ErrNoRows
+	}
+	return first.String, last.String, nil
+}
+
+func (r *ticketRepo) CreateJourney(journey domain.RFIDJourney) (*domain.RFIDJourney, error) {
+	var created domain.RFIDJourney
+	query := `
+		INSERT INTO rfid_journeys (user_id, route_id, bus_name, start_stop, hold_amount, hold_discount, status, tapped_on_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING *
+	`
+	err := r.dbCon.Get(&created, query, journey.UserId, journey.RouteId, journey.BusName, journey.StartStop, journey.HoldAmount, journey.HoldDiscount, domain.JourneyOpen, journey.TappedOnAt)
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (r *ticketRepo) GetOpenJourney(userId int64) (*domain.RFIDJourney, error) {
+	var journey domain.RFIDJourney
+	err := r.dbCon.Get(&journey, `SELECT * FROM rfid_journeys WHERE user_id = $1 AND status = 'OPEN'`, userId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &journey, nil
+}
+
+// SettleJourney closes the open journey and charges it in one transaction:
+// the wallet gets back what the hold exceeds the ticket's fare by, or pays
+// the difference when the fare is higher and the balance covers it; if not,
+// the fare stops at the hold. The ticket is written with the fare charged.
+// It returns nil if the journey was already closed, so only one caller
+// settles it.
+func (r *ticketRepo) SettleJourney(j domain.RFIDJourney, status string, endStop *string, closedAt time.Time, t domain.Ticket) (*domain.Ticket, float64, error) {
+	tx, err := r.dbCon.Beginx()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE rfid_journeys SET status = $2, end_stop = $3, tapped_off_at = $4
+		WHERE id = $1 AND status = 'OPEN'
+	`, j.Id, status, endStop, closedAt)
+	if err != nil {
+		return nil, 0, err
+	}
+	if rows, err := res.RowsAffected(); err != nil || rows != 1 {
+		return nil, 0, err
+	}
+
+	var balance float64
+	if err := tx.Get(&balance, `SELECT balance FROM users WHERE id = $1 FOR UPDATE`, j.UserId); err != nil {
+		return nil, 0, err
+	}
+	if extra := t.Fare - j.HoldAmount; extra > 0 && balance < extra {
+		t.Fare = j.HoldAmount
+	}
+	refund := j.HoldAmount - t.Fare
+	if refund != 0 {
+		if _, err := tx.Exec(`UPDATE users SET balance = balance + $1 WHERE id = $2`, refund, j.UserId); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	ticket, err := insertTicket(tx, t)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := tx.Exec(`UPDATE rfid_journeys SET fare = $2, ticket_id = $3 WHERE id = $1`, j.Id, ticket.Fare, ticket.Id); err != nil {
+		return nil, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, 0, err
+	}
+	if refund < 0 {
+		refund = 0
+	}
+	return ticket, refund, nil
+}
+
+func (r *ticketRepo) GetStaleJourneys(before time.Time) ([]domain.RFIDJourney, error) {
+	journeys := []domain.RFIDJourney{}
+	err := r.dbCon.Select(&journeys, `SELECT * FROM rfid_journeys WHERE status = 'OPEN' AND tapped_on_at < $1`, before)
+	return journeys, err
+}
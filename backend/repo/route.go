@@ -16,6 +16,9 @@ type RouteRepo interface {
 	FindRoute(start, end string) (*domain.Route, error)
 	SearchByName(query string) ([]domain.Route, error)
 	SearchStops(query string) ([]string, error)
+	FindGTFSRoute(gtfsRouteId string, direction int) (int64, error)
+	CreateGTFSRoute(gtfsRouteId string, direction int, route domain.Route) (*domain.Route, error)
+	LinkStations() error
 }
 
 type routeRepo struct {
@@ -45,62 +48,99 @@ func (r *routeRepo) Create(route domain.Route) (*domain.Route, error) {
 	}
 	route.Id = routeID
 
-	if len(route.Stops) > 0 {
-		var (
-			names  []string
-			orders []int
-			lons   []float64
-			lats   []float64
-			areas  []string
-		)
-
-		for _, stop := range route.Stops {
-			names = append(names, stop.Name)
-			orders = append(orders, stop.Order)
-			lons = append(lons, stop.Lon)
-			lats = append(lats, stop.Lat)
-
-			var areaJSON string
-			if stop.AreaGeom != nil {
-				b, _ := json.Marshal(stop.AreaGeom)
-				areaJSON = string(b)
-			}
-			areas = append(areas, areaJSON)
+	if err := insertStops(tx, routeID, route.Stops); err != nil {
+		return nil, err
+	}
+	if err := snapshotVersion(tx, routeID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &route, nil
+}
+
+// insertStops adds stops to a route in order, links them to their stations
+// and fills in both ids.
+func insertStops(tx *sqlx.Tx, routeID int64, stops []domain.Stop) error {
+	if len(stops) == 0 {
+		return nil
+	}
+
+	var (
+		names  []string
+		orders []int
+		lons   []float64
+		lats   []float64
+		areas  []string
+	)
+
+	for _, stop := range stops {
+		names = append(names, stop.Name)
+		orders = append(orders, stop.Order)
+		lons = append(lons, stop.Lon)
+		lats = append(lats, stop.Lat)
+
+		var areaJSON string
+		if stop.AreaGeom != nil {
+			b, _ := json.Marshal(stop.AreaGeom)
+			areaJSON = string(b)
 		}
+		areas = append(areas, areaJSON)
+	}
+
+	stopQuery := `
+		INSERT INTO stops (route_id, name, stop_order, geom, area_geom)
+		SELECT $1, u.name, u.stop_order, ST_SetSRID(ST_MakePoint(u.lon, u.lat), 4326), ST_SetSRID(ST_GeomFromGeoJSON(NULLIF(u.area_geom, '')), 4326)
+		FROM unnest($2::text[], $3::int[], $4::float8[], $5::float8[], $6::text[]) AS u(name, stop_order, lon, lat, area_geom)
+		RETURNING id
+	`
 
-		stopQuery := `
-			INSERT INTO stops (route_id, name, stop_order, geom, area_geom)
-			SELECT $1, u.name, u.stop_order, ST_SetSRID(ST_MakePoint(u.lon, u.lat), 4326), ST_SetSRID(ST_GeomFromGeoJSON(NULLIF(u.area_geom, '')), 4326)
-			FROM unnest($2::text[], $3::int[], $4::float8[], $5::float8[], $6::text[]) AS u(name, stop_order, lon, lat, area_geom)
-			RETURNING id
-		`
+	rows, err := tx.Queryx(stopQuery, routeID, pq.Array(names), pq.Array(orders), pq.Array(lons), pq.Array(lats), pq.Array(areas))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
 
-		rows, err := tx.Queryx(stopQuery, routeID, pq.Array(names), pq.Array(orders), pq.Array(lons), pq.Array(lats), pq.Array(areas))
-		if err != nil {
-			return nil, err
+	i := 0
+	for rows.Next() {
+		var stopID int64
+		if err := rows.Scan(&stopID); err != nil {
+			return err
 		}
-		defer rows.Close()
-
-		i := 0
-		for rows.Next() {
-			var stopID int64
-			if err := rows.Scan(&stopID); err != nil {
-				return nil, err
-			}
-			if i < len(route.Stops) {
-				route.Stops[i].Id = stopID
-				route.Stops[i].RouteId = routeID
-				i++
-			}
+		if i < len(stops) {
+			stops[i].Id = stopID
+			stops[i].RouteId = routeID
+			i++
 		}
 	}
-
-	if err := tx.Commit(); err != nil {
-		return nil, err
+	if err := rows.Err(); err != nil {
+		return err
 	}
+	rows.Close()
 
-	return &route, nil
+	if err := assignStations(tx, routeID); err != nil {
+		return err
+	}
+	var linked []struct {
+		Id        int64  `db:"id"`
+		StationId *int64 `db:"station_id"`
+	}
+	if err := tx.Select(&linked, `SELECT id, station_id FROM stops WHERE route_id = $1`, routeID); err != nil {
+		return err
+	}
+	stations := make(map[int64]*int64, len(linked))
+	for _, l := range linked {
+		stations[l.Id] = l.StationId
+	}
+	for i := range stops {
+		stops[i].StationId = stations[stops[i].Id]
+	}
+	return nil
 }
+
 func (r *routeRepo) FindAll() ([]domain.Route, error) {
 	var routes []domain.Route
 	query := `SELECT id, name, ST_AsGeoJSON(geom) as linestring_geojson FROM routes ORDER BY name`
@@ -113,7 +153,7 @@ func (r *routeRepo) FindAll() ([]domain.Route, error) {
 	// This might be heavy if there are many routes, but for now it's fine.
 	// Optimization: Fetch all stops and map them in memory.
 
-	stopQuery := `SELECT id, route_id, stop_order, name, ST_X(geom::geometry) as lon, ST_Y(geom::geometry) as lat, COALESCE(ST_AsGeoJSON(area_geom), '') as area_geom FROM stops ORDER BY route_id, stop_order`
+	stopQuery := `SELECT id, route_id, station_id, stop_order, name, ST_X(geom::geometry) as lon, ST_Y(geom::geometry) as lat, COALESCE(ST_AsGeoJSON(area_geom), '') as area_geom FROM stops ORDER BY route_id, stop_order`
 	var stops []domain.Stop
 	err = r.dbCon.Select(&stops, stopQuery)
 	if err != nil {
@@ -140,7 +180,7 @@ func (r *routeRepo) FindByID(id int64) (*domain.Route, error) {
 	}
 
 	var stops []domain.Stop
-	stopQuery := `SELECT id, route_id,stop_order,name, ST_X(geom::geometry) as lon, ST_Y(geom::geometry) as lat, COALESCE(ST_AsGeoJSON(area_geom), '') as area_geom FROM stops WHERE route_id = $1 ORDER BY stop_order`
+	stopQuery := `SELECT id, route_id, station_id, stop_order, name, ST_X(geom::geometry) as lon, ST_Y(geom::geometry) as lat, COALESCE(ST_AsGeoJSON(area_geom), '') as area_geom FROM stops WHERE route_id = $1 ORDER BY stop_order`
 
 	err = r.dbCon.Select(&stops, stopQuery, id)
 	fmt.Println(stops)
@@ -171,7 +211,7 @@ func (r *routeRepo) FindRoute(start, end string) (*domain.Route, error) {
 
 	// Fetch stops for this route
 	var stops []domain.Stop
-	stopQuery := `SELECT id, route_id, stop_order, name, ST_X(geom::geometry) as lon, ST_Y(geom::geometry) as lat, COALESCE(ST_AsGeoJSON(area_geom), '') as area_geom FROM stops WHERE route_id = $1 ORDER BY stop_order`
+	stopQuery := `SELECT id, route_id, station_id, stop_order, name, ST_X(geom::geometry) as lon, ST_Y(geom::geometry) as lat, COALESCE(ST_AsGeoJSON(area_geom), '') as area_geom FROM stops WHERE route_id = $1 ORDER BY stop_order`
 	err = r.dbCon.Select(&stops, stopQuery, route.Id)
 	if err != nil {
 		return nil, err
@@ -204,7 +244,7 @@ LIMIT 20
 	}
 
 	stopQuery := `
-SELECT id, route_id, stop_order, name, ST_X(geom::geometry) as lon, ST_Y(geom::geometry) as lat, COALESCE(ST_AsGeoJSON(area_geom), '') as area_geom
+SELECT id, route_id, station_id, stop_order, name, ST_X(geom::geometry) as lon, ST_Y(geom::geometry) as lat, COALESCE(ST_AsGeoJSON(area_geom), '') as area_geom
 FROM stops
 WHERE route_id = ANY($1)
 ORDER BY stop_order
@@ -228,7 +268,8 @@ ORDER BY stop_order
 
 func (r *routeRepo) SearchStops(query string) ([]string, error) {
 	var stops []string
-	sql := `SELECT DISTINCT name FROM stops WHERE name ILIKE $1 LIMIT 10`
+	// Each place is one station, however many routes stop there
+	sql := `SELECT DISTINCT name FROM stations WHERE name ILIKE $1 ORDER BY name LIMIT 10`
 	err := r.dbCon.Select(&stops, sql, "%"+query+"%")
 	if err != nil {
 		return nil, err
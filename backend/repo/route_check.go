@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"swift_transit/domain"
+
+	"github.com/lib/pq"
+)
+
+// MeasureLine returns the length of the line in meters and whether it is
+// simple, that is, never crosses or touches itself but at its ends.
+func (r *routeRepo) MeasureLine(line domain.LineString) (float64, bool, error) {
+	var m struct {
+		Length float64 `db:"length_meters"`
+		Simple bool    `db:"simple"`
+	}
+	query := `
+		WITH l AS (SELECT ST_SetSRID(ST_Force2D(ST_GeomFromGeoJSON($1)), 4326) AS geom)
+		SELECT ST_Length(geom::geography) AS length_meters, ST_IsSimple(geom) AS simple
+		FROM l
+	`
+	if err := r.dbCon.Get(&m, query, line); err != nil {
+		return 0, false, err
+	}
+	return m.Length, m.Simple, nil
+}
+
+// LocateStops snaps each stop to the closest point of the line, in the order
+// the stops are given.
+func (r *routeRepo) LocateStops(line domain.LineString, stops []domain.Stop) ([]domain.SnappedStop, error) {
+	lons := make([]float64, len(stops))
+	lats := make([]float64, len(stops))
+	for i, stop := range stops {
+		lons[i] = stop.Lon
+		lats[i] = stop.Lat
+	}
+
+	var located []struct {
+		Position      float64 `db:"position"`
+		DistanceAlong float64 `db:"distance_along"`
+		Offset        float64 `db:"offset_meters"`
+		Lon           float64 `db:"lon"`
+		Lat           float64 `db:"lat"`
+	}
+	query := `
+		WITH l AS (
+			SELECT ST_SetSRID(ST_Force2D(ST_GeomFromGeoJSON($1)), 4326) AS geom
+		), p AS (
+			SELECT u.i, ST_SetSRID(ST_MakePoint(u.lon, u.lat), 4326) AS pt
+			FROM unnest($2::float8[], $3::float8[]) WITH ORDINALITY AS u(lon, lat, i)
+		), s AS (
+			SELECT p.i, p.pt, l.geom, ST_LineLocatePoint(l.geom, p.pt) AS position
+			FROM p, l
+		)
+		SELECT position,
+			ST_Length(ST_LineSubstring(geom, 0, position)::geography) AS distance_along,
+			ST_Distance(pt::geography, ST_LineInterpolatePoint(geom, position)::geography) AS offset_meters,
+			ST_X(ST_LineInterpolatePoint(geom, position)) AS lon,
+			ST_Y(ST_LineInterpolatePoint(geom, position)) AS lat
+		FROM s
+		ORDER BY i
+	`
+	if err := r.dbCon.Select(&located, query, line, pq.Array(lons), pq.Array(lats)); err != nil {
+		return nil, err
+	}
+
+	snapped := make([]domain.SnappedStop, len(stops))
+	for i, stop := range stops {
+		snapped[i] = domain.SnappedStop{Stop: stop, GivenOrder: stop.Order}
+		if i < len(located) {
+			l := located[i]
+			snapped[i].Position = l.Position
+			snapped[i].DistanceAlong = l.DistanceAlong
+			snapped[i].Offset = l.Offset
+			snapped[i].Lon = l.Lon
+			snapped[i].Lat = l.Lat
+		}
+	}
+	return snapped, nil
+}
@@ -0,0 +1,290 @@
+package repo
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"swift_transit/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const routeVersionSelect = `
+	SELECT v.id, v.route_id, v.version, v.name, v.effective_from, v.applied_at, v.created_at,
+		(SELECT COUNT(*) FROM route_version_stops WHERE version_id = v.id) AS stop_count
+	FROM route_versions v
+`
+
+func (r *routeRepo) GetVersions(routeId int64) ([]domain.RouteVersion, error) {
+	versions := []domain.RouteVersion{}
+	query := routeVersionSelect + ` WHERE v.route_id = $1 ORDER BY v.version DESC`
+	if err := r.dbCon.Select(&versions, query, routeId); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetVersion returns the version with its geometry and stops, or nil.
+func (r *routeRepo) GetVersion(routeId int64, version int) (*domain.RouteVersion, error) {
+	var v domain.RouteVersion
+	query := `
+		SELECT v.id, v.route_id, v.version, v.name, ST_AsGeoJSON(v.geom) AS linestring_geojson,
+			v.effective_from, v.applied_at, v.created_at,
+			(SELECT COUNT(*) FROM route_version_stops WHERE version_id = v.id) AS stop_count
+		FROM route_versions v
+		WHERE v.route_id = $1 AND v.version = $2
+	`
+	err := r.dbCon.Get(&v, query, routeId, version)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	v.Stops = []domain.Stop{}
+	stopQuery := `
+		SELECT id, $2::int AS route_id, stop_order, name, ST_X(geom) AS lon, ST_Y(geom) AS lat,
+			COALESCE(ST_AsGeoJSON(area_geom), '') AS area_geom
+		FROM route_version_stops
+		WHERE version_id = $1
+		ORDER BY stop_order
+	`
+	if err := r.dbCon.Select(&v.Stops, stopQuery, v.Id, routeId); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *routeRepo) CreateVersion(v domain.RouteVersion) (*domain.RouteVersion, error) {
+	tx, err := r.dbCon.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Locking the route keeps concurrent edits from taking the same number
+	if _, err := tx.Exec(`SELECT id FROM routes WHERE id = $1 FOR UPDATE`, v.RouteId); err != nil {
+		return nil, err
+	}
+	query := `
+		INSERT INTO route_versions (route_id, version, name, geom, effective_from)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, ST_Force2D(ST_GeomFromGeoJSON($3)), $4
+		FROM route_versions
+		WHERE route_id = $1
+		RETURNING id, version
+	`
+	if err := tx.QueryRowx(query, v.RouteId, v.Name, v.LineStringGeoJSON, v.EffectiveFrom).Scan(&v.Id, &v.Version); err != nil {
+		return nil, err
+	}
+	if err := insertVersionStops(tx, v.Id, v.Stops); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetVersion(v.RouteId, v.Version)
+}
+
+func insertVersionStops(tx *sqlx.Tx, versionId int64, stops []domain.Stop) error {
+	var (
+		names  []string
+		orders []int
+		lons   []float64
+		lats   []float64
+		areas  []string
+	)
+	for _, stop := range stops {
+		names = append(names, stop.Name)
+		orders = append(orders, stop.Order)
+		lons = append(lons, stop.Lon)
+		lats = append(lats, stop.Lat)
+
+		var areaJSON string
+		if stop.AreaGeom != nil {
+			b, _ := json.Marshal(stop.AreaGeom)
+			areaJSON = string(b)
+		}
+		areas = append(areas, areaJSON)
+	}
+
+	query := `
+		INSERT INTO route_version_stops (version_id, name, stop_order, geom, area_geom)
+		SELECT $1, u.name, u.stop_order, ST_SetSRID(ST_MakePoint(u.lon, u.lat), 4326), ST_SetSRID(ST_GeomFromGeoJSON(NULLIF(u.area_geom, '')), 4326)
+		FROM unnest($2::text[], $3::int[], $4::float8[], $5::float8[], $6::text[]) AS u(name, stop_order, lon, lat, area_geom)
+	`
+	_, err := tx.Exec(query, versionId, pq.Array(names), pq.Array(orders), pq.Array(lons), pq.Array(lats), pq.Array(areas))
+	return err
+}
+
+// snapshotVersion records the route's live name, geometry and stops as a new
+// version already in effect, after they were written directly.
+func snapshotVersion(tx *sqlx.Tx, routeId int64) error {
+	query := `
+		INSERT INTO route_versions (route_id, version, name, geom, effective_from, applied_at)
+		SELECT r.id, COALESCE((SELECT MAX(version) FROM route_versions WHERE route_id = r.id), 0) + 1, r.name, r.geom, NOW(), NOW()
+		FROM routes r
+		WHERE r.id = $1
+		RETURNING id
+	`
+	var versionId int64
+	if err := tx.QueryRowx(query, routeId).Scan(&versionId); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+		INSERT INTO route_version_stops (version_id, name, stop_order, geom, area_geom)
+		SELECT $1, name, stop_order, geom, area_geom
+		FROM stops
+		WHERE route_id = $2
+	`, versionId, routeId)
+	return err
+}
+
+// ScheduleVersion moves a version that is not applied yet.
+func (r *routeRepo) ScheduleVersion(versionId int64, effectiveFrom time.Time) error {
+	_, err := r.dbCon.Exec(`UPDATE route_versions SET effective_from = $2 WHERE id = $1 AND applied_at IS NULL`, versionId, effectiveFrom)
+	return err
+}
+
+func (r *routeRepo) DeleteVersion(versionId int64) error {
+	_, err := r.dbCon.Exec(`DELETE FROM route_versions WHERE id = $1 AND applied_at IS NULL`, versionId)
+	return err
+}
+
+// GetDueVersions lists the versions not yet applied whose time has come, in
+// the order they take effect.
+func (r *routeRepo) GetDueVersions(at time.Time) ([]int64, error) {
+	ids := []int64{}
+	query := `SELECT id FROM route_versions WHERE applied_at IS NULL AND effective_from <= $1 ORDER BY effective_from, version`
+	if err := r.dbCon.Select(&ids, query, at); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetTimetabledStops lists the names of the route's stops that scheduled
+// trips have stop times at.
+func (r *routeRepo) GetTimetabledStops(routeId int64) ([]string, error) {
+	names := []string{}
+	query := `
+		SELECT DISTINCT s.name
+		FROM stops s
+		JOIN scheduled_stop_times st ON st.stop_id = s.id
+		WHERE s.route_id = $1
+	`
+	if err := r.dbCon.Select(&names, query, routeId); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// ApplyVersion makes the version the route's live one. Stops are matched by
+// name, in order where a name is used more than once, and updated in place,
+// so stop ids and what refers to them stay with the same place however the
+// stops are reordered. It refuses to drop a stop with timetabled stop times.
+func (r *routeRepo) ApplyVersion(versionId int64) error {
+	tx, err := r.dbCon.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var routeId int64
+	err = tx.Get(&routeId, `SELECT route_id FROM route_versions WHERE id = $1 AND applied_at IS NULL FOR UPDATE`, versionId)
+	if err == sql.ErrNoRows {
+		// Applied or deleted meanwhile
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var live []struct {
+		Id         int64  `db:"id"`
+		Name       string `db:"name"`
+		Timetabled bool   `db:"timetabled"`
+	}
+	liveQuery := `
+		SELECT s.id, s.name, EXISTS (SELECT 1 FROM scheduled_stop_times WHERE stop_id = s.id) AS timetabled
+		FROM stops s
+		WHERE s.route_id = $1
+		ORDER BY s.stop_order, s.id
+		FOR UPDATE
+	`
+	if err := tx.Select(&live, liveQuery, routeId); err != nil {
+		return err
+	}
+	var next []struct {
+		Id   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+	if err := tx.Select(&next, `SELECT id, name FROM route_version_stops WHERE version_id = $1 ORDER BY stop_order, id`, versionId); err != nil {
+		return err
+	}
+
+	byName := make(map[string][]int64)
+	for _, s := range live {
+		byName[s.Name] = append(byName[s.Name], s.Id)
+	}
+	matched := make(map[int64]int64, len(next))
+	for _, vs := range next {
+		if ids := byName[vs.Name]; len(ids) > 0 {
+			matched[vs.Id] = ids[0]
+			byName[vs.Name] = ids[1:]
+		}
+	}
+	kept := make(map[int64]bool, len(matched))
+	for _, id := range matched {
+		kept[id] = true
+	}
+	var dropped []int64
+	for _, s := range live {
+		if kept[s.Id] {
+			continue
+		}
+		if s.Timetabled {
+			return fmt.Errorf("stop %q has timetabled stop times; take it off the schedules first", s.Name)
+		}
+		dropped = append(dropped, s.Id)
+	}
+
+	if _, err := tx.Exec(`UPDATE routes r SET name = v.name, geom = v.geom FROM route_versions v WHERE v.id = $1 AND r.id = $2`, versionId, routeId); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM stops WHERE id = ANY($1)`, pq.Array(dropped)); err != nil {
+		return err
+	}
+	// A stop moved away from its station is linked to one afresh
+	update := `
+		UPDATE stops s SET stop_order = vs.stop_order, geom = vs.geom, area_geom = vs.area_geom,
+			station_id = CASE WHEN ST_DWithin(s.geom::geography, vs.geom::geography, $3) THEN s.station_id END
+		FROM route_version_stops vs
+		WHERE vs.id = $1 AND s.id = $2
+	`
+	insert := `
+		INSERT INTO stops (route_id, name, stop_order, geom, area_geom)
+		SELECT $2, name, stop_order, geom, area_geom
+		FROM route_version_stops
+		WHERE id = $1
+	`
+	for _, vs := range next {
+		if stopId, ok := matched[vs.Id]; ok {
+			_, err = tx.Exec(update, vs.Id, stopId, stationMergeMeters)
+		} else {
+			_, err = tx.Exec(insert, vs.Id, routeId)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := assignStations(tx, routeId); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE route_versions SET applied_at = NOW() WHERE id = $1`, versionId); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
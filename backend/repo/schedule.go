@@ -0,0 +1,317 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"swift_transit/domain"
+	"swift_transit/schedule"
+	"swift_transit/utils"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type ScheduleRepo interface {
+	schedule.ScheduleRepo
+}
+
+type scheduleRepo struct {
+	dbCon       *sqlx.DB
+	utilHandler *utils.Handler
+}
+
+func NewScheduleRepo(dbcon *sqlx.DB, utilHandler *utils.Handler) ScheduleRepo {
+	return &scheduleRepo{
+		dbCon:       dbcon,
+		utilHandler: utilHandler,
+	}
+}
+
+const calendarSelect = `
+	SELECT id, name, owner_id, monday, tuesday, wednesday, thursday, friday, saturday, sunday,
+		to_char(start_date, 'YYYY-MM-DD') AS start_date, to_char(end_date, 'YYYY-MM-DD') AS end_date
+	FROM service_calendars
+`
+
+func (r *scheduleRepo) GetCalendars(ownerId int64) ([]domain.ServiceCalendar, error) {
+	calendars := []domain.ServiceCalendar{}
+	query := calendarSelect + ` WHERE $1 = 0 OR owner_id IS NULL OR owner_id = $1 ORDER BY name, id`
+	if err := r.dbCon.Select(&calendars, query, ownerId); err != nil {
+		return nil, err
+	}
+	if len(calendars) == 0 {
+		return calendars, nil
+	}
+
+	ids := make([]int64, len(calendars))
+	for i, c := range calendars {
+		ids[i] = c.Id
+	}
+	exceptions, err := r.getExceptions(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range calendars {
+		calendars[i].Exceptions = exceptions[calendars[i].Id]
+	}
+	return calendars, nil
+}
+
+func (r *scheduleRepo) GetCalendar(id int64) (*domain.ServiceCalendar, error) {
+	var calendar domain.ServiceCalendar
+	err := r.dbCon.Get(&calendar, calendarSelect+` WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	exceptions, err := r.getExceptions([]int64{id})
+	if err != nil {
+		return nil, err
+	}
+	calendar.Exceptions = exceptions[id]
+	return &calendar, nil
+}
+
+// getExceptions returns the exceptions of each calendar by date, never nil.
+func (r *scheduleRepo) getExceptions(calendarIds []int64) (map[int64][]domain.CalendarException, error) {
+	var rows []domain.CalendarException
+	query := `
+		SELECT id, calendar_id, to_char(date, 'YYYY-MM-DD') AS date, type, note
+		FROM calendar_exceptions
+		WHERE calendar_id = ANY($1)
+		ORDER BY date
+	`
+	if err := r.dbCon.Select(&rows, query, pq.Array(calendarIds)); err != nil {
+		return nil, err
+	}
+	exceptions := make(map[int64][]domain.CalendarException, len(calendarIds))
+	for _, id := range calendarIds {
+		exceptions[id] = []domain.CalendarException{}
+	}
+	for _, e := range rows {
+		exceptions[e.CalendarId] = append(exceptions[e.CalendarId], e)
+	}
+	return exceptions, nil
+}
+
+func (r *scheduleRepo) CreateCalendar(c domain.ServiceCalendar) (*domain.ServiceCalendar, error) {
+	query := `
+		INSERT INTO service_calendars (name, owner_id, monday, tuesday, wednesday, thursday, friday, saturday, sunday, start_date, end_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+	var id int64
+	err := r.dbCon.QueryRow(query, c.Name, c.OwnerId, c.Monday, c.Tuesday, c.Wednesday, c.Thursday, c.Friday,
+		c.Saturday, c.Sunday, c.StartDate, c.EndDate).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetCalendar(id)
+}
+
+func (r *scheduleRepo) UpdateCalendar(c domain.ServiceCalendar) error {
+	query := `
+		UPDATE service_calendars
+		SET name = $2, monday = $3, tuesday = $4, wednesday = $5, thursday = $6, friday = $7, saturday = $8, sunday = $9,
+			start_date = $10, end_date = $11
+		WHERE id = $1
+	`
+	_, err := r.dbCon.Exec(query, c.Id, c.Name, c.Monday, c.Tuesday, c.Wednesday, c.Thursday, c.Friday,
+		c.Saturday, c.Sunday, c.StartDate, c.EndDate)
+	return err
+}
+
+func (r *scheduleRepo) DeleteCalendar(id int64) error {
+	var inUse int
+	if err := r.dbCon.Get(&inUse, `SELECT COUNT(*) FROM scheduled_trips WHERE calendar_id = $1`, id); err != nil {
+		return err
+	}
+	if inUse > 0 {
+		return fmt.Errorf("calendar is used by %d scheduled trips", inUse)
+	}
+	_, err := r.dbCon.Exec(`DELETE FROM service_calendars WHERE id = $1`, id)
+	return err
+}
+
+func (r *scheduleRepo) SetException(e domain.CalendarException) (*domain.CalendarException, error) {
+	query := `
+		INSERT INTO calendar_exceptions (calendar_id, date, type, note)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (calendar_id, date) DO UPDATE SET type = EXCLUDED.type, note = EXCLUDED.note
+		RETURNING id
+	`
+	if err := r.dbCon.QueryRow(query, e.CalendarId, e.Date, e.Type, e.Note).Scan(&e.Id); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *scheduleRepo) DeleteException(calendarId int64, date string) error {
+	res, err := r.dbCon.Exec(`DELETE FROM calendar_exceptions WHERE calendar_id = $1 AND date = $2`, calendarId, date)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("no exception on %s", date)
+	}
+	return nil
+}
+
+const scheduledTripSelect = `
+	SELECT t.id, t.route_id, t.calendar_id, c.name AS calendar_name, t.owner_id, t.headsign
+	FROM scheduled_trips t
+	JOIN service_calendars c ON c.id = t.calendar_id
+`
+
+func (r *scheduleRepo) GetTrips(ownerId, routeId int64) ([]domain.ScheduledTrip, error) {
+	trips := []domain.ScheduledTrip{}
+	query := scheduledTripSelect + ` WHERE ($1 = 0 OR t.owner_id = $1) AND ($2 = 0 OR t.route_id = $2) ORDER BY t.route_id, t.id`
+	if err := r.dbCon.Select(&trips, query, ownerId, routeId); err != nil {
+		return nil, err
+	}
+	if err := r.fillStopTimes(trips); err != nil {
+		return nil, err
+	}
+	return trips, nil
+}
+
+func (r *scheduleRepo) GetTrip(id int64) (*domain.ScheduledTrip, error) {
+	var trip domain.ScheduledTrip
+	err := r.dbCon.Get(&trip, scheduledTripSelect+` WHERE t.id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	trips := []domain.ScheduledTrip{trip}
+	if err := r.fillStopTimes(trips); err != nil {
+		return nil, err
+	}
+	return &trips[0], nil
+}
+
+func (r *scheduleRepo) fillStopTimes(trips []domain.ScheduledTrip) error {
+	if len(trips) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(trips))
+	for i, t := range trips {
+		ids[i] = t.Id
+	}
+
+	var rows []domain.ScheduledStopTime
+	query := `
+		SELECT st.scheduled_trip_id, st.stop_id, s.name AS stop_name, st.stop_order, st.arrival_seconds, st.departure_seconds
+		FROM scheduled_stop_times st
+		JOIN stops s ON s.id = st.stop_id
+		WHERE st.scheduled_trip_id = ANY($1)
+		ORDER BY st.scheduled_trip_id, st.stop_order
+	`
+	if err := r.dbCon.Select(&rows, query, pq.Array(ids)); err != nil {
+		return err
+	}
+	byTrip := make(map[int64][]domain.ScheduledStopTime, len(trips))
+	for _, st := range rows {
+		byTrip[st.ScheduledTripId] = append(byTrip[st.ScheduledTripId], st)
+	}
+	for i := range trips {
+		trips[i].StopTimes = byTrip[trips[i].Id]
+		if trips[i].StopTimes == nil {
+			trips[i].StopTimes = []domain.ScheduledStopTime{}
+		}
+	}
+	return nil
+}
+
+func (r *scheduleRepo) CreateTrip(trip domain.ScheduledTrip) (*domain.ScheduledTrip, error) {
+	tx, err := r.dbCon.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO scheduled_trips (route_id, calendar_id, owner_id, headsign)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	if err := tx.QueryRowx(query, trip.RouteId, trip.CalendarId, trip.OwnerId, trip.Headsign).Scan(&trip.Id); err != nil {
+		return nil, err
+	}
+	if err := insertStopTimes(tx, trip.Id, trip.StopTimes); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetTrip(trip.Id)
+}
+
+// UpdateTrip replaces the trip's details and all of its stop times.
+func (r *scheduleRepo) UpdateTrip(trip domain.ScheduledTrip) error {
+	tx, err := r.dbCon.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE scheduled_trips SET route_id = $2, calendar_id = $3, headsign = $4 WHERE id = $1`
+	if _, err := tx.Exec(query, trip.Id, trip.RouteId, trip.CalendarId, trip.Headsign); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM scheduled_stop_times WHERE scheduled_trip_id = $1`, trip.Id); err != nil {
+		return err
+	}
+	if err := insertStopTimes(tx, trip.Id, trip.StopTimes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertStopTimes(tx *sqlx.Tx, tripId int64, stopTimes []domain.ScheduledStopTime) error {
+	query := `
+		INSERT INTO scheduled_stop_times (scheduled_trip_id, stop_id, stop_order, arrival_seconds, departure_seconds)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, st := range stopTimes {
+		if _, err := tx.Exec(query, tripId, st.StopId, st.StopOrder, st.Arrival, st.Departure); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *scheduleRepo) DeleteTrip(id int64) error {
+	_, err := r.dbCon.Exec(`DELETE FROM scheduled_trips WHERE id = $1`, id)
+	return err
+}
+
+func (r *scheduleRepo) GetRouteStops(routeId int64) (map[int]int64, error) {
+	var rows []struct {
+		Id    int64 `db:"id"`
+		Order int   `db:"stop_order"`
+	}
+	if err := r.dbCon.Select(&rows, `SELECT id, stop_order FROM stops WHERE route_id = $1`, routeId); err != nil {
+		return nil, err
+	}
+	stops := make(map[int]int64, len(rows))
+	for _, s := range rows {
+		stops[s.Order] = s.Id
+	}
+	return stops, nil
+}
+
+func (r *scheduleRepo) OwnerServesRoute(ownerId, routeId int64) (bool, error) {
+	var serves bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM bus_credentials
+			WHERE owner_id = $1 AND (route_id_up = $2 OR route_id_down = $2)
+		)
+	`
+	err := r.dbCon.Get(&serves, query, ownerId, routeId)
+	return serves, err
+}
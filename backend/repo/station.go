@@ -0,0 +1,166 @@
+package repo
+
+import (
+	"database/sql"
+	"swift_transit/domain"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Stops of the same name closer than this are one station
+const stationMergeMeters = 150
+
+// assignStations links each stop of the route that has no station to the
+// nearest station of the same name, or to a new one where there is none, and
+// drops the stations no stop uses any more.
+func assignStations(tx *sqlx.Tx, routeID int64) error {
+	var unlinked []int64
+	if err := tx.Select(&unlinked, `SELECT id FROM stops WHERE route_id = $1 AND station_id IS NULL AND geom IS NOT NULL ORDER BY stop_order`, routeID); err != nil {
+		return err
+	}
+
+	// One stop at a time, so stops of the route sharing a place share the
+	// station made for the first of them
+	query := `
+		WITH s AS (
+			SELECT name, geom FROM stops WHERE id = $1
+		), near AS (
+			SELECT st.id
+			FROM stations st, s
+			WHERE lower(trim(st.name)) = lower(trim(s.name))
+				AND ST_DWithin(st.geom::geography, s.geom::geography, $2)
+			ORDER BY st.geom <-> s.geom
+			LIMIT 1
+		), created AS (
+			INSERT INTO stations (name, geom)
+			SELECT name, geom FROM s
+			WHERE NOT EXISTS (SELECT 1 FROM near)
+			RETURNING id
+		)
+		UPDATE stops SET station_id = COALESCE((SELECT id FROM near), (SELECT id FROM created))
+		WHERE id = $1
+	`
+	for _, id := range unlinked {
+		if _, err := tx.Exec(query, id, stationMergeMeters); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec(`DELETE FROM stations st WHERE NOT EXISTS (SELECT 1 FROM stops WHERE station_id = st.id)`)
+	return err
+}
+
+// LinkStations gives a station to every stop that has none, such as stops
+// saved before stations existed, route by route as a save would.
+func (r *routeRepo) LinkStations() error {
+	var routeIDs []int64
+	if err := r.dbCon.Select(&routeIDs, `SELECT DISTINCT route_id FROM stops WHERE station_id IS NULL AND geom IS NOT NULL ORDER BY route_id`); err != nil {
+		return err
+	}
+	for _, routeID := range routeIDs {
+		tx, err := r.dbCon.Beginx()
+		if err != nil {
+			return err
+		}
+		if err := assignStations(tx, routeID); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *routeRepo) GetStation(id int64) (*domain.Station, error) {
+	var station domain.Station
+	err := r.dbCon.Get(&station, `SELECT id, name, ST_X(geom) AS lon, ST_Y(geom) AS lat FROM stations WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stations := []domain.Station{station}
+	if err := r.fillStationStops(stations); err != nil {
+		return nil, err
+	}
+	return &stations[0], nil
+}
+
+// fillStationStops loads the route stops at each of the stations.
+func (r *routeRepo) fillStationStops(stations []domain.Station) error {
+	if len(stations) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(stations))
+	for i, st := range stations {
+		ids[i] = st.Id
+	}
+
+	var stops []domain.StationStop
+	query := `
+		SELECT s.station_id, s.id AS stop_id, s.route_id, r.name AS route_name, s.stop_order
+		FROM stops s
+		JOIN routes r ON r.id = s.route_id
+		WHERE s.station_id = ANY($1)
+		ORDER BY r.name, s.stop_order
+	`
+	if err := r.dbCon.Select(&stops, query, pq.Array(ids)); err != nil {
+		return err
+	}
+
+	byStation := make(map[int64][]domain.StationStop)
+	for _, stop := range stops {
+		byStation[stop.StationId] = append(byStation[stop.StationId], stop)
+	}
+	for i := range stations {
+		stations[i].Routes = byStation[stations[i].Id]
+		if stations[i].Routes == nil {
+			stations[i].Routes = []domain.StationStop{}
+		}
+	}
+	return nil
+}
+
+// FindNearbyStations finds the stations with a stop within radius meters of
+// the point or an area covering it, measured to the closest of their stops.
+func (r *routeRepo) FindNearbyStations(lat, lon, radius float64, limit int) ([]domain.NearbyStation, error) {
+	nearby := []domain.NearbyStation{}
+	query := `
+		WITH p AS (SELECT ST_SetSRID(ST_MakePoint($2, $1), 4326) AS pt)
+		SELECT st.id, st.name, ST_X(st.geom) AS lon, ST_Y(st.geom) AS lat, MIN(d.distance) AS distance
+		FROM (
+			SELECT s.station_id,
+				CASE WHEN s.area_geom IS NOT NULL AND ST_Covers(s.area_geom, p.pt) THEN 0
+					ELSE ST_Distance(s.geom::geography, p.pt::geography)
+				END AS distance
+			FROM stops s, p
+			WHERE s.station_id IS NOT NULL
+				AND (ST_DWithin(s.geom::geography, p.pt::geography, $3)
+					OR (s.area_geom IS NOT NULL AND ST_Covers(s.area_geom, p.pt)))
+		) d
+		JOIN stations st ON st.id = d.station_id
+		GROUP BY st.id
+		ORDER BY distance, st.name
+		LIMIT $4
+	`
+	if err := r.dbCon.Select(&nearby, query, lat, lon, radius, limit); err != nil {
+		return nil, err
+	}
+
+	stations := make([]domain.Station, len(nearby))
+	for i, n := range nearby {
+		stations[i] = n.Station
+	}
+	if err := r.fillStationStops(stations); err != nil {
+		return nil, err
+	}
+	for i := range nearby {
+		nearby[i].Station = stations[i]
+	}
+	return nearby, nil
+}
@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"database/sql"
+	"swift_transit/domain"
+	"swift_transit/location"
+	"swift_transit/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type StopEventRepo interface {
+	location.StopRepo
+}
+
+type stopEventRepo struct {
+	dbCon       *sqlx.DB
+	utilHandler *utils.Handler
+}
+
+func NewStopEventRepo(dbcon *sqlx.DB, utilHandler *utils.Handler) StopEventRepo {
+	return &stopEventRepo{
+		dbCon:       dbcon,
+		utilHandler: utilHandler,
+	}
+}
+
+// FindStopAt returns the stop of the route whose area covers the point, or
+// nil when the point is outside every stop area.
+func (r *stopEventRepo) FindStopAt(routeId int64, lat, lon float64) (*domain.Stop, error) {
+	var stop domain.Stop
+	query := `
+		SELECT id, route_id, stop_order, name, ST_X(geom::geometry) as lon, ST_Y(geom::geometry) as lat, COALESCE(ST_AsGeoJSON(area_geom), '') as area_geom
+		FROM stops
+		WHERE route_id = $1 AND area_geom IS NOT NULL
+			AND ST_Covers(area_geom, ST_SetSRID(ST_MakePoint($3, $2), 4326))
+		ORDER BY stop_order
+		LIMIT 1
+	`
+	err := r.dbCon.Get(&stop, query, routeId, lat, lon)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stop, nil
+}
+
+func (r *stopEventRepo) CreateStopEvent(event domain.StopEvent) (*domain.StopEvent, error) {
+	query := `
+		INSERT INTO stop_events (bus_id, route_id, stop_id, stop_name, stop_order, event, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	if err := r.dbCon.QueryRow(query, event.BusId, event.RouteId, event.StopId, event.StopName, event.StopOrder, event.Event, event.OccurredAt).Scan(&event.Id); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
@@ -0,0 +1,151 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"swift_transit/domain"
+	"swift_transit/student"
+	"swift_transit/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type StudentRepo interface {
+	student.StudentRepo
+}
+
+type studentRepo struct {
+	dbCon       *sqlx.DB
+	utilHandler *utils.Handler
+}
+
+func NewStudentRepo(dbcon *sqlx.DB, utilHandler *utils.Handler) StudentRepo {
+	return &studentRepo{
+		dbCon:       dbcon,
+		utilHandler: utilHandler,
+	}
+}
+
+// Approved requests past their expiry date are reported as EXPIRED without
+// needing a sweep.
+const studentVerificationSelect = `
+	SELECT v.id, v.user_id, v.institution_id, v.expires_at,
+		CASE WHEN v.status = 'APPROVED' AND v.expires_at < CURRENT_DATE THEN 'EXPIRED' ELSE v.status END AS status,
+		v.note, v.reviewed_by, v.reviewed_at, v.created_at,
+		u.name AS user_name, u.email AS user_email
+	FROM student_verifications v
+	JOIN users u ON u.id = v.user_id
+`
+
+func (r *studentRepo) Create(verification domain.StudentVerification) (*domain.StudentVerification, error) {
+	query := `
+		INSERT INTO student_verifications (user_id, institution_id, expires_at, status)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	var id int64
+	if err := r.dbCon.Get(&id, query, verification.UserId, verification.InstitutionId, verification.ExpiresAt, verification.Status); err != nil {
+		return nil, fmt.Errorf("failed to create student verification: %w", err)
+	}
+	return r.GetByID(id)
+}
+
+func (r *studentRepo) GetByID(id int64) (*domain.StudentVerification, error) {
+	var v domain.StudentVerification
+	if err := r.dbCon.Get(&v, studentVerificationSelect+` WHERE v.id = $1`, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("student verification not found")
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *studentRepo) GetLatestByUser(userId int64) (*domain.StudentVerification, error) {
+	var v domain.StudentVerification
+	err := r.dbCon.Get(&v, studentVerificationSelect+` WHERE v.user_id = $1 ORDER BY v.created_at DESC, v.id DESC LIMIT 1`, userId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *studentRepo) HasPending(userId int64) (bool, error) {
+	var exists bool
+	err := r.dbCon.Get(&exists, `SELECT EXISTS(SELECT 1 FROM student_verifications WHERE user_id = $1 AND status = 'PENDING')`, userId)
+	return exists, err
+}
+
+func (r *studentRepo) List(status string, limit, offset int) ([]domain.StudentVerification, int, error) {
+	base := `SELECT * FROM (` + studentVerificationSelect + `) sv`
+	args := []interface{}{}
+	if status != "" {
+		base += ` WHERE sv.status = $1`
+		args = append(args, status)
+	}
+
+	var total int
+	if err := r.dbCon.Get(&total, `SELECT COUNT(*) FROM (`+base+`) c`, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`%s ORDER BY sv.created_at ASC LIMIT $%d OFFSET $%d`, base, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	verifications := []domain.StudentVerification{}
+	if err := r.dbCon.Select(&verifications, query, args...); err != nil {
+		return nil, 0, err
+	}
+	return verifications, total, nil
+}
+
+func (r *studentRepo) Approve(id, adminId int64, note string) error {
+	tx, err := r.dbCon.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var v domain.StudentVerification
+	err = tx.Get(&v, `
+		UPDATE student_verifications
+		SET status = 'APPROVED', reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP, note = $3
+		WHERE id = $1 AND status = 'PENDING'
+		RETURNING user_id, expires_at
+	`, id, adminId, note)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("verification is no longer pending")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to approve verification: %w", err)
+	}
+
+	_, err = tx.Exec(`UPDATE users SET is_student = TRUE, student_verified_until = $1 WHERE id = $2`, v.ExpiresAt, v.UserId)
+	if err != nil {
+		return fmt.Errorf("failed to update student status: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *studentRepo) Reject(id, adminId int64, note string) error {
+	res, err := r.dbCon.Exec(`
+		UPDATE student_verifications
+		SET status = 'REJECTED', reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP, note = $3
+		WHERE id = $1 AND status = 'PENDING'
+	`, id, adminId, note)
+	if err != nil {
+		return fmt.Errorf("failed to reject verification: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("verification is no longer pending")
+	}
+	return nil
+}
@@ -31,19 +31,26 @@ func NewTicketRepo(dbcon *sqlx.DB, utilHandler *utils.Handler) TicketRepo {
 }
 
 func (r *ticketRepo) Create(ticket domain.Ticket) (*domain.Ticket, error) {
+	return insertTicket(r.dbCon, ticket)
+}
+
+// insertTicket writes the ticket with the database or transaction given and
+// fills in its id and route version.
+func insertTicket(db sqlx.Ext, ticket domain.Ticket) (*domain.Ticket, error) {
 	query := `
-                INSERT INTO tickets (user_id, route_id, bus_name, start_destination, end_destination, fare, paid_status, checked, qr_code, created_at, batch_id, payment_method, payment_reference, payment_used, payment_status, cancelled_at, registration_number)
-                VALUES (:user_id, :route_id, :bus_name, :start_destination, :end_destination, :fare, :paid_status, :checked, :qr_code, :created_at, :batch_id, :payment_method, :payment_reference, :payment_used, :payment_status, :cancelled_at, :registration_number)
-                RETURNING id
+                INSERT INTO tickets (user_id, route_id, bus_name, start_destination, end_destination, fare, discount, paid_status, checked, qr_code, created_at, batch_id, payment_method, payment_reference, payment_used, payment_status, cancelled_at, registration_number, journey_id, leg_index, transfer_discount, checked_at, route_version_id)
+                VALUES (:user_id, :route_id, :bus_name, :start_destination, :end_destination, :fare, :discount, :paid_status, :checked, :qr_code, :created_at, :batch_id, :payment_method, :payment_reference, :payment_used, :payment_status, :cancelled_at, :registration_number, :journey_id, :leg_index, :transfer_discount, CASE WHEN :checked THEN CURRENT_TIMESTAMP END,
+                        (SELECT id FROM route_versions WHERE route_id = :route_id AND applied_at IS NOT NULL ORDER BY applied_at DESC, version DESC LIMIT 1))
+                RETURNING id, route_version_id
         `
-	rows, err := r.dbCon.NamedQuery(query, ticket)
+	rows, err := sqlx.NamedQuery(db, query, ticket)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	if rows.Next() {
-		err = rows.Scan(&ticket.Id)
+		err = rows.Scan(&ticket.Id, &ticket.RouteVersionId)
 		if err != nil {
 			return nil, err
 		}
@@ -74,29 +81,6 @@ func (r *ticketRepo) Get(id int64) (*domain.Ticket, error) {
 	return &ticket, nil
 }
 
-func (r *ticketRepo) CalculateFare(routeId int64, start, end string) (float64, error) {
-	var fare float64
-	query := `
-		SELECT 
-			GREATEST(10, (ST_Length(
-				ST_LineSubstring(
-					r.geom, 
-					ST_LineLocatePoint(r.geom, s1.geom), 
-					ST_LineLocatePoint(r.geom, s2.geom)
-				)::geography
-			) / 1000)*2.5) as fare
-		FROM routes r
-		JOIN stops s1 ON r.id = s1.route_id
-		JOIN stops s2 ON r.id = s2.route_id
-		WHERE r.id = $1 AND s1.name = $2 AND s2.name = $3
-	`
-	err := r.dbCon.Get(&fare, query, routeId, start, end)
-	if err != nil {
-		return 0, err
-	}
-	return fare, nil
-}
-
 func (r *ticketRepo) GetByUserID(userId int64, limit, offset int) ([]domain.Ticket, int, error) {
 	var tickets []domain.Ticket
 	var total int
@@ -155,7 +139,7 @@ func (r *ticketRepo) CancelTicket(id int64, cancelledAt time.Time, status string
 }
 
 func (r *ticketRepo) ValidateTicket(id int64, registrationNumber string) error {
-	query := `UPDATE tickets SET checked = TRUE, registration_number = $2 WHERE id = $1`
+	query := `UPDATE tickets SET checked = TRUE, checked_at = COALESCE(checked_at, CURRENT_TIMESTAMP), registration_number = $2 WHERE id = $1`
 	_, err := r.dbCon.Exec(query, id, registrationNumber)
 	return err
 }
@@ -167,8 +151,26 @@ func (r *ticketRepo) GetBatchCount(batchID string) (int, error) {
 	return count, err
 }
 
-func (r *ticketRepo) GetStop(routeId int64, stopName string) (*domain.Stop, error) {
+// GetStop finds the stop on the route version a ticket was sold on, or on
+// the route as it is now when versionId is 0.
+func (r *ticketRepo) GetStop(routeId int64, stopName string, versionId int64) (*domain.Stop, error) {
 	var stop domain.Stop
+	if versionId != 0 {
+		query := `
+			SELECT vs.id, v.route_id, vs.name, vs.stop_order, ST_X(vs.geom) AS lon, ST_Y(vs.geom) AS lat,
+				COALESCE(ST_AsGeoJSON(vs.area_geom), '') AS area_geom
+			FROM route_version_stops vs
+			JOIN route_versions v ON v.id = vs.version_id
+			WHERE v.id = $1 AND v.route_id = $2 AND vs.name = $3
+			ORDER BY vs.stop_order
+			LIMIT 1
+		`
+		if err := r.dbCon.Get(&stop, query, versionId, routeId, stopName); err != nil {
+			return nil, err
+		}
+		return &stop, nil
+	}
+
 	query := `
 		SELECT 
 			id, 
@@ -212,3 +214,18 @@ func (r *ticketRepo) GetLatestTicket(userId int64, routeId int64) (*domain.Ticke
 	}
 	return &ticket, nil
 }
+
+// GetRFIDSpend sums what the rider was actually charged for RFID trips since
+// the given time; cancelled tickets are not counted.
+func (r *ticketRepo) GetRFIDSpend(userId int64, since time.Time) (float64, error) {
+	var spend float64
+	query := `
+		SELECT COALESCE(SUM(fare), 0) FROM tickets
+		WHERE user_id = $1 AND payment_method = 'RFID' AND payment_status IN ('paid', $3)
+			AND cancelled_at IS NULL AND created_at >= $2
+	`
+	if err := r.dbCon.Get(&spend, query, userId, since, domain.TicketStatusCapped); err != nil {
+		return 0, err
+	}
+	return spend, nil
+}
@@ -0,0 +1,212 @@
+package repo
+
+import (
+	"database/sql"
+	"swift_transit/domain"
+	"swift_transit/trip"
+	"swift_transit/utils"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type TripRepo interface {
+	trip.TripRepo
+}
+
+type tripRepo struct {
+	dbCon       *sqlx.DB
+	utilHandler *utils.Handler
+}
+
+func NewTripRepo(dbcon *sqlx.DB, utilHandler *utils.Handler) TripRepo {
+	return &tripRepo{
+		dbCon:       dbcon,
+		utilHandler: utilHandler,
+	}
+}
+
+// A trip in progress has no stored path yet, so its distance is measured
+// from the points recorded so far
+const tripColumns = `
+	t.id, t.bus_id, b.registration_number, t.route_id, t.variant, t.driver_name, t.seat_capacity, t.status, t.started_at, t.ended_at,
+	t.scheduled_trip_id, to_char(t.service_date, 'YYYY-MM-DD') AS service_date, t.delay_seconds,
+	(SELECT COUNT(*) FROM trip_points WHERE trip_id = t.id) AS point_count,
+	CASE WHEN t.status = 'active'
+		THEN COALESCE((SELECT ST_Length(ST_MakeLine(geom ORDER BY recorded_at)::geography) FROM trip_points WHERE trip_id = t.id), 0)
+		ELSE t.distance_meters
+	END AS distance_meters
+`
+
+const tripPathColumn = `
+	ST_AsGeoJSON(COALESCE(t.path, (
+		SELECT ST_MakeLine(geom ORDER BY recorded_at) FROM trip_points WHERE trip_id = t.id HAVING COUNT(*) > 1
+	))) AS path
+`
+
+func (r *tripRepo) findOne(where string, args ...any) (*domain.Trip, error) {
+	var t domain.Trip
+	query := `SELECT ` + tripColumns + `, ` + tripPathColumn + `
+		FROM trips t
+		JOIN bus_credentials b ON b.id = t.bus_id
+		WHERE ` + where
+	err := r.dbCon.Get(&t, query, args...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *tripRepo) Create(t domain.Trip) (*domain.Trip, error) {
+	query := `
+		INSERT INTO trips (bus_id, route_id, variant, driver_name, status, started_at, scheduled_trip_id, service_date, seat_capacity)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8::date, seat_capacity
+		FROM bus_credentials
+		WHERE id = $1
+		RETURNING id
+	`
+	var id int64
+	if err := r.dbCon.QueryRow(query, t.BusId, t.RouteId, t.Variant, t.DriverName, t.Status, t.StartedAt, t.ScheduledTripId, t.ServiceDate).Scan(&id); err != nil {
+		return nil, err
+	}
+	return r.findOne(`t.id = $1`, id)
+}
+
+func (r *tripRepo) FindActive(busId int64) (*domain.Trip, error) {
+	return r.findOne(`t.bus_id = $1 AND t.status = 'active'`, busId)
+}
+
+// Complete closes the trip and stores its path, built from the recorded
+// points in the order they were reported.
+func (r *tripRepo) Complete(tripId int64, endedAt time.Time) (*domain.Trip, error) {
+	query := `
+		UPDATE trips t
+		SET status = 'completed',
+			ended_at = $2,
+			path = p.line,
+			distance_meters = COALESCE(ST_Length(p.line::geography), 0)
+		FROM (
+			SELECT CASE WHEN COUNT(*) > 1 THEN ST_MakeLine(geom ORDER BY recorded_at) END AS line
+			FROM trip_points
+			WHERE trip_id = $1
+		) p
+		WHERE t.id = $1 AND t.status = 'active'
+	`
+	if _, err := r.dbCon.Exec(query, tripId, endedAt); err != nil {
+		return nil, err
+	}
+	return r.findOne(`t.id = $1`, tripId)
+}
+
+func (r *tripRepo) AddPoint(busId int64, point domain.TripPoint) error {
+	query := `
+		INSERT INTO trip_points (trip_id, geom, speed, recorded_at)
+		SELECT id, ST_SetSRID(ST_MakePoint($3, $2), 4326), $4, $5
+		FROM trips
+		WHERE bus_id = $1 AND status = 'active'
+	`
+	_, err := r.dbCon.Exec(query, busId, point.Latitude, point.Longitude, point.Speed, point.RecordedAt)
+	return err
+}
+
+func (r *tripRepo) SetDelay(tripId int64, seconds int) error {
+	_, err := r.dbCon.Exec(`UPDATE trips SET delay_seconds = $2 WHERE id = $1`, tripId, seconds)
+	return err
+}
+
+// FindByOwner lists the trips of the owner's buses, newest first. A zero
+// busId lists every bus.
+func (r *tripRepo) FindByOwner(ownerId, busId int64, limit, offset int) ([]domain.Trip, int, error) {
+	where := `
+		FROM trips t
+		JOIN bus_credentials b ON b.id = t.bus_id
+		WHERE b.owner_id = $1 AND ($2 = 0 OR t.bus_id = $2)
+	`
+
+	var total int
+	if err := r.dbCon.Get(&total, `SELECT COUNT(*) `+where, ownerId, busId); err != nil {
+		return nil, 0, err
+	}
+
+	trips := []domain.Trip{}
+	query := `SELECT ` + tripColumns + where + ` ORDER BY t.started_at DESC LIMIT $3 OFFSET $4`
+	if err := r.dbCon.Select(&trips, query, ownerId, busId, limit, offset); err != nil {
+		return nil, 0, err
+	}
+	return trips, total, nil
+}
+
+func (r *tripRepo) FindByIdForOwner(ownerId, tripId int64) (*domain.Trip, error) {
+	return r.findOne(`t.id = $1 AND b.owner_id = $2`, tripId, ownerId)
+}
+
+func (r *tripRepo) FindById(tripId int64) (*domain.Trip, error) {
+	return r.findOne(`t.id = $1`, tripId)
+}
+
+func (r *tripRepo) GetPoints(tripId int64) ([]domain.TripPoint, error) {
+	points := []domain.TripPoint{}
+	query := `
+		SELECT ST_Y(geom) AS lat, ST_X(geom) AS lon, speed, recorded_at
+		FROM trip_points
+		WHERE trip_id = $1
+		ORDER BY recorded_at, id
+	`
+	if err := r.dbCon.Select(&points, query, tripId); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+func (r *tripRepo) GetStopEvents(busId int64, from, to time.Time) ([]domain.StopEvent, error) {
+	events := []domain.StopEvent{}
+	query := `
+		SELECT id, bus_id, route_id, stop_id, stop_name, stop_order, event, occurred_at
+		FROM stop_events
+		WHERE bus_id = $1 AND occurred_at BETWEEN $2 AND $3
+		ORDER BY occurred_at, id
+	`
+	if err := r.dbCon.Select(&events, query, busId, from, to); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetTicketChecks finds tickets checked on the bus between from and to.
+// Tickets record the bus by registration number only.
+func (r *tripRepo) GetTicketChecks(registrationNumber string, from, to time.Time) ([]domain.TripTicketCheck, error) {
+	checks := []domain.TripTicketCheck{}
+	query := `
+		SELECT id, route_id, start_destination, end_destination, checked_at
+		FROM tickets
+		WHERE registration_number = $1 AND checked_at BETWEEN $2 AND $3
+		ORDER BY checked_at, id
+	`
+	if err := r.dbCon.Select(&checks, query, registrationNumber, from, to); err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+func (r *tripRepo) GetBusIdByRegistration(registrationNumber string) (int64, error) {
+	var id int64
+	err := r.dbCon.Get(&id, `SELECT id FROM bus_credentials WHERE registration_number = $1`, registrationNumber)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// GetStopOrder returns the order of the named stop on the route, or -1 when
+// the route has no such stop.
+func (r *tripRepo) GetStopOrder(routeId int64, name string) (int, error) {
+	var order int
+	err := r.dbCon.Get(&order, `SELECT stop_order FROM stops WHERE route_id = $1 AND name = $2 ORDER BY stop_order LIMIT 1`, routeId, name)
+	if err == sql.ErrNoRows {
+		return -1, nil
+	}
+	return order, err
+}
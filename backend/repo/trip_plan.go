@@ -0,0 +1,36 @@
+package repo
+
+import "swift_transit/domain"
+
+// GetTransferPoints pairs stops of different routes that share a name or are
+// within walkMeters of each other. Stops at the same station need no walk.
+func (r *routeRepo) GetTransferPoints(walkMeters float64) ([]domain.TransferPoint, error) {
+	var points []domain.TransferPoint
+	// Each join uses its own index; an OR of the two would compare every
+	// pair of stops
+	query := `
+		WITH pairs AS (
+			SELECT s1.route_id AS from_route_id, s1.name AS from_stop, s2.route_id AS to_route_id, s2.name AS to_stop,
+				CASE WHEN s1.station_id = s2.station_id THEN 0
+					ELSE COALESCE(ST_Distance(s1.geom::geography, s2.geom::geography), 0)
+				END AS walk_distance
+			FROM stops s1
+			JOIN stops s2 ON lower(trim(s2.name)) = lower(trim(s1.name)) AND s2.route_id <> s1.route_id
+			UNION ALL
+			SELECT s1.route_id, s1.name, s2.route_id, s2.name,
+				CASE WHEN s1.station_id = s2.station_id THEN 0
+					ELSE ST_Distance(s1.geom::geography, s2.geom::geography)
+				END
+			FROM stops s1
+			JOIN stops s2 ON ST_DWithin(s1.geom::geography, s2.geom::geography, $1) AND s2.route_id <> s1.route_id
+		)
+		SELECT from_route_id, from_stop, to_route_id, to_stop, MIN(walk_distance) AS walk_distance
+		FROM pairs
+		GROUP BY from_route_id, from_stop, to_route_id, to_stop
+		ORDER BY from_route_id, walk_distance
+	`
+	if err := r.dbCon.Select(&points, query, walkMeters); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
@@ -5,7 +5,9 @@ import (
 	"swift_transit/rest/handlers/admin"
 	"swift_transit/rest/handlers/bus"
 	"swift_transit/rest/handlers/bus_owner"
+	"swift_transit/rest/handlers/pass"
 	"swift_transit/rest/handlers/route"
+	"swift_transit/rest/handlers/schedule"
 	"swift_transit/rest/handlers/ticket"
 	"swift_transit/rest/handlers/transaction"
 	"swift_transit/rest/handlers/user"
@@ -22,9 +24,11 @@ type Handler struct {
 	transactionHandler *transaction.Handler
 	busOwnerHandler    *bus_owner.Handler
 	adminHandler       *admin.Handler
+	passHandler        *pass.Handler
+	scheduleHandler    *schedule.Handler
 }
 
-func NewHandler(cnf *config.Config, mdlw *middlewares.Handler, userHandler *user.Handler, routeHandler *route.Handler, busHandler *bus.Handler, ticketHandler *ticket.Handler, transactionHandler *transaction.Handler, busOwnerHandler *bus_owner.Handler, adminHandler *admin.Handler) *Handler {
+func NewHandler(cnf *config.Config, mdlw *middlewares.Handler, userHandler *user.Handler, routeHandler *route.Handler, busHandler *bus.Handler, ticketHandler *ticket.Handler, transactionHandler *transaction.Handler, busOwnerHandler *bus_owner.Handler, adminHandler *admin.Handler, passHandler *pass.Handler, scheduleHandler *schedule.Handler) *Handler {
 	return &Handler{
 		cnf:                cnf,
 		mdlw:               mdlw,
@@ -35,5 +39,7 @@ func NewHandler(cnf *config.Config, mdlw *middlewares.Handler, userHandler *user
 		transactionHandler: transactionHandler,
 		busOwnerHandler:    busOwnerHandler,
 		adminHandler:       adminHandler,
+		passHandler:        passHandler,
+		scheduleHandler:    scheduleHandler,
 	}
 }
@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"swift_transit/domain"
+)
+
+func (h *Handler) GetConcessions(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.svc.GetConcessions()
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.utilHandler.SendData(w, rules, http.StatusOK)
+}
+
+func (h *Handler) CreateConcession(w http.ResponseWriter, r *http.Request) {
+	var rule domain.ConcessionRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.svc.CreateConcession(rule)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, created, http.StatusCreated)
+}
+
+func (h *Handler) UpdateConcession(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.utilHandler.SendError(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var rule domain.ConcessionRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rule.Id = id
+	if err := h.svc.UpdateConcession(rule); err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, map[string]string{"message": "Concession updated successfully"}, http.StatusOK)
+}
+
+func (h *Handler) DeleteConcession(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.utilHandler.SendError(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.svc.DeleteConcession(id); err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.utilHandler.SendData(w, map[string]string{"message": "Concession deleted successfully"}, http.StatusOK)
+}
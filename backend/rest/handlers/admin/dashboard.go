@@ -12,3 +12,12 @@ func (h *Handler) GetDashboardStats(w http.ResponseWriter, r *http.Request) {
 	}
 	h.utilHandler.SendData(w, stats, http.StatusOK)
 }
+
+func (h *Handler) GetStationActivity(w http.ResponseWriter, r *http.Request) {
+	activity, err := h.svc.GetStationActivity(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.utilHandler.SendData(w, activity, http.StatusOK)
+}
@@ -245,3 +245,29 @@ func (h *Handler) GetAllTransactions(w http.ResponseWriter, r *http.Request) {
 		"total_pages":  (total + pageSize - 1) / pageSize,
 	}, http.StatusOK)
 }
+
+// Bus alerts
+func (h *Handler) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	alerts, total, err := h.svc.GetAlerts(page, pageSize)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.utilHandler.SendData(w, map[string]interface{}{
+		"alerts":      alerts,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+		"total_pages": (total + pageSize - 1) / pageSize,
+	}, http.StatusOK)
+}
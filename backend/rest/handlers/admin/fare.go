@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"swift_transit/domain"
+)
+
+func (h *Handler) GetFarePolicy(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.utilHandler.SendError(w, "Invalid route ID", http.StatusBadRequest)
+		return
+	}
+
+	policy, err := h.svc.GetFarePolicy(id)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.utilHandler.SendData(w, policy, http.StatusOK)
+}
+
+func (h *Handler) UpdateFarePolicy(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.utilHandler.SendError(w, "Invalid route ID", http.StatusBadRequest)
+		return
+	}
+
+	var policy domain.FarePolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	policy.RouteId = id
+	updated, err := h.svc.UpdateFarePolicy(policy)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, updated, http.StatusOK)
+}
@@ -0,0 +1,66 @@
+package admin
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const maxGTFSUploadBytes = 50 << 20
+
+func (h *Handler) ExportGTFS(w http.ResponseWriter, r *http.Request) {
+	feed, err := h.svc.ExportGTFS()
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=gtfs-%s.zip", time.Now().Format("20060102")))
+	w.WriteHeader(http.StatusOK)
+	w.Write(feed)
+}
+
+// ImportGTFS accepts the feed zip either as a multipart "file" field or as
+// the raw request body. Updates of routes imported before take effect at the
+// RFC 3339 time in effective_from, or at once without it.
+func (h *Handler) ImportGTFS(w http.ResponseWriter, r *http.Request) {
+	var effectiveFrom time.Time
+	if val := r.URL.Query().Get("effective_from"); val != "" {
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			h.utilHandler.SendError(w, "effective_from must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		effectiveFrom = t
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxGTFSUploadBytes)
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			h.utilHandler.SendError(w, "file is required", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	feed, err := io.ReadAll(body)
+	if err != nil || len(feed) == 0 {
+		h.utilHandler.SendError(w, "Failed to read GTFS feed", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.svc.ImportGTFS(feed, effectiveFrom)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, report, http.StatusOK)
+}
@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"swift_transit/domain"
+)
+
+func (h *Handler) GetPassPlans(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.svc.GetPassPlans()
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.utilHandler.SendData(w, plans, http.StatusOK)
+}
+
+func (h *Handler) CreatePassPlan(w http.ResponseWriter, r *http.Request) {
+	var plan domain.PassPlan
+	if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.svc.CreatePassPlan(plan)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, created, http.StatusCreated)
+}
+
+func (h *Handler) UpdatePassPlan(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.utilHandler.SendError(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var plan domain.PassPlan
+	if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	plan.Id = id
+	if err := h.svc.UpdatePassPlan(plan); err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, map[string]string{"message": "Pass plan updated successfully"}, http.StatusOK)
+}
+
+func (h *Handler) DeletePassPlan(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.utilHandler.SendError(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.svc.DeletePassPlan(id); err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.utilHandler.SendData(w, map[string]string{"message": "Pass plan deleted successfully"}, http.StatusOK)
+}
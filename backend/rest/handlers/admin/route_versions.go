@@ -0,0 +1,137 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"swift_transit/domain"
+	"time"
+)
+
+// routeVersionPath reads the route id and version number from the path.
+func routeVersionPath(r *http.Request) (int64, int, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	version, err := strconv.Atoi(r.PathValue("version"))
+	if err != nil {
+		return 0, 0, false
+	}
+	return id, version, true
+}
+
+func (h *Handler) GetRouteVersions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		h.utilHandler.SendError(w, "Invalid route ID", http.StatusBadRequest)
+		return
+	}
+
+	versions, err := h.svc.GetRouteVersions(id)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.utilHandler.SendData(w, versions, http.StatusOK)
+}
+
+func (h *Handler) GetRouteVersion(w http.ResponseWriter, r *http.Request) {
+	id, version, ok := routeVersionPath(r)
+	if !ok {
+		h.utilHandler.SendError(w, "Invalid route ID or version", http.StatusBadRequest)
+		return
+	}
+
+	v, err := h.svc.GetRouteVersion(id, version)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.utilHandler.SendData(w, v, http.StatusOK)
+}
+
+// CreateRouteVersion publishes an edit of a route's name, line and stops once
+// it passes the route check. Without effective_from, or with one in the past,
+// it takes effect at once. With dry_run=true only the check is returned.
+func (h *Handler) CreateRouteVersion(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		h.utilHandler.SendError(w, "Invalid route ID", http.StatusBadRequest)
+		return
+	}
+
+	var v domain.RouteVersion
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	v.RouteId = id
+
+	if dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run")); dryRun {
+		check, err := h.svc.CheckRouteVersion(v)
+		if err != nil {
+			h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.utilHandler.SendData(w, check, http.StatusOK)
+		return
+	}
+
+	created, check, err := h.svc.CreateRouteVersion(v)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if created == nil {
+		h.utilHandler.SendData(w, check, http.StatusUnprocessableEntity)
+		return
+	}
+
+	h.utilHandler.SendData(w, map[string]interface{}{
+		"version": created,
+		"check":   check,
+	}, http.StatusCreated)
+}
+
+// ScheduleRouteVersion moves when a version not yet in effect takes effect.
+func (h *Handler) ScheduleRouteVersion(w http.ResponseWriter, r *http.Request) {
+	id, version, ok := routeVersionPath(r)
+	if !ok {
+		h.utilHandler.SendError(w, "Invalid route ID or version", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		EffectiveFrom time.Time `json:"effective_from"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	v, err := h.svc.ScheduleRouteVersion(id, version, req.EffectiveFrom)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, v, http.StatusOK)
+}
+
+func (h *Handler) DeleteRouteVersion(w http.ResponseWriter, r *http.Request) {
+	id, version, ok := routeVersionPath(r)
+	if !ok {
+		h.utilHandler.SendError(w, "Invalid route ID or version", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.svc.DeleteRouteVersion(id, version); err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, map[string]string{"message": "Route version deleted successfully"}, http.StatusOK)
+}
@@ -9,6 +9,7 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	// Protected routes - require admin authentication
 	// Dashboard
 	mux.Handle("GET /admin/dashboard/stats", h.mngr.With(http.HandlerFunc(h.GetDashboardStats), h.middlewareHandler.Authenticate))
+	mux.Handle("GET /admin/analytics/stations", h.mngr.With(http.HandlerFunc(h.GetStationActivity), h.middlewareHandler.Authenticate))
 
 	// Users
 	mux.Handle("GET /admin/users", h.mngr.With(http.HandlerFunc(h.GetAllUsers), h.middlewareHandler.Authenticate))
@@ -30,6 +31,37 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	// Routes
 	mux.Handle("GET /admin/routes", h.mngr.With(http.HandlerFunc(h.GetAllRoutes), h.middlewareHandler.Authenticate))
 	mux.Handle("DELETE /admin/routes/{id}", h.mngr.With(http.HandlerFunc(h.DeleteRoute), h.middlewareHandler.Authenticate))
+	mux.Handle("GET /admin/routes/{id}/versions", h.mngr.With(http.HandlerFunc(h.GetRouteVersions), h.middlewareHandler.Authenticate))
+	mux.Handle("POST /admin/routes/{id}/versions", h.mngr.With(http.HandlerFunc(h.CreateRouteVersion), h.middlewareHandler.Authenticate))
+	mux.Handle("GET /admin/routes/{id}/versions/{version}", h.mngr.With(http.HandlerFunc(h.GetRouteVersion), h.middlewareHandler.Authenticate))
+	mux.Handle("PUT /admin/routes/{id}/versions/{version}", h.mngr.With(http.HandlerFunc(h.ScheduleRouteVersion), h.middlewareHandler.Authenticate))
+	mux.Handle("DELETE /admin/routes/{id}/versions/{version}", h.mngr.With(http.HandlerFunc(h.DeleteRouteVersion), h.middlewareHandler.Authenticate))
+	mux.Handle("GET /admin/routes/{id}/fare-policy", h.mngr.With(http.HandlerFunc(h.GetFarePolicy), h.middlewareHandler.Authenticate))
+	mux.Handle("PUT /admin/routes/{id}/fare-policy", h.mngr.With(http.HandlerFunc(h.UpdateFarePolicy), h.middlewareHandler.Authenticate))
+
+	// GTFS
+	mux.Handle("GET /admin/gtfs/export", h.mngr.With(http.HandlerFunc(h.ExportGTFS), h.middlewareHandler.Authenticate))
+	mux.Handle("POST /admin/gtfs/import", h.mngr.With(http.HandlerFunc(h.ImportGTFS), h.middlewareHandler.Authenticate))
+
+	// Concessions
+	mux.Handle("GET /admin/concessions", h.mngr.With(http.HandlerFunc(h.GetConcessions), h.middlewareHandler.Authenticate))
+	mux.Handle("POST /admin/concessions", h.mngr.With(http.HandlerFunc(h.CreateConcession), h.middlewareHandler.Authenticate))
+	mux.Handle("PUT /admin/concessions/{id}", h.mngr.With(http.HandlerFunc(h.UpdateConcession), h.middlewareHandler.Authenticate))
+	mux.Handle("DELETE /admin/concessions/{id}", h.mngr.With(http.HandlerFunc(h.DeleteConcession), h.middlewareHandler.Authenticate))
+
+	// Pass Plans
+	mux.Handle("GET /admin/pass-plans", h.mngr.With(http.HandlerFunc(h.GetPassPlans), h.middlewareHandler.Authenticate))
+	mux.Handle("POST /admin/pass-plans", h.mngr.With(http.HandlerFunc(h.CreatePassPlan), h.middlewareHandler.Authenticate))
+	mux.Handle("PUT /admin/pass-plans/{id}", h.mngr.With(http.HandlerFunc(h.UpdatePassPlan), h.middlewareHandler.Authenticate))
+	mux.Handle("DELETE /admin/pass-plans/{id}", h.mngr.With(http.HandlerFunc(h.DeletePassPlan), h.middlewareHandler.Authenticate))
+
+	// Student Verifications
+	mux.Handle("GET /admin/student-verifications", h.mngr.With(http.HandlerFunc(h.GetStudentVerifications), h.middlewareHandler.Authenticate))
+	mux.Handle("POST /admin/student-verifications/{id}/approve", h.mngr.With(http.HandlerFunc(h.ApproveStudentVerification), h.middlewareHandler.Authenticate))
+	mux.Handle("POST /admin/student-verifications/{id}/reject", h.mngr.With(http.HandlerFunc(h.RejectStudentVerification), h.middlewareHandler.Authenticate))
+
+	// Bus alerts
+	mux.Handle("GET /admin/alerts", h.mngr.With(http.HandlerFunc(h.GetAlerts), h.middlewareHandler.Authenticate))
 
 	// Tickets
 	mux.Handle("GET /admin/tickets", h.mngr.With(http.HandlerFunc(h.GetAllTickets), h.middlewareHandler.Authenticate))
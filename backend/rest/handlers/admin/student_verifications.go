@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+type StudentVerificationDecision struct {
+	Note string `json:"note"`
+}
+
+func (h *Handler) GetStudentVerifications(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	// Defaults to the pending queue; pass status= (empty) explicitly for all
+	status := "PENDING"
+	if r.URL.Query().Has("status") {
+		status = r.URL.Query().Get("status")
+	}
+
+	verifications, total, err := h.svc.GetStudentVerifications(status, page, pageSize)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.utilHandler.SendData(w, map[string]interface{}{
+		"verifications": verifications,
+		"total":         total,
+		"page":          page,
+		"page_size":     pageSize,
+		"total_pages":   (total + pageSize - 1) / pageSize,
+	}, http.StatusOK)
+}
+
+func (h *Handler) ApproveStudentVerification(w http.ResponseWriter, r *http.Request) {
+	h.decideStudentVerification(w, r, true)
+}
+
+func (h *Handler) RejectStudentVerification(w http.ResponseWriter, r *http.Request) {
+	h.decideStudentVerification(w, r, false)
+}
+
+func (h *Handler) decideStudentVerification(w http.ResponseWriter, r *http.Request, approve bool) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.utilHandler.SendError(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var req StudentVerificationDecision
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	adminID := h.utilHandler.GetUserIDFromContext(r.Context())
+
+	decide := h.svc.RejectStudentVerification
+	if approve {
+		decide = h.svc.ApproveStudentVerification
+	}
+	verification, err := decide(id, adminID, req.Note)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, verification, http.StatusOK)
+}
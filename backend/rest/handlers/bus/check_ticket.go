@@ -13,17 +13,24 @@ func (h *Handler) CheckTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Basic validation
-	if req.QRCode == "" || req.CurrentStoppage.Name == "" {
-		h.utilHandler.SendError(w, "Missing required fields", http.StatusBadRequest)
-		return
-	}
-
 	busData, err := h.BusFromContext(r)
 	if err != nil {
 		h.utilHandler.SendError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
+
+	// Fall back to the stop the bus was detected at
+	if req.CurrentStoppage.Name == "" {
+		if stop, err := h.hub.CurrentStop(busData.Id); err == nil && stop != nil && stop.RouteId == busData.RouteId {
+			req.CurrentStoppage = ticket.CheckTicketStoppage{Name: stop.Name, Order: stop.Order}
+		}
+	}
+
+	// Basic validation
+	if req.QRCode == "" || req.CurrentStoppage.Name == "" {
+		h.utilHandler.SendError(w, "Missing required fields", http.StatusBadRequest)
+		return
+	}
 	req.RouteID = busData.RouteId
 	req.BusName = busData.RegistrationNumber
 	req.RegistrationNumber = busData.RegistrationNumber
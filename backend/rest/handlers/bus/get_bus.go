@@ -3,11 +3,23 @@ package bus
 import (
 	"encoding/json"
 	"net/http"
+	"swift_transit/bus"
 )
 
 type GetBusRequest struct {
 	StartDestination string `json:"start_destination"`
 	EndDestination   string `json:"end_destination"`
+
+	// Riders who do not know the stop names can search by coordinates
+	OriginLat      *float64 `json:"origin_lat"`
+	OriginLon      *float64 `json:"origin_lon"`
+	DestinationLat *float64 `json:"destination_lat"`
+	DestinationLon *float64 `json:"destination_lon"`
+	Radius         float64  `json:"radius"`
+}
+
+func (req GetBusRequest) hasCoordinates() bool {
+	return req.OriginLat != nil && req.OriginLon != nil && req.DestinationLat != nil && req.DestinationLon != nil
 }
 
 func (h *Handler) GetBus(w http.ResponseWriter, r *http.Request) {
@@ -17,8 +29,25 @@ func (h *Handler) GetBus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if (req.StartDestination == "" || req.EndDestination == "") && req.hasCoordinates() {
+		buses, err := h.svc.FindBusByLocation(bus.FindByLocationRequest{
+			OriginLat:      *req.OriginLat,
+			OriginLon:      *req.OriginLon,
+			DestinationLat: *req.DestinationLat,
+			DestinationLon: *req.DestinationLon,
+			Radius:         req.Radius,
+		})
+		if err != nil {
+			h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		h.utilHandler.SendData(w, buses, http.StatusOK)
+		return
+	}
+
 	if req.StartDestination == "" || req.EndDestination == "" {
-		h.utilHandler.SendError(w, "start_destination and end_destination are required", http.StatusBadRequest)
+		h.utilHandler.SendError(w, "start_destination and end_destination, or origin and destination coordinates, are required", http.StatusBadRequest)
 		return
 	}
 
@@ -4,6 +4,7 @@ import (
 	"swift_transit/location" // Added import for location package
 	"swift_transit/rest/middlewares"
 	"swift_transit/ticket"
+	"swift_transit/trip"
 	"swift_transit/utils"
 )
 
@@ -28,16 +29,18 @@ type AuthResponse struct {
 type Handler struct {
 	svc               Service
 	ticketService     ticket.Service
+	tripService       trip.Service
 	middlewareHandler *middlewares.Handler
 	mngr              *middlewares.Manager
 	utilHandler       *utils.Handler
 	hub               *location.Hub // Added Hub field
 }
 
-func NewHandler(svc Service, ticketService ticket.Service, middlewareHandler *middlewares.Handler, mngr *middlewares.Manager, utilHandler *utils.Handler, hub *location.Hub) *Handler {
+func NewHandler(svc Service, ticketService ticket.Service, tripService trip.Service, middlewareHandler *middlewares.Handler, mngr *middlewares.Manager, utilHandler *utils.Handler, hub *location.Hub) *Handler {
 	return &Handler{
 		svc:               svc,
 		ticketService:     ticketService,
+		tripService:       tripService,
 		middlewareHandler: middlewareHandler,
 		mngr:              mngr,
 		utilHandler:       utilHandler,
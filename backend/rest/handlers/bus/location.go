@@ -8,8 +8,15 @@ import (
 )
 
 func (h *Handler) LocationSocket(w http.ResponseWriter, r *http.Request) {
-	// Bus connects to this endpoint to send location updates
-	// We expect route_id in query params
+	// Buses publish with their token; riders subscribe without one
+	if r.Header.Get("Authorization") != "" {
+		h.middlewareHandler.Authenticate(http.HandlerFunc(h.busLocationSocket)).ServeHTTP(w, r)
+		return
+	}
+
+	// Riders connect to receive location updates for the route in route_id
+	// Stop events and ETAs are sent too when listed in types, e.g.
+	// ?types=stop_event,eta
 	routeIDStr := r.URL.Query().Get("route_id")
 	if routeIDStr == "" {
 		h.utilHandler.SendError(w, "route_id is required", http.StatusBadRequest)
@@ -23,7 +30,21 @@ func (h *Handler) LocationSocket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Upgrade to WebSocket
-	location.ServeWs(h.hub, w, r, routeID, true)
+	location.ServeWs(h.hub, w, r, routeID, nil)
+}
+
+func (h *Handler) busLocationSocket(w http.ResponseWriter, r *http.Request) {
+	busData, err := h.BusFromContext(r)
+	if err != nil || busData.Id == 0 || busData.RouteId == 0 || busData.RegistrationNumber == "" {
+		h.utilHandler.SendError(w, "a bus token is required to publish locations", http.StatusForbidden)
+		return
+	}
+
+	location.ServeWs(h.hub, w, r, busData.RouteId, &location.Publisher{
+		BusID:              busData.Id,
+		RouteID:            busData.RouteId,
+		RegistrationNumber: busData.RegistrationNumber,
+	})
 }
 
 func (h *Handler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
@@ -39,11 +60,15 @@ func (h *Handler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Basic validation
-	if update.BusID == 0 {
-		update.BusID = busData.Id
+	if busData.Id == 0 || busData.RouteId == 0 || busData.RegistrationNumber == "" {
+		h.utilHandler.SendError(w, "a bus token is required to publish locations", http.StatusForbidden)
+		return
 	}
+
+	// The bus can only report its own position on its own route
+	update.BusID = busData.Id
 	update.RouteID = busData.RouteId
+	update.RegistrationNumber = busData.RegistrationNumber
 
 	h.hub.BroadcastLocation(update)
 	h.utilHandler.SendData(w, "Location updated", http.StatusOK)
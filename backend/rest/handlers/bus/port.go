@@ -8,6 +8,7 @@ import (
 
 type Service interface {
 	FindBus(start, end string) ([]domain.Bus, error)
+	FindBusByLocation(req bus.FindByLocationRequest) ([]domain.Bus, error)
 	Login(regNum, password string, variant string) (*bus.BusLoginResult, error)
 	Register(regNum, password string, routeIdUp, routeIdDown int64) (*domain.BusCredential, error)
 	ValidateTicket(ticketID int64, routeID int64, busName string) error
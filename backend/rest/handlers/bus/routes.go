@@ -11,5 +11,9 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.Handle("POST /bus/validate", h.mngr.With(http.HandlerFunc(h.ValidateTicket), h.middlewareHandler.Authenticate))
 	mux.Handle("POST /bus/check-ticket", h.mngr.With(http.HandlerFunc(h.CheckTicket), h.middlewareHandler.Authenticate))
 	mux.Handle("GET /ws/location", http.HandlerFunc(h.LocationSocket))
+	mux.Handle("GET /gtfs-rt/vehicle-positions", h.mngr.With(http.HandlerFunc(h.VehiclePositions)))
+	mux.Handle("POST /bus/trips/start", h.mngr.With(http.HandlerFunc(h.StartTrip), h.middlewareHandler.Authenticate))
+	mux.Handle("POST /bus/trips/end", h.mngr.With(http.HandlerFunc(h.EndTrip), h.middlewareHandler.Authenticate))
+	mux.Handle("GET /bus/trips/current", h.mngr.With(http.HandlerFunc(h.CurrentTrip), h.middlewareHandler.Authenticate))
 	mux.Handle("POST /bus/location", h.mngr.With(http.HandlerFunc(h.UpdateLocation), h.middlewareHandler.Authenticate))
 }
@@ -0,0 +1,71 @@
+package bus
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"swift_transit/trip"
+)
+
+// StartTrip opens a trip for the bus. Positions it publishes afterwards, over
+// the socket or POST /bus/location, are recorded on the trip until it ends.
+func (h *Handler) StartTrip(w http.ResponseWriter, r *http.Request) {
+	busData, err := h.BusFromContext(r)
+	if err != nil || busData.Id == 0 {
+		h.utilHandler.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req trip.StartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.BusId = busData.Id
+	req.RouteId = busData.RouteId
+	req.Variant = busData.Variant
+
+	t, err := h.tripService.Start(req)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	h.utilHandler.SendData(w, t, http.StatusCreated)
+}
+
+func (h *Handler) EndTrip(w http.ResponseWriter, r *http.Request) {
+	busData, err := h.BusFromContext(r)
+	if err != nil || busData.Id == 0 {
+		h.utilHandler.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	t, err := h.tripService.End(busData.Id)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	h.utilHandler.SendData(w, t, http.StatusOK)
+}
+
+func (h *Handler) CurrentTrip(w http.ResponseWriter, r *http.Request) {
+	busData, err := h.BusFromContext(r)
+	if err != nil || busData.Id == 0 {
+		h.utilHandler.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	t, err := h.tripService.Current(busData.Id)
+	if err != nil {
+		h.utilHandler.SendError(w, "Failed to load trip", http.StatusInternalServerError)
+		return
+	}
+	if t == nil {
+		h.utilHandler.SendError(w, "no trip in progress", http.StatusNotFound)
+		return
+	}
+
+	h.utilHandler.SendData(w, t, http.StatusOK)
+}
@@ -0,0 +1,20 @@
+package bus
+
+import (
+	"net/http"
+	"swift_transit/gtfs"
+	"time"
+)
+
+func (h *Handler) VehiclePositions(w http.ResponseWriter, r *http.Request) {
+	positions, err := h.hub.Positions()
+	if err != nil {
+		h.utilHandler.SendError(w, "Failed to load vehicle positions", http.StatusInternalServerError)
+		return
+	}
+	feed := gtfs.EncodeVehiclePositions(positions, time.Now())
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+	w.Write(feed)
+}
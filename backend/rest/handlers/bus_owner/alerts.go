@@ -0,0 +1,49 @@
+package bus_owner
+
+import (
+	"net/http"
+	"strconv"
+	"swift_transit/location"
+)
+
+func (h *Handler) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	ownerID := h.utilHandler.GetUserIDFromContext(r.Context())
+	if ownerID == 0 {
+		h.utilHandler.SendError(w, "Unauthorized: Invalid user ID", http.StatusUnauthorized)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	alerts, total, err := h.alertSvc.GetOwnerAlerts(ownerID, page, pageSize)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.utilHandler.SendData(w, map[string]interface{}{
+		"alerts":      alerts,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+		"total_pages": (total + pageSize - 1) / pageSize,
+	}, http.StatusOK)
+}
+
+// AlertSocket streams new alerts about the owner's buses as they are raised.
+func (h *Handler) AlertSocket(w http.ResponseWriter, r *http.Request) {
+	ownerID := h.utilHandler.GetUserIDFromContext(r.Context())
+	if ownerID == 0 {
+		h.utilHandler.SendError(w, "Unauthorized: Invalid user ID", http.StatusUnauthorized)
+		return
+	}
+
+	location.ServeOwnerWs(h.hub, w, r, ownerID)
+}
@@ -1,23 +1,32 @@
 package bus_owner
 
 import (
+	"swift_transit/alert"
 	"swift_transit/bus_owner"
+	"swift_transit/location"
 	"swift_transit/rest/middlewares"
+	"swift_transit/trip"
 	"swift_transit/utils"
 )
 
 type Handler struct {
 	svc               bus_owner.Service
+	tripSvc           trip.Service
+	alertSvc          alert.Service
 	middlewareHandler *middlewares.Handler
 	mngr              *middlewares.Manager
 	utilHandler       *utils.Handler
+	hub               *location.Hub
 }
 
-func NewHandler(svc bus_owner.Service, middlewareHandler *middlewares.Handler, mngr *middlewares.Manager, utilHandler *utils.Handler) *Handler {
+func NewHandler(svc bus_owner.Service, tripSvc trip.Service, alertSvc alert.Service, middlewareHandler *middlewares.Handler, mngr *middlewares.Manager, utilHandler *utils.Handler, hub *location.Hub) *Handler {
 	return &Handler{
 		svc:               svc,
+		tripSvc:           tripSvc,
+		alertSvc:          alertSvc,
 		middlewareHandler: middlewareHandler,
 		mngr:              mngr,
 		utilHandler:       utilHandler,
+		hub:               hub,
 	}
 }
@@ -10,6 +10,7 @@ type RegisterBusRequest struct {
 	Password           string `json:"password"`
 	RouteIdUp          int64  `json:"route_id_up"`
 	RouteIdDown        int64  `json:"route_id_down"`
+	SeatCapacity       int    `json:"seat_capacity"`
 }
 
 func (h *Handler) RegisterBus(w http.ResponseWriter, r *http.Request) {
@@ -51,7 +52,7 @@ func (h *Handler) RegisterBus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.svc.RegisterBus(ownerID, req.RegistrationNumber, req.Password, req.RouteIdUp, req.RouteIdDown); err != nil {
+	if err := h.svc.RegisterBus(ownerID, req.RegistrationNumber, req.Password, req.RouteIdUp, req.RouteIdDown, req.SeatCapacity); err != nil {
 		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
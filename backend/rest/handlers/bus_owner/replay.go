@@ -0,0 +1,57 @@
+package bus_owner
+
+import (
+	"net/http"
+	"strconv"
+	"swift_transit/domain"
+	"swift_transit/location"
+)
+
+const (
+	defaultReplaySpeed = 10
+	maxReplaySpeed     = 100
+)
+
+// ReplayTrip streams a recorded trip over a socket, speed times faster than
+// it happened. The bus's owner and admins may replay it.
+func (h *Handler) ReplayTrip(w http.ResponseWriter, r *http.Request) {
+	id := h.utilHandler.GetID(r)
+	if id == 0 {
+		h.utilHandler.SendError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	speed := float64(defaultReplaySpeed)
+	if v := r.URL.Query().Get("speed"); v != "" {
+		s, err := strconv.ParseFloat(v, 64)
+		if err != nil || s <= 0 || s > maxReplaySpeed {
+			h.utilHandler.SendError(w, "speed must be between 0 and 100", http.StatusBadRequest)
+			return
+		}
+		speed = s
+	}
+
+	var trip *domain.Trip
+	var err error
+	switch h.utilHandler.GetRoleFromContext(r.Context()) {
+	case domain.RoleAdmin:
+		trip, err = h.tripSvc.GetTrip(id)
+	case domain.RoleBusOwner:
+		trip, err = h.tripSvc.GetOwnerTrip(h.utilHandler.GetUserIDFromContext(r.Context()), id)
+	default:
+		h.utilHandler.SendError(w, "only the bus owner or an admin can replay a trip", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	frames, err := h.tripSvc.Replay(trip)
+	if err != nil {
+		h.utilHandler.SendError(w, "Failed to load trip recording", http.StatusInternalServerError)
+		return
+	}
+
+	location.ServeReplay(w, r, trip.RouteId, frames, speed)
+}
@@ -10,5 +10,10 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.Handle("GET /bus-owner/buses", h.mngr.With(http.HandlerFunc(h.GetBuses), h.middlewareHandler.Authenticate))
 	mux.Handle("GET /bus-owner/analytics", h.mngr.With(http.HandlerFunc(h.GetAnalytics), h.middlewareHandler.Authenticate))
 	mux.Handle("GET /bus-owner/analytics/per-bus", h.mngr.With(http.HandlerFunc(h.GetPerBusAnalytics), h.middlewareHandler.Authenticate))
+	mux.Handle("GET /bus-owner/trips", h.mngr.With(http.HandlerFunc(h.GetTrips), h.middlewareHandler.Authenticate))
+	mux.Handle("GET /bus-owner/trips/{id}", h.mngr.With(http.HandlerFunc(h.GetTrip), h.middlewareHandler.Authenticate))
+	mux.Handle("GET /bus-owner/trips/{id}/replay", h.middlewareHandler.Authenticate(http.HandlerFunc(h.ReplayTrip)))
+	mux.Handle("GET /bus-owner/alerts", h.mngr.With(http.HandlerFunc(h.GetAlerts), h.middlewareHandler.Authenticate))
+	mux.Handle("GET /ws/bus-owner/alerts", h.middlewareHandler.Authenticate(http.HandlerFunc(h.AlertSocket)))
 	mux.Handle("GET /bus-owner/routes", h.mngr.With(http.HandlerFunc(h.GetRoutes), h.middlewareHandler.Authenticate))
 }
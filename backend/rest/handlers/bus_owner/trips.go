@@ -0,0 +1,68 @@
+package bus_owner
+
+import (
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) GetTrips(w http.ResponseWriter, r *http.Request) {
+	ownerID := h.utilHandler.GetUserIDFromContext(r.Context())
+	if ownerID == 0 {
+		h.utilHandler.SendError(w, "Unauthorized: Invalid user ID", http.StatusUnauthorized)
+		return
+	}
+
+	var busID int64
+	if v := r.URL.Query().Get("bus_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			h.utilHandler.SendError(w, "invalid bus_id", http.StatusBadRequest)
+			return
+		}
+		busID = id
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	trips, total, err := h.tripSvc.GetOwnerTrips(ownerID, busID, page, pageSize)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.utilHandler.SendData(w, map[string]interface{}{
+		"trips":       trips,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+		"total_pages": (total + pageSize - 1) / pageSize,
+	}, http.StatusOK)
+}
+
+func (h *Handler) GetTrip(w http.ResponseWriter, r *http.Request) {
+	ownerID := h.utilHandler.GetUserIDFromContext(r.Context())
+	if ownerID == 0 {
+		h.utilHandler.SendError(w, "Unauthorized: Invalid user ID", http.StatusUnauthorized)
+		return
+	}
+
+	id := h.utilHandler.GetID(r)
+	if id == 0 {
+		h.utilHandler.SendError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	trip, err := h.tripSvc.GetOwnerTrip(ownerID, id)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.utilHandler.SendData(w, trip, http.StatusOK)
+}
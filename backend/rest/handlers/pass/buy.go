@@ -0,0 +1,34 @@
+package pass
+
+import (
+	"encoding/json"
+	"net/http"
+	"swift_transit/pass"
+)
+
+func (h *Handler) BuyPass(w http.ResponseWriter, r *http.Request) {
+	var req pass.BuyPassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.PlanId == 0 {
+		h.utilHandler.SendError(w, "Invalid request parameters", http.StatusBadRequest)
+		return
+	}
+
+	req.UserId = h.utilHandler.GetUserIDFromContext(r.Context())
+	if req.UserId == 0 {
+		h.utilHandler.SendError(w, "Invalid user data in token", http.StatusUnauthorized)
+		return
+	}
+
+	resp, err := h.svc.BuyPass(req)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, resp, http.StatusOK)
+}
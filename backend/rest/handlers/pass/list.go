@@ -0,0 +1,19 @@
+package pass
+
+import "net/http"
+
+func (h *Handler) GetPasses(w http.ResponseWriter, r *http.Request) {
+	userId := h.utilHandler.GetUserIDFromContext(r.Context())
+	if userId == 0 {
+		h.utilHandler.SendError(w, "Invalid user data in token", http.StatusUnauthorized)
+		return
+	}
+
+	passes, err := h.svc.GetUserPasses(userId)
+	if err != nil {
+		h.utilHandler.SendError(w, "Failed to fetch passes", http.StatusInternalServerError)
+		return
+	}
+
+	h.utilHandler.SendData(w, passes, http.StatusOK)
+}
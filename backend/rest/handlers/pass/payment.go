@@ -0,0 +1,88 @@
+package pass
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func (h *Handler) PaymentSuccess(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.utilHandler.SendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	tranID := r.FormValue("tran_id")
+	if tranID == "" {
+		tranID = r.URL.Query().Get("tran_id")
+	}
+
+	valID := r.FormValue("val_id")
+	if valID == "" {
+		valID = r.URL.Query().Get("val_id")
+	}
+
+	if tranID == "" || valID == "" {
+		h.utilHandler.SendError(w, "Missing transaction reference", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.svc.CompletePayment(tranID, valID)
+	if err != nil {
+		h.renderPaymentResult(w, false, err.Error())
+		return
+	}
+
+	h.renderPaymentResult(w, true, fmt.Sprintf("Your %s is active until %s.", p.PlanName, p.ValidUntil.Format("02 Jan 2006 15:04")))
+}
+
+func (h *Handler) PaymentFail(w http.ResponseWriter, r *http.Request) {
+	tranID := r.URL.Query().Get("tran_id")
+	if tranID != "" {
+		_ = h.svc.CancelPayment(tranID)
+	}
+	h.renderPaymentResult(w, false, "Payment failed. Please try again.")
+}
+
+func (h *Handler) PaymentCancel(w http.ResponseWriter, r *http.Request) {
+	tranID := r.URL.Query().Get("tran_id")
+	if tranID != "" {
+		_ = h.svc.CancelPayment(tranID)
+	}
+	h.renderPaymentResult(w, false, "Payment cancelled")
+}
+
+func (h *Handler) renderPaymentResult(w http.ResponseWriter, success bool, message string) {
+	status := "failed"
+	icon := "✕"
+	color := "#e74c3c"
+	if success {
+		status = "successful"
+		icon = "✓"
+		color = "#27ae60"
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	fmt.Fprintf(w, `
+        <html>
+            <head>
+                <title>Pass Purchase %s</title>
+                <meta name="viewport" content="width=device-width, initial-scale=1.0">
+                <style>
+                    body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; text-align: center; padding: 40px 20px; background-color: #f4f7f6; }
+                    .container { background: white; padding: 36px; border-radius: 16px; box-shadow: 0 4px 15px rgba(0,0,0,0.05); max-width: 420px; margin: 0 auto; }
+                    .icon { color: %s; font-size: 64px; margin-bottom: 16px; }
+                    h1 { color: #2c3e50; margin-bottom: 10px; font-size: 24px; }
+                    p { color: #7f8c8d; margin-bottom: 20px; font-size: 16px; line-height: 1.5; }
+                </style>
+            </head>
+            <body>
+                <div class="container">
+                    <div class="icon">%s</div>
+                    <h1>Pass Purchase %s</h1>
+                    <p>%s</p>
+                    <p style="margin-top: 12px; font-size: 14px; color: #95a5a6;">You can close this window now.</p>
+                </div>
+            </body>
+        </html>
+    `, status, color, icon, status, message)
+}
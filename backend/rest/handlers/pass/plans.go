@@ -0,0 +1,13 @@
+package pass
+
+import "net/http"
+
+func (h *Handler) GetPlans(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.svc.GetPlans(true)
+	if err != nil {
+		h.utilHandler.SendError(w, "Failed to fetch pass plans", http.StatusInternalServerError)
+		return
+	}
+
+	h.utilHandler.SendData(w, plans, http.StatusOK)
+}
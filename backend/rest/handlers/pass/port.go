@@ -0,0 +1,14 @@
+package pass
+
+import (
+	"swift_transit/domain"
+	"swift_transit/pass"
+)
+
+type Service interface {
+	GetPlans(activeOnly bool) ([]domain.PassPlan, error)
+	BuyPass(req pass.BuyPassRequest) (*pass.BuyPassResponse, error)
+	CompletePayment(tranID, valID string) (*domain.Pass, error)
+	CancelPayment(tranID string) error
+	GetUserPasses(userId int64) ([]domain.Pass, error)
+}
@@ -0,0 +1,12 @@
+package pass
+
+import "net/http"
+
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.Handle("GET /pass/plans", h.mngr.With(http.HandlerFunc(h.GetPlans)))
+	mux.Handle("POST /pass/buy", h.mngr.With(http.HandlerFunc(h.BuyPass), h.middlewareHandler.Authenticate))
+	mux.Handle("GET /pass", h.mngr.With(http.HandlerFunc(h.GetPasses), h.middlewareHandler.Authenticate))
+	mux.Handle("/pass/payment/success", h.mngr.With(http.HandlerFunc(h.PaymentSuccess)))
+	mux.Handle("/pass/payment/fail", h.mngr.With(http.HandlerFunc(h.PaymentFail)))
+	mux.Handle("/pass/payment/cancel", h.mngr.With(http.HandlerFunc(h.PaymentCancel)))
+}
@@ -0,0 +1,36 @@
+package route
+
+import (
+	"net/http"
+	"sort"
+	"swift_transit/location"
+	"time"
+)
+
+type activeBus struct {
+	location.VehiclePosition
+	AgeSeconds int64 `json:"age_seconds"`
+}
+
+func (h *Handler) GetActiveBuses(w http.ResponseWriter, r *http.Request) {
+	id := h.utilHandler.GetID(r)
+	if id == 0 {
+		h.utilHandler.SendError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	positions, err := h.hub.RoutePositions(id)
+	if err != nil {
+		h.utilHandler.SendError(w, "Failed to load bus positions", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	buses := make([]activeBus, 0, len(positions))
+	for _, p := range positions {
+		buses = append(buses, activeBus{VehiclePosition: p, AgeSeconds: int64(now.Sub(p.UpdatedAt).Seconds())})
+	}
+	sort.Slice(buses, func(i, j int) bool { return buses[i].AgeSeconds < buses[j].AgeSeconds })
+
+	h.utilHandler.SendData(w, buses, http.StatusOK)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"strconv"
 	"swift_transit/domain"
 )
 
@@ -30,7 +31,9 @@ type Geojson struct {
 	Features []Feature `json:"features"`
 }
 
-// Handle incoming request and unmarshal JSON
+// Create saves the route in the GeoJSON body once it passes the route check.
+// With dry_run=true only the check is returned, so the route builder can show
+// its problems before saving.
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	// Read the body
@@ -79,9 +82,15 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	stopOrder := 1
 	for _, feature := range jsonData.Features {
 		if feature.Geom.Type == "Point" {
-			if coords, ok := feature.Geom.Coordinates.([]interface{}); ok {
-				lat := math.Round(coords[1].(float64)*100000) / 100000
-				lon := math.Round(coords[0].(float64)*100000) / 100000
+			if coords, ok := feature.Geom.Coordinates.([]interface{}); ok && len(coords) >= 2 {
+				rawLon, okLon := coords[0].(float64)
+				rawLat, okLat := coords[1].(float64)
+				if !okLon || !okLat {
+					h.utilHandler.SendError(w, fmt.Sprintf("Invalid coordinates for stop %q", feature.Properties.Name), http.StatusBadRequest)
+					return
+				}
+				lat := math.Round(rawLat*100000) / 100000
+				lon := math.Round(rawLon*100000) / 100000
 
 				stop := domain.Stop{
 					Name:  feature.Properties.Name,
@@ -120,11 +129,28 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		Stops:             stoppages,
 	}
 
-	createdRoute, err := h.svc.Create(route)
+	if dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run")); dryRun {
+		check, err := h.svc.CheckRoute(route)
+		if err != nil {
+			h.utilHandler.SendError(w, fmt.Sprintf("Failed to check route: %v", err), http.StatusInternalServerError)
+			return
+		}
+		h.utilHandler.SendData(w, check, http.StatusOK)
+		return
+	}
+
+	createdRoute, check, err := h.svc.Create(route)
 	if err != nil {
 		h.utilHandler.SendError(w, fmt.Sprintf("Failed to create route: %v", err), http.StatusInternalServerError)
 		return
 	}
+	if createdRoute == nil {
+		h.utilHandler.SendData(w, check, http.StatusUnprocessableEntity)
+		return
+	}
 
-	h.utilHandler.SendData(w, createdRoute, http.StatusOK)
+	h.utilHandler.SendData(w, map[string]interface{}{
+		"route": createdRoute,
+		"check": check,
+	}, http.StatusOK)
 }
@@ -0,0 +1,23 @@
+package route
+
+import (
+	"net/http"
+	"strings"
+)
+
+func (h *Handler) GetETA(w http.ResponseWriter, r *http.Request) {
+	id := h.utilHandler.GetID(r)
+	if id == 0 {
+		h.utilHandler.SendError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	stop := strings.TrimSpace(r.URL.Query().Get("stop"))
+
+	etas, err := h.etaSvc.RouteETAs(id, stop)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.utilHandler.SendData(w, etas, http.StatusOK)
+}
@@ -1,22 +1,28 @@
 package route
 
 import (
+	"swift_transit/eta"
+	"swift_transit/location"
 	"swift_transit/rest/middlewares"
 	"swift_transit/utils"
 )
 
 type Handler struct {
 	svc               Service
+	etaSvc            eta.Service
 	middlewareHandler *middlewares.Handler
 	mngr              *middlewares.Manager
 	utilHandler       *utils.Handler
+	hub               *location.Hub
 }
 
-func NewHandler(svc Service, middlewareHandler *middlewares.Handler, mngr *middlewares.Manager, utilHandler *utils.Handler) *Handler {
+func NewHandler(svc Service, etaSvc eta.Service, middlewareHandler *middlewares.Handler, mngr *middlewares.Manager, utilHandler *utils.Handler, hub *location.Hub) *Handler {
 	return &Handler{
 		svc:               svc,
+		etaSvc:            etaSvc,
 		middlewareHandler: middlewareHandler,
 		mngr:              mngr,
 		utilHandler:       utilHandler,
+		hub:               hub,
 	}
 }
@@ -0,0 +1,34 @@
+package route
+
+import (
+	"net/http"
+	"strconv"
+	"swift_transit/route"
+)
+
+func (h *Handler) PlanTrip(w http.ResponseWriter, r *http.Request) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if from == "" || to == "" {
+		h.utilHandler.SendError(w, "from and to parameters are required", http.StatusBadRequest)
+		return
+	}
+
+	maxTransfers := route.DefaultMaxTransfers
+	if v := r.URL.Query().Get("max_transfers"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			h.utilHandler.SendError(w, "invalid max_transfers", http.StatusBadRequest)
+			return
+		}
+		maxTransfers = n
+	}
+
+	itineraries, err := h.svc.PlanTrip(from, to, maxTransfers)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.utilHandler.SendData(w, itineraries, http.StatusOK)
+}
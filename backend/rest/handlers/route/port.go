@@ -5,8 +5,12 @@ import "swift_transit/domain"
 type Service interface {
 	FindAll() ([]domain.Route, error)
 	FindByID(id int64) (*domain.Route, error)
-	Create(route domain.Route) (*domain.Route, error)
+	CheckRoute(route domain.Route) (*domain.RouteCheck, error)
+	Create(route domain.Route) (*domain.Route, *domain.RouteCheck, error)
 	FindRoute(start, end string) (*domain.Route, error)
 	SearchByName(query string) ([]domain.Route, error)
 	SearchStops(query string) ([]string, error)
+	PlanTrip(from, to string, maxTransfers int) ([]domain.Itinerary, error)
+	NearbyStations(lat, lon, radius float64) ([]domain.NearbyStation, error)
+	GetStation(id int64) (*domain.Station, error)
 }
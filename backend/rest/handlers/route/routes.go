@@ -6,5 +6,10 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.Handle("POST /route", h.mngr.With(http.HandlerFunc(h.Create)))
 	mux.Handle("GET /route/search", h.mngr.With(http.HandlerFunc(h.SearchRoute)))
 	mux.Handle("GET /route/stops", h.mngr.With(http.HandlerFunc(h.SearchStops)))
+	mux.Handle("GET /route/plan", h.mngr.With(http.HandlerFunc(h.PlanTrip)))
+	mux.Handle("GET /stops/nearby", h.mngr.With(http.HandlerFunc(h.NearbyStations)))
+	mux.Handle("GET /stations/{id}", h.mngr.With(http.HandlerFunc(h.GetStation)))
 	mux.Handle("GET /route/{id}", h.mngr.With(http.HandlerFunc(h.GetByID)))
+	mux.Handle("GET /route/{id}/buses", h.mngr.With(http.HandlerFunc(h.GetActiveBuses)))
+	mux.Handle("GET /route/{id}/eta", h.mngr.With(http.HandlerFunc(h.GetETA)))
 }
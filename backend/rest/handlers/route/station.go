@@ -0,0 +1,49 @@
+package route
+
+import (
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) NearbyStations(w http.ResponseWriter, r *http.Request) {
+	lat, errLat := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	lon, errLon := strconv.ParseFloat(r.URL.Query().Get("lon"), 64)
+	if errLat != nil || errLon != nil {
+		h.utilHandler.SendError(w, "lat and lon parameters are required", http.StatusBadRequest)
+		return
+	}
+
+	var radius float64
+	if v := r.URL.Query().Get("radius"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed <= 0 {
+			h.utilHandler.SendError(w, "invalid radius", http.StatusBadRequest)
+			return
+		}
+		radius = parsed
+	}
+
+	stations, err := h.svc.NearbyStations(lat, lon, radius)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, stations, http.StatusOK)
+}
+
+func (h *Handler) GetStation(w http.ResponseWriter, r *http.Request) {
+	id := h.utilHandler.GetID(r)
+	if id == 0 {
+		h.utilHandler.SendError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	station, err := h.svc.GetStation(id)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.utilHandler.SendData(w, station, http.StatusOK)
+}
@@ -0,0 +1,128 @@
+package schedule
+
+import (
+	"encoding/json"
+	"net/http"
+	"swift_transit/domain"
+)
+
+func (h *Handler) GetCalendars(w http.ResponseWriter, r *http.Request, ownerId int64) {
+	calendars, err := h.svc.GetCalendars(ownerId)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.utilHandler.SendData(w, calendars, http.StatusOK)
+}
+
+func (h *Handler) GetCalendar(w http.ResponseWriter, r *http.Request, ownerId int64) {
+	id := h.utilHandler.GetID(r)
+	if id == 0 {
+		h.utilHandler.SendError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	calendar, err := h.svc.GetCalendar(ownerId, id)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.utilHandler.SendData(w, calendar, http.StatusOK)
+}
+
+func (h *Handler) CreateCalendar(w http.ResponseWriter, r *http.Request, ownerId int64) {
+	var calendar domain.ServiceCalendar
+	if err := json.NewDecoder(r.Body).Decode(&calendar); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.svc.CreateCalendar(ownerId, calendar)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, created, http.StatusCreated)
+}
+
+func (h *Handler) UpdateCalendar(w http.ResponseWriter, r *http.Request, ownerId int64) {
+	id := h.utilHandler.GetID(r)
+	if id == 0 {
+		h.utilHandler.SendError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var calendar domain.ServiceCalendar
+	if err := json.NewDecoder(r.Body).Decode(&calendar); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	calendar.Id = id
+
+	updated, err := h.svc.UpdateCalendar(ownerId, calendar)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, updated, http.StatusOK)
+}
+
+func (h *Handler) DeleteCalendar(w http.ResponseWriter, r *http.Request, ownerId int64) {
+	id := h.utilHandler.GetID(r)
+	if id == 0 {
+		h.utilHandler.SendError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.svc.DeleteCalendar(ownerId, id); err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, map[string]string{"message": "Calendar deleted successfully"}, http.StatusOK)
+}
+
+// SetException adds or removes service on the date in the path, for
+// instance to take a holiday off a weekday calendar.
+func (h *Handler) SetException(w http.ResponseWriter, r *http.Request, ownerId int64) {
+	id := h.utilHandler.GetID(r)
+	if id == 0 {
+		h.utilHandler.SendError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var exception domain.CalendarException
+	if err := json.NewDecoder(r.Body).Decode(&exception); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	exception.CalendarId = id
+	exception.Date = r.PathValue("date")
+
+	saved, err := h.svc.SetException(ownerId, exception)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, saved, http.StatusOK)
+}
+
+func (h *Handler) DeleteException(w http.ResponseWriter, r *http.Request, ownerId int64) {
+	id := h.utilHandler.GetID(r)
+	if id == 0 {
+		h.utilHandler.SendError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.svc.DeleteException(ownerId, id, r.PathValue("date")); err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, map[string]string{"message": "Exception deleted successfully"}, http.StatusOK)
+}
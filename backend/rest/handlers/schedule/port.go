@@ -0,0 +1,21 @@
+package schedule
+
+import "swift_transit/domain"
+
+type Service interface {
+	GetCalendars(ownerId int64) ([]domain.ServiceCalendar, error)
+	GetCalendar(ownerId, id int64) (*domain.ServiceCalendar, error)
+	CreateCalendar(ownerId int64, calendar domain.ServiceCalendar) (*domain.ServiceCalendar, error)
+	UpdateCalendar(ownerId int64, calendar domain.ServiceCalendar) (*domain.ServiceCalendar, error)
+	DeleteCalendar(ownerId, id int64) error
+	SetException(ownerId int64, exception domain.CalendarException) (*domain.CalendarException, error)
+	DeleteException(ownerId, calendarId int64, date string) error
+
+	GetTrips(ownerId, routeId int64) ([]domain.ScheduledTrip, error)
+	GetTrip(ownerId, id int64) (*domain.ScheduledTrip, error)
+	CreateTrip(ownerId int64, trip domain.ScheduledTrip) (*domain.ScheduledTrip, error)
+	UpdateTrip(ownerId int64, trip domain.ScheduledTrip) (*domain.ScheduledTrip, error)
+	DeleteTrip(ownerId, id int64) error
+
+	Timetable(routeId int64, date string) (*domain.Timetable, error)
+}
@@ -0,0 +1,50 @@
+package schedule
+
+import (
+	"net/http"
+	"swift_transit/domain"
+)
+
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.Handle("GET /route/{id}/timetable", h.mngr.With(http.HandlerFunc(h.GetTimetable)))
+
+	// Admins manage every schedule and the shared calendars; owners manage
+	// their own
+	for prefix, role := range map[string]string{"/admin": domain.RoleAdmin, "/bus-owner": domain.RoleBusOwner} {
+		h.handle(mux, "GET "+prefix+"/calendars", role, h.GetCalendars)
+		h.handle(mux, "POST "+prefix+"/calendars", role, h.CreateCalendar)
+		h.handle(mux, "GET "+prefix+"/calendars/{id}", role, h.GetCalendar)
+		h.handle(mux, "PUT "+prefix+"/calendars/{id}", role, h.UpdateCalendar)
+		h.handle(mux, "DELETE "+prefix+"/calendars/{id}", role, h.DeleteCalendar)
+		h.handle(mux, "PUT "+prefix+"/calendars/{id}/exceptions/{date}", role, h.SetException)
+		h.handle(mux, "DELETE "+prefix+"/calendars/{id}/exceptions/{date}", role, h.DeleteException)
+
+		h.handle(mux, "GET "+prefix+"/schedules", role, h.GetTrips)
+		h.handle(mux, "POST "+prefix+"/schedules", role, h.CreateTrip)
+		h.handle(mux, "GET "+prefix+"/schedules/{id}", role, h.GetTrip)
+		h.handle(mux, "PUT "+prefix+"/schedules/{id}", role, h.UpdateTrip)
+		h.handle(mux, "DELETE "+prefix+"/schedules/{id}", role, h.DeleteTrip)
+	}
+}
+
+// scopedHandler serves a request on behalf of ownerId, which is 0 for admins.
+type scopedHandler func(w http.ResponseWriter, r *http.Request, ownerId int64)
+
+// handle registers an authenticated route open only to tokens with the given
+// role.
+func (h *Handler) handle(mux *http.ServeMux, pattern, role string, next scopedHandler) {
+	mux.Handle(pattern, h.mngr.With(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.utilHandler.GetRoleFromContext(r.Context()) != role {
+			h.utilHandler.SendError(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		var ownerId int64
+		if role == domain.RoleBusOwner {
+			if ownerId = h.utilHandler.GetUserIDFromContext(r.Context()); ownerId == 0 {
+				h.utilHandler.SendError(w, "Unauthorized: Invalid user ID", http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r, ownerId)
+	}), h.middlewareHandler.Authenticate))
+}
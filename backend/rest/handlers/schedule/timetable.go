@@ -0,0 +1,24 @@
+package schedule
+
+import (
+	"net/http"
+	"strings"
+)
+
+// GetTimetable lists the route's scheduled trips on ?date=, today by default.
+func (h *Handler) GetTimetable(w http.ResponseWriter, r *http.Request) {
+	id := h.utilHandler.GetID(r)
+	if id == 0 {
+		h.utilHandler.SendError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	date := strings.TrimSpace(r.URL.Query().Get("date"))
+
+	timetable, err := h.svc.Timetable(id, date)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, timetable, http.StatusOK)
+}
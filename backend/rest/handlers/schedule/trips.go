@@ -0,0 +1,102 @@
+package schedule
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"swift_transit/domain"
+)
+
+// GetTrips lists scheduled trips, optionally of one ?route_id=.
+func (h *Handler) GetTrips(w http.ResponseWriter, r *http.Request, ownerId int64) {
+	var routeId int64
+	if v := r.URL.Query().Get("route_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			h.utilHandler.SendError(w, "invalid route_id", http.StatusBadRequest)
+			return
+		}
+		routeId = id
+	}
+
+	trips, err := h.svc.GetTrips(ownerId, routeId)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.utilHandler.SendData(w, trips, http.StatusOK)
+}
+
+func (h *Handler) GetTrip(w http.ResponseWriter, r *http.Request, ownerId int64) {
+	id := h.utilHandler.GetID(r)
+	if id == 0 {
+		h.utilHandler.SendError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	trip, err := h.svc.GetTrip(ownerId, id)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.utilHandler.SendData(w, trip, http.StatusOK)
+}
+
+// CreateTrip schedules a run of a route variant. Stop times are given by
+// stop order as "HH:MM:SS" after midnight of the service day.
+func (h *Handler) CreateTrip(w http.ResponseWriter, r *http.Request, ownerId int64) {
+	var trip domain.ScheduledTrip
+	if err := json.NewDecoder(r.Body).Decode(&trip); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.svc.CreateTrip(ownerId, trip)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, created, http.StatusCreated)
+}
+
+// UpdateTrip replaces a scheduled trip, stop times included.
+func (h *Handler) UpdateTrip(w http.ResponseWriter, r *http.Request, ownerId int64) {
+	id := h.utilHandler.GetID(r)
+	if id == 0 {
+		h.utilHandler.SendError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var trip domain.ScheduledTrip
+	if err := json.NewDecoder(r.Body).Decode(&trip); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	trip.Id = id
+
+	updated, err := h.svc.UpdateTrip(ownerId, trip)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, updated, http.StatusOK)
+}
+
+func (h *Handler) DeleteTrip(w http.ResponseWriter, r *http.Request, ownerId int64) {
+	id := h.utilHandler.GetID(r)
+	if id == 0 {
+		h.utilHandler.SendError(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.svc.DeleteTrip(ownerId, id); err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, map[string]string{"message": "Scheduled trip deleted successfully"}, http.StatusOK)
+}
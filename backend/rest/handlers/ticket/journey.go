@@ -0,0 +1,51 @@
+package ticket
+
+import (
+	"encoding/json"
+	"net/http"
+	"swift_transit/ticket"
+)
+
+func (h *Handler) BuyJourney(w http.ResponseWriter, r *http.Request) {
+	var req ticket.BuyJourneyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.UserId = h.utilHandler.GetUserIDFromContext(r.Context())
+	if req.UserId == 0 {
+		h.utilHandler.SendError(w, "Invalid user data in token", http.StatusUnauthorized)
+		return
+	}
+
+	resp, err := h.svc.BuyJourney(req)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, resp, http.StatusOK)
+}
+
+func (h *Handler) GetJourney(w http.ResponseWriter, r *http.Request) {
+	userID := h.utilHandler.GetUserIDFromContext(r.Context())
+	if userID == 0 {
+		h.utilHandler.SendError(w, "Invalid user data in token", http.StatusUnauthorized)
+		return
+	}
+
+	journeyID := h.utilHandler.GetID(r)
+	if journeyID == 0 {
+		h.utilHandler.SendError(w, "invalid journey id", http.StatusBadRequest)
+		return
+	}
+
+	journey, err := h.svc.GetJourney(userID, journeyID)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.utilHandler.SendData(w, journey, http.StatusOK)
+}
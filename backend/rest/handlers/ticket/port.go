@@ -17,5 +17,9 @@ type Service interface {
 	GetPaymentStatus(ticketID int64) (string, error)
 	CancelTicket(userID int64, ticketID int64) (float64, error)
 	ProcessRFIDPayment(req ticket.RFIDPaymentRequest) (*ticket.RFIDPaymentResponse, error)
+	TapOn(req ticket.RFIDTapRequest) (*ticket.RFIDPaymentResponse, error)
+	TapOff(req ticket.RFIDTapRequest) (*ticket.RFIDPaymentResponse, error)
+	BuyJourney(req ticket.BuyJourneyRequest) (*ticket.BuyJourneyResponse, error)
+	GetJourney(userID, journeyID int64) (*domain.MultiLegJourney, error)
 	CreateOverTravelTicket(originalTicketID int64, currentStop string, paymentCollected bool) (*domain.Ticket, error)
 }
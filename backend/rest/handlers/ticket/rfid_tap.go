@@ -0,0 +1,37 @@
+package ticket
+
+import (
+	"encoding/json"
+	"net/http"
+	"swift_transit/ticket"
+)
+
+func (h *Handler) TapOn(w http.ResponseWriter, r *http.Request) {
+	h.handleTap(w, r, h.svc.TapOn)
+}
+
+func (h *Handler) TapOff(w http.ResponseWriter, r *http.Request) {
+	h.handleTap(w, r, h.svc.TapOff)
+}
+
+func (h *Handler) handleTap(w http.ResponseWriter, r *http.Request, tap func(ticket.RFIDTapRequest) (*ticket.RFIDPaymentResponse, error)) {
+	var req ticket.RFIDTapRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.RFID == "" || req.RouteID == 0 || req.Stop == "" {
+		http.Error(w, "rfid, route_id and stop are required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := tap(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -11,7 +11,11 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.Handle("GET /ticket/download", h.mngr.With(http.HandlerFunc(h.DownloadTicket)))
 	mux.Handle("GET /ticket/status", h.mngr.With(http.HandlerFunc(h.GetTicketStatus)))
 	mux.Handle("GET /ticket", h.mngr.With(http.HandlerFunc(h.GetTickets), h.middlewareHandler.Authenticate))
+	mux.Handle("POST /ticket/journey", h.mngr.With(http.HandlerFunc(h.BuyJourney), h.middlewareHandler.Authenticate))
+	mux.Handle("GET /ticket/journey/{id}", h.mngr.With(http.HandlerFunc(h.GetJourney), h.middlewareHandler.Authenticate))
 	mux.Handle("POST /ticket/cancel/{id}", h.mngr.With(http.HandlerFunc(h.CancelTicket), h.middlewareHandler.Authenticate))
 	mux.Handle("POST /ticket/rfid-payment", http.HandlerFunc(h.ProcessRFIDPayment)) // No auth for now, or bus auth?
+	mux.Handle("POST /ticket/rfid/tap-on", http.HandlerFunc(h.TapOn))
+	mux.Handle("POST /ticket/rfid/tap-off", http.HandlerFunc(h.TapOff))
 	mux.Handle("POST /ticket/over-travel", http.HandlerFunc(h.CreateOverTravelTicket))
 }
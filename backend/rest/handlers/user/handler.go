@@ -4,6 +4,7 @@ import (
 	"context"
 	"swift_transit/location"
 	"swift_transit/rest/middlewares"
+	"swift_transit/student"
 	"swift_transit/utils"
 
 	"github.com/go-redis/redis/v8"
@@ -11,6 +12,7 @@ import (
 
 type Handler struct {
 	svc               Service
+	studentSvc        student.Service
 	middlewareHandler *middlewares.Handler
 	mngr              *middlewares.Manager
 	utilHandler       *utils.Handler
@@ -19,9 +21,10 @@ type Handler struct {
 	hub               *location.Hub
 }
 
-func NewHandler(svc Service, middlewareHandler *middlewares.Handler, mngr *middlewares.Manager, utilHandler *utils.Handler, redis *redis.Client, ctx context.Context, hub *location.Hub) *Handler {
+func NewHandler(svc Service, studentSvc student.Service, middlewareHandler *middlewares.Handler, mngr *middlewares.Manager, utilHandler *utils.Handler, redis *redis.Client, ctx context.Context, hub *location.Hub) *Handler {
 	return &Handler{
 		svc:               svc,
+		studentSvc:        studentSvc,
 		middlewareHandler: middlewareHandler,
 		mngr:              mngr,
 		utilHandler:       utilHandler,
@@ -8,6 +8,8 @@ import (
 
 func (h *Handler) LocationSocket(w http.ResponseWriter, r *http.Request) {
 	// User connects to this endpoint to receive location updates for a specific route
+	// Stop events and ETAs are sent too when listed in types, e.g.
+	// ?types=stop_event,eta
 	routeIDStr := r.URL.Query().Get("route_id")
 	if routeIDStr == "" {
 		h.utilHandler.SendError(w, "route_id is required", http.StatusBadRequest)
@@ -21,5 +23,5 @@ func (h *Handler) LocationSocket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Upgrade to WebSocket
-	location.ServeWs(h.hub, w, r, routeID, false)
+	location.ServeWs(h.hub, w, r, routeID, nil)
 }
@@ -23,4 +23,8 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.Handle("POST /auth/change-password", h.mngr.With(http.HandlerFunc(h.ChangePassword), h.middlewareHandler.Authenticate))
 	mux.Handle("GET /user/rfid", h.mngr.With(http.HandlerFunc(h.GetRFIDStatus), h.middlewareHandler.Authenticate))
 	mux.Handle("POST /user/rfid/toggle", h.mngr.With(http.HandlerFunc(h.ToggleRFIDStatus), h.middlewareHandler.Authenticate))
+
+	// Student Verification
+	mux.Handle("GET /user/student-verification", h.mngr.With(http.HandlerFunc(h.GetStudentVerification), h.middlewareHandler.Authenticate))
+	mux.Handle("POST /user/student-verification", h.mngr.With(http.HandlerFunc(h.SubmitStudentVerification), h.middlewareHandler.Authenticate))
 }
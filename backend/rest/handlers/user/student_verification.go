@@ -0,0 +1,60 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+type StudentVerificationRequest struct {
+	InstitutionId string `json:"institution_id"`
+	ExpiresAt     string `json:"expires_at"` // YYYY-MM-DD
+}
+
+func (h *Handler) SubmitStudentVerification(w http.ResponseWriter, r *http.Request) {
+	userID := h.utilHandler.GetUserIDFromContext(r.Context())
+	if userID == 0 {
+		h.utilHandler.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req StudentVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.utilHandler.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	expiresAt, err := time.Parse("2006-01-02", req.ExpiresAt)
+	if err != nil {
+		h.utilHandler.SendError(w, "Invalid expires_at, expected YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	verification, err := h.studentSvc.Submit(userID, req.InstitutionId, expiresAt)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.utilHandler.SendData(w, verification, http.StatusCreated)
+}
+
+func (h *Handler) GetStudentVerification(w http.ResponseWriter, r *http.Request) {
+	userID := h.utilHandler.GetUserIDFromContext(r.Context())
+	if userID == 0 {
+		h.utilHandler.SendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	verification, err := h.studentSvc.GetStatus(userID)
+	if err != nil {
+		h.utilHandler.SendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if verification == nil {
+		h.utilHandler.SendData(w, map[string]string{"status": "UNVERIFIED"}, http.StatusOK)
+		return
+	}
+
+	h.utilHandler.SendData(w, verification, http.StatusOK)
+}
@@ -15,6 +15,8 @@ func (h *Handler) Serve() {
 	h.transactionHandler.RegisterRoutes(mux)
 	h.busOwnerHandler.RegisterRoutes(mux)
 	h.adminHandler.RegisterRoutes(mux)
+	h.passHandler.RegisterRoutes(mux)
+	h.scheduleHandler.RegisterRoutes(mux)
 	mngr := h.mdlw.NewManager()
 	mngr.Use(h.mdlw.Logger, h.mdlw.Cors)
 	wrappedMux := mngr.WrapMux(mux)
@@ -0,0 +1,110 @@
+package route
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"swift_transit/domain"
+)
+
+func (svc *service) CheckRoute(route domain.Route) (*domain.RouteCheck, error) {
+	check := &domain.RouteCheck{Errors: []string{}, Warnings: []string{}, Stops: []domain.SnappedStop{}}
+	fail := func(format string, args ...any) {
+		check.Errors = append(check.Errors, fmt.Sprintf(format, args...))
+	}
+	warn := func(format string, args ...any) {
+		check.Warnings = append(check.Warnings, fmt.Sprintf(format, args...))
+	}
+
+	if strings.TrimSpace(route.Name) == "" {
+		fail("route name is required")
+	}
+	if len(route.Stops) < 2 {
+		fail("a route needs at least two stops")
+	}
+	names := make(map[string]bool, len(route.Stops))
+	for i, stop := range route.Stops {
+		name := strings.TrimSpace(stop.Name)
+		if name == "" {
+			fail("stop %d has no name", i+1)
+		} else if names[name] {
+			warn("stop %q appears more than once", name)
+		}
+		names[name] = true
+	}
+
+	line := route.LineStringGeoJSON
+	if msg := lineProblem(line); msg != "" {
+		fail("%s", msg)
+		return check, nil
+	}
+
+	length, simple, err := svc.repo.MeasureLine(*line)
+	if err != nil {
+		return nil, err
+	}
+	check.LengthMeters = length
+	if length < svc.thresholds.MinLengthMeters {
+		fail("route line is %.0f m long, shorter than the %.0f m minimum", length, svc.thresholds.MinLengthMeters)
+	}
+	if !simple {
+		fail("route line crosses itself")
+	}
+	if len(route.Stops) == 0 {
+		check.Valid = len(check.Errors) == 0
+		return check, nil
+	}
+
+	// Stops sent without an order keep the order they were sent in
+	given := make([]domain.Stop, len(route.Stops))
+	copy(given, route.Stops)
+	for i := range given {
+		if given[i].Order == 0 {
+			given[i].Order = i + 1
+		}
+	}
+	stops, err := svc.repo.LocateStops(*line, given)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(stops, func(i, j int) bool {
+		return stops[i].Position < stops[j].Position
+	})
+	for i := range stops {
+		stop := &stops[i]
+		stop.Order = i + 1
+		if stop.Offset > svc.thresholds.StopOffsetMeters {
+			warn("stop %q is %.0f m from the route line", stop.Name, stop.Offset)
+		}
+		if stop.GivenOrder != stop.Order {
+			warn("stop %q was given as stop %d but is stop %d along the line", stop.Name, stop.GivenOrder, stop.Order)
+		}
+		if i > 0 && stop.DistanceAlong-stops[i-1].DistanceAlong < 1 {
+			warn("stops %q and %q snap to the same point of the line", stops[i-1].Name, stop.Name)
+		}
+	}
+	check.Stops = stops
+	check.Valid = len(check.Errors) == 0
+	return check, nil
+}
+
+// lineProblem describes what keeps the line from being measured, if anything.
+func lineProblem(line *domain.LineString) string {
+	if line == nil || len(line.Coordinates) == 0 {
+		return "route line is required"
+	}
+	if line.Type != "LineString" {
+		return fmt.Sprintf("route line must be a LineString, not %s", line.Type)
+	}
+	if len(line.Coordinates) < 2 {
+		return "route line needs at least two points"
+	}
+	for i, c := range line.Coordinates {
+		if len(c) < 2 || math.IsNaN(c[0]) || math.IsNaN(c[1]) ||
+			c[0] < -180 || c[0] > 180 || c[1] < -90 || c[1] > 90 {
+			return fmt.Sprintf("route line point %d is not a valid coordinate", i+1)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,207 @@
+package route
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"swift_transit/domain"
+	"time"
+)
+
+const (
+	// Stops on different routes closer than this are treated as one place
+	TransferWalkMeters  = 300
+	DefaultMaxTransfers = 2
+	MaxTransfersLimit   = 3
+
+	maxItineraries = 5
+	// Only the best candidates by stops and walking are priced
+	maxCandidates = 20
+)
+
+type candidate struct {
+	key       string
+	legs      []domain.ItineraryLeg
+	rideStops int
+	walk      float64
+}
+
+// planner searches the stop network as a graph of routes joined by transfer
+// points. Routes run one way, so a leg always alights at a later stop than
+// it boards.
+type planner struct {
+	routes    map[int64]domain.Route
+	stopIndex map[int64]map[string]int
+	transfers map[int64][]domain.TransferPoint
+}
+
+func newPlanner(routes []domain.Route, points []domain.TransferPoint) *planner {
+	p := &planner{
+		routes:    make(map[int64]domain.Route),
+		stopIndex: make(map[int64]map[string]int),
+		transfers: make(map[int64][]domain.TransferPoint),
+	}
+	for _, rt := range routes {
+		p.routes[rt.Id] = rt
+		idx := make(map[string]int)
+		for i, stop := range rt.Stops {
+			idx[stop.Name] = i
+		}
+		p.stopIndex[rt.Id] = idx
+	}
+	for _, tp := range points {
+		p.transfers[tp.FromRouteId] = append(p.transfers[tp.FromRouteId], tp)
+	}
+	return p
+}
+
+func (p *planner) leg(routeId int64, from, to string) domain.ItineraryLeg {
+	idx := p.stopIndex[routeId]
+	return domain.ItineraryLeg{
+		RouteId:   routeId,
+		RouteName: p.routes[routeId].Name,
+		From:      from,
+		To:        to,
+		Stops:     idx[to] - idx[from],
+	}
+}
+
+// ahead reports whether the route reaches the stop to after the stop from.
+func (p *planner) ahead(routeId int64, from, to string) bool {
+	idx := p.stopIndex[routeId]
+	i, okFrom := idx[from]
+	j, okTo := idx[to]
+	return okFrom && okTo && i < j
+}
+
+// search returns the shortest candidate for every sequence of routes that
+// connects from and to with at most maxTransfers changes.
+func (p *planner) search(from, to string, maxTransfers int) []candidate {
+	best := make(map[string]candidate)
+
+	var visit func(routeId int64, board string, legs []domain.ItineraryLeg, used map[int64]bool, walk float64, rideStops int)
+	visit = func(routeId int64, board string, legs []domain.ItineraryLeg, used map[int64]bool, walk float64, rideStops int) {
+		if p.ahead(routeId, board, to) {
+			last := p.leg(routeId, board, to)
+			c := candidate{
+				legs:      append(append([]domain.ItineraryLeg{}, legs...), last),
+				rideStops: rideStops + last.Stops,
+				walk:      walk,
+			}
+			ids := make([]string, len(c.legs))
+			for i, l := range c.legs {
+				ids[i] = strconv.FormatInt(l.RouteId, 10)
+			}
+			c.key = strings.Join(ids, "-")
+			if prev, ok := best[c.key]; !ok || c.rideStops < prev.rideStops || (c.rideStops == prev.rideStops && c.walk < prev.walk) {
+				best[c.key] = c
+			}
+			return
+		}
+		if len(legs) >= maxTransfers {
+			return
+		}
+
+		for _, tp := range p.transfers[routeId] {
+			if used[tp.ToRouteId] || !p.ahead(routeId, board, tp.FromStop) || tp.ToStop == to {
+				continue
+			}
+			l := p.leg(routeId, board, tp.FromStop)
+			l.WalkDistance = tp.WalkDistance
+			used[tp.ToRouteId] = true
+			visit(tp.ToRouteId, tp.ToStop, append(legs[:len(legs):len(legs)], l), used, walk+tp.WalkDistance, rideStops+l.Stops)
+			used[tp.ToRouteId] = false
+		}
+	}
+
+	for routeId, idx := range p.stopIndex {
+		if _, ok := idx[from]; ok {
+			visit(routeId, from, nil, map[int64]bool{routeId: true}, 0, 0)
+		}
+	}
+
+	candidates := make([]candidate, 0, len(best))
+	for _, c := range best {
+		candidates = append(candidates, c)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if len(a.legs) != len(b.legs) {
+			return len(a.legs) < len(b.legs)
+		}
+		if a.rideStops != b.rideStops {
+			return a.rideStops < b.rideStops
+		}
+		if a.walk != b.walk {
+			return a.walk < b.walk
+		}
+		return a.key < b.key
+	})
+	return candidates
+}
+
+func (svc *service) PlanTrip(from, to string, maxTransfers int) ([]domain.Itinerary, error) {
+	if from == "" || to == "" {
+		return nil, fmt.Errorf("from and to are required")
+	}
+	if from == to {
+		return nil, fmt.Errorf("from and to must be different stops")
+	}
+	if maxTransfers < 0 {
+		maxTransfers = DefaultMaxTransfers
+	}
+	if maxTransfers > MaxTransfersLimit {
+		maxTransfers = MaxTransfersLimit
+	}
+
+	routes, err := svc.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	points, err := svc.repo.GetTransferPoints(TransferWalkMeters)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := newPlanner(routes, points).search(from, to, maxTransfers)
+	if len(candidates) > maxCandidates {
+		candidates = candidates[:maxCandidates]
+	}
+
+	itineraries := []domain.Itinerary{}
+	for _, c := range candidates {
+		it := domain.Itinerary{Legs: c.legs, Transfers: len(c.legs) - 1, WalkDistance: c.walk}
+		priced := true
+		for i, l := range it.Legs {
+			fare, err := svc.fareSvc.CalculateFare(l.RouteId, l.From, l.To, time.Now())
+			if err != nil {
+				priced = false
+				break
+			}
+			it.Legs[i].Fare = fare
+			it.TotalFare += fare
+		}
+		if priced {
+			itineraries = append(itineraries, it)
+		}
+	}
+	if len(itineraries) == 0 {
+		return nil, fmt.Errorf("no itinerary found from %s to %s", from, to)
+	}
+
+	sort.SliceStable(itineraries, func(i, j int) bool {
+		a, b := itineraries[i], itineraries[j]
+		if a.Transfers != b.Transfers {
+			return a.Transfers < b.Transfers
+		}
+		if a.TotalFare != b.TotalFare {
+			return a.TotalFare < b.TotalFare
+		}
+		return a.WalkDistance < b.WalkDistance
+	})
+	if len(itineraries) > maxItineraries {
+		itineraries = itineraries[:maxItineraries]
+	}
+	return itineraries, nil
+}
@@ -2,15 +2,43 @@ package route
 
 import (
 	"swift_transit/domain"
+	"time"
 )
 
 type Service interface {
-	Create(route domain.Route) (*domain.Route, error)
+	// CheckRoute validates a new route and snaps its stops onto the line,
+	// ordered by where they lie along it, without saving anything
+	CheckRoute(route domain.Route) (*domain.RouteCheck, error)
+	// Create saves the route as CheckRoute would leave it. A route that
+	// fails the check is not saved and only the check is returned.
+	Create(route domain.Route) (*domain.Route, *domain.RouteCheck, error)
 	FindAll() ([]domain.Route, error)
 	FindByID(id int64) (*domain.Route, error)
 	FindRoute(start, end string) (*domain.Route, error)
 	SearchByName(query string) ([]domain.Route, error)
 	SearchStops(query string) ([]string, error)
+	PlanTrip(from, to string, maxTransfers int) ([]domain.Itinerary, error)
+	// NearbyStations lists the stations near the point with the routes
+	// stopping at each
+	NearbyStations(lat, lon, radius float64) ([]domain.NearbyStation, error)
+	GetStation(id int64) (*domain.Station, error)
+
+	GetVersions(routeId int64) ([]domain.RouteVersion, error)
+	GetVersion(routeId int64, version int) (*domain.RouteVersion, error)
+	// CheckVersion runs the route check on an edit of the route without
+	// saving it
+	CheckVersion(v domain.RouteVersion) (*domain.RouteCheck, error)
+	// CreateVersion saves an edit of the route as CheckVersion would leave
+	// it. It takes effect at EffectiveFrom, or at once when that is unset or
+	// already past. An edit that fails the check is not saved and only the
+	// check is returned.
+	CreateVersion(v domain.RouteVersion) (*domain.RouteVersion, *domain.RouteCheck, error)
+	// ScheduleVersion moves when a version not yet in effect takes effect
+	ScheduleVersion(routeId int64, version int, effectiveFrom time.Time) (*domain.RouteVersion, error)
+	// DeleteVersion drops a version not yet in effect
+	DeleteVersion(routeId int64, version int) error
+	// ApplyDueVersions puts every version whose time has come into effect
+	ApplyDueVersions() (int, error)
 }
 
 type RouteRepo interface {
@@ -20,4 +48,18 @@ type RouteRepo interface {
 	FindRoute(start, end string) (*domain.Route, error)
 	SearchByName(query string) ([]domain.Route, error)
 	SearchStops(query string) ([]string, error)
+	GetTransferPoints(walkMeters float64) ([]domain.TransferPoint, error)
+	FindNearbyStations(lat, lon, radius float64, limit int) ([]domain.NearbyStation, error)
+	MeasureLine(line domain.LineString) (float64, bool, error)
+	LocateStops(line domain.LineString, stops []domain.Stop) ([]domain.SnappedStop, error)
+	GetStation(id int64) (*domain.Station, error)
+
+	GetVersions(routeId int64) ([]domain.RouteVersion, error)
+	GetVersion(routeId int64, version int) (*domain.RouteVersion, error)
+	CreateVersion(v domain.RouteVersion) (*domain.RouteVersion, error)
+	ScheduleVersion(versionId int64, effectiveFrom time.Time) error
+	DeleteVersion(versionId int64) error
+	GetDueVersions(at time.Time) ([]int64, error)
+	ApplyVersion(versionId int64) error
+	GetTimetabledStops(routeId int64) ([]string, error)
 }
@@ -1,23 +1,45 @@
 package route
 
-import "swift_transit/domain"
+import (
+	"swift_transit/domain"
+	"swift_transit/fare"
+)
+
+// CheckThresholds sets what the route check rejects and warns about.
+type CheckThresholds struct {
+	MinLengthMeters  float64
+	StopOffsetMeters float64
+}
 
 type service struct {
-	repo RouteRepo
+	repo       RouteRepo
+	fareSvc    fare.Service
+	thresholds CheckThresholds
 }
 
-func NewService(repo RouteRepo) Service {
+func NewService(repo RouteRepo, fareSvc fare.Service, thresholds CheckThresholds) Service {
 	return &service{
-		repo: repo,
+		repo:       repo,
+		fareSvc:    fareSvc,
+		thresholds: thresholds,
 	}
 }
 
-func (svc *service) Create(route domain.Route) (*domain.Route, error) {
+func (svc *service) Create(route domain.Route) (*domain.Route, *domain.RouteCheck, error) {
+	check, err := svc.CheckRoute(route)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !check.Valid {
+		return nil, check, nil
+	}
+
+	route.Stops = check.RouteStops()
 	createdRoute, err := svc.repo.Create(route)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return createdRoute, nil
+	return createdRoute, check, nil
 }
 func (svc *service) FindAll() ([]domain.Route, error) {
 	return nil, nil
@@ -0,0 +1,32 @@
+package route
+
+import (
+	"fmt"
+	"swift_transit/domain"
+)
+
+const nearbyStationsLimit = 20
+
+func (svc *service) NearbyStations(lat, lon, radius float64) ([]domain.NearbyStation, error) {
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("invalid coordinates")
+	}
+	if radius <= 0 {
+		radius = domain.DefaultNearbyRadius
+	}
+	if radius > domain.MaxNearbyRadius {
+		radius = domain.MaxNearbyRadius
+	}
+	return svc.repo.FindNearbyStations(lat, lon, radius, nearbyStationsLimit)
+}
+
+func (svc *service) GetStation(id int64) (*domain.Station, error) {
+	station, err := svc.repo.GetStation(id)
+	if err != nil {
+		return nil, err
+	}
+	if station == nil {
+		return nil, fmt.Errorf("station not found")
+	}
+	return station, nil
+}
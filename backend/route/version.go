@@ -0,0 +1,154 @@
+package route
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"swift_transit/domain"
+	"time"
+)
+
+func (svc *service) GetVersions(routeId int64) ([]domain.RouteVersion, error) {
+	return svc.repo.GetVersions(routeId)
+}
+
+func (svc *service) GetVersion(routeId int64, version int) (*domain.RouteVersion, error) {
+	v, err := svc.repo.GetVersion(routeId, version)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("route version not found")
+	}
+	return v, nil
+}
+
+func (svc *service) CheckVersion(v domain.RouteVersion) (*domain.RouteCheck, error) {
+	check, _, err := svc.checkVersion(v)
+	return check, err
+}
+
+// checkVersion checks the version as the route it would make, and returns
+// the route name it would have.
+func (svc *service) checkVersion(v domain.RouteVersion) (*domain.RouteCheck, string, error) {
+	route, err := svc.repo.FindByID(v.RouteId)
+	if err != nil {
+		return nil, "", fmt.Errorf("route not found")
+	}
+
+	name := strings.TrimSpace(v.Name)
+	if name == "" {
+		name = route.Name
+	}
+	check, err := svc.CheckRoute(domain.Route{Id: v.RouteId, Name: name, LineStringGeoJSON: v.LineStringGeoJSON, Stops: v.Stops})
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Stops keep their identity by name; one left out would take its
+	// timetable stop times with it
+	timetabled, err := svc.repo.GetTimetabledStops(v.RouteId)
+	if err != nil {
+		return nil, "", err
+	}
+	names := make(map[string]bool, len(v.Stops))
+	for _, stop := range v.Stops {
+		names[strings.TrimSpace(stop.Name)] = true
+	}
+	for _, stopName := range timetabled {
+		if !names[stopName] {
+			check.Errors = append(check.Errors, fmt.Sprintf("stop %q has timetabled stop times; take it off the schedules first", stopName))
+		}
+	}
+	check.Valid = len(check.Errors) == 0
+	return check, name, nil
+}
+
+func (svc *service) CreateVersion(v domain.RouteVersion) (*domain.RouteVersion, *domain.RouteCheck, error) {
+	check, name, err := svc.checkVersion(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !check.Valid {
+		return nil, check, nil
+	}
+	v.Name = name
+	v.Stops = check.RouteStops()
+
+	now := time.Now()
+	if v.EffectiveFrom.IsZero() || v.EffectiveFrom.Before(now) {
+		v.EffectiveFrom = now
+	}
+	created, err := svc.repo.CreateVersion(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	if created.EffectiveFrom.After(now) {
+		return created, check, nil
+	}
+	if err := svc.repo.ApplyVersion(created.Id); err != nil {
+		// Nothing refers to a version that never took effect
+		svc.repo.DeleteVersion(created.Id)
+		return nil, nil, err
+	}
+	applied, err := svc.repo.GetVersion(created.RouteId, created.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return applied, check, nil
+}
+
+func (svc *service) ScheduleVersion(routeId int64, version int, effectiveFrom time.Time) (*domain.RouteVersion, error) {
+	v, err := svc.pendingVersion(routeId, version)
+	if err != nil {
+		return nil, err
+	}
+	if effectiveFrom.IsZero() {
+		return nil, fmt.Errorf("effective_from is required")
+	}
+	if err := svc.repo.ScheduleVersion(v.Id, effectiveFrom); err != nil {
+		return nil, err
+	}
+	if !effectiveFrom.After(time.Now()) {
+		if err := svc.repo.ApplyVersion(v.Id); err != nil {
+			return nil, err
+		}
+	}
+	return svc.repo.GetVersion(routeId, version)
+}
+
+func (svc *service) DeleteVersion(routeId int64, version int) error {
+	v, err := svc.pendingVersion(routeId, version)
+	if err != nil {
+		return err
+	}
+	return svc.repo.DeleteVersion(v.Id)
+}
+
+// pendingVersion loads a version that may still be changed.
+func (svc *service) pendingVersion(routeId int64, version int) (*domain.RouteVersion, error) {
+	v, err := svc.GetVersion(routeId, version)
+	if err != nil {
+		return nil, err
+	}
+	if v.AppliedAt != nil {
+		return nil, fmt.Errorf("version %d is already in effect; publish a new version instead", version)
+	}
+	return v, nil
+}
+
+func (svc *service) ApplyDueVersions() (int, error) {
+	ids, err := svc.repo.GetDueVersions(time.Now())
+	if err != nil {
+		return 0, err
+	}
+	applied := 0
+	for _, id := range ids {
+		if err := svc.repo.ApplyVersion(id); err != nil {
+			log.Printf("failed to apply route version %d: %v", id, err)
+			continue
+		}
+		applied++
+	}
+	return applied, nil
+}
@@ -0,0 +1,37 @@
+package route
+
+import (
+	"log"
+	"time"
+)
+
+const versionCheckInterval = time.Minute
+
+// VersionJob puts scheduled route versions into effect when their time
+// comes.
+type VersionJob struct {
+	svc Service
+}
+
+func NewVersionJob(svc Service) *VersionJob {
+	return &VersionJob{
+		svc: svc,
+	}
+}
+
+func (j *VersionJob) Start() {
+	ticker := time.NewTicker(versionCheckInterval)
+	defer ticker.Stop()
+
+	log.Printf(" [*] Route version job running every %s", versionCheckInterval)
+	for range ticker.C {
+		applied, err := j.svc.ApplyDueVersions()
+		if err != nil {
+			log.Printf("Failed to apply route versions: %v", err)
+			continue
+		}
+		if applied > 0 {
+			log.Printf("Applied %d scheduled route versions", applied)
+		}
+	}
+}
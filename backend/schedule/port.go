@@ -0,0 +1,63 @@
+package schedule
+
+import (
+	"swift_transit/domain"
+	"time"
+)
+
+// Every method takes the owner the request acts for. A zero ownerId acts
+// as an admin: it sees and edits everything, and what it creates is shared.
+// Owners edit only their own calendars and trips, may use shared calendars,
+// and schedule only routes their buses serve.
+type Service interface {
+	GetCalendars(ownerId int64) ([]domain.ServiceCalendar, error)
+	GetCalendar(ownerId, id int64) (*domain.ServiceCalendar, error)
+	CreateCalendar(ownerId int64, calendar domain.ServiceCalendar) (*domain.ServiceCalendar, error)
+	UpdateCalendar(ownerId int64, calendar domain.ServiceCalendar) (*domain.ServiceCalendar, error)
+	DeleteCalendar(ownerId, id int64) error
+	// SetException adds or replaces the calendar's exception on its date
+	SetException(ownerId int64, exception domain.CalendarException) (*domain.CalendarException, error)
+	DeleteException(ownerId, calendarId int64, date string) error
+
+	// GetTrips lists scheduled trips; a zero routeId lists every route
+	GetTrips(ownerId, routeId int64) ([]domain.ScheduledTrip, error)
+	GetTrip(ownerId, id int64) (*domain.ScheduledTrip, error)
+	CreateTrip(ownerId int64, trip domain.ScheduledTrip) (*domain.ScheduledTrip, error)
+	UpdateTrip(ownerId int64, trip domain.ScheduledTrip) (*domain.ScheduledTrip, error)
+	DeleteTrip(ownerId, id int64) error
+
+	// Timetable lists the trips of the route running on the date, given as
+	// domain.DateLayout; an empty date means today
+	Timetable(routeId int64, date string) (*domain.Timetable, error)
+	// MatchRun finds the scheduled run of the route due to leave its first
+	// stop closest to at, or nil when none is near
+	MatchRun(routeId int64, at time.Time) (*domain.ScheduledRun, error)
+	// GetRun returns the scheduled trip's run on the service date
+	GetRun(scheduledTripId int64, serviceDate string) (*domain.ScheduledRun, error)
+	// CurrentRun returns the run of the scheduled trip in service at the
+	// given time, or nil when it does not run then
+	CurrentRun(scheduledTripId int64, at time.Time) (*domain.ScheduledRun, error)
+}
+
+type ScheduleRepo interface {
+	// GetCalendars lists the owner's calendars and the shared ones; a zero
+	// ownerId lists every calendar
+	GetCalendars(ownerId int64) ([]domain.ServiceCalendar, error)
+	GetCalendar(id int64) (*domain.ServiceCalendar, error)
+	CreateCalendar(calendar domain.ServiceCalendar) (*domain.ServiceCalendar, error)
+	UpdateCalendar(calendar domain.ServiceCalendar) error
+	DeleteCalendar(id int64) error
+	SetException(exception domain.CalendarException) (*domain.CalendarException, error)
+	DeleteException(calendarId int64, date string) error
+
+	GetTrips(ownerId, routeId int64) ([]domain.ScheduledTrip, error)
+	GetTrip(id int64) (*domain.ScheduledTrip, error)
+	CreateTrip(trip domain.ScheduledTrip) (*domain.ScheduledTrip, error)
+	UpdateTrip(trip domain.ScheduledTrip) error
+	DeleteTrip(id int64) error
+
+	// GetRouteStops maps the stop order of each stop on the route to its id
+	GetRouteStops(routeId int64) (map[int]int64, error)
+	// OwnerServesRoute reports whether any of the owner's buses runs the route
+	OwnerServesRoute(ownerId, routeId int64) (bool, error)
+}
@@ -0,0 +1,435 @@
+package schedule
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"swift_transit/domain"
+	"sync"
+	"time"
+)
+
+const (
+	// A trip started this close to a scheduled departure is taken to be that
+	// run
+	matchWindow = 30 * time.Minute
+	// Runs looked up for lateness are reloaded after this long
+	runTTL = 10 * time.Minute
+)
+
+type cachedRun struct {
+	run      *domain.ScheduledRun
+	loadedAt time.Time
+}
+
+type service struct {
+	repo ScheduleRepo
+	loc  *time.Location
+
+	mu   sync.Mutex
+	runs map[string]cachedRun
+}
+
+// NewService reads service dates and stop times in the named time zone,
+// falling back to the server's own when it is unknown.
+func NewService(repo ScheduleRepo, timezone string) Service {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Printf("unknown time zone %q for schedules, using local time: %v", timezone, err)
+		loc = time.Local
+	}
+	return &service{
+		repo: repo,
+		loc:  loc,
+		runs: make(map[string]cachedRun),
+	}
+}
+
+func (s *service) GetCalendars(ownerId int64) ([]domain.ServiceCalendar, error) {
+	return s.repo.GetCalendars(ownerId)
+}
+
+func (s *service) GetCalendar(ownerId, id int64) (*domain.ServiceCalendar, error) {
+	calendar, err := s.repo.GetCalendar(id)
+	if err != nil {
+		return nil, err
+	}
+	if calendar == nil || !usable(ownerId, calendar.OwnerId) {
+		return nil, fmt.Errorf("calendar not found")
+	}
+	return calendar, nil
+}
+
+// editableCalendar loads a calendar the owner may change.
+func (s *service) editableCalendar(ownerId, id int64) (*domain.ServiceCalendar, error) {
+	calendar, err := s.GetCalendar(ownerId, id)
+	if err != nil {
+		return nil, err
+	}
+	if !editable(ownerId, calendar.OwnerId) {
+		return nil, fmt.Errorf("shared calendars can only be changed by an admin")
+	}
+	return calendar, nil
+}
+
+func (s *service) CreateCalendar(ownerId int64, calendar domain.ServiceCalendar) (*domain.ServiceCalendar, error) {
+	if err := validateCalendar(&calendar); err != nil {
+		return nil, err
+	}
+	calendar.OwnerId = ownerRef(ownerId)
+	return s.repo.CreateCalendar(calendar)
+}
+
+func (s *service) UpdateCalendar(ownerId int64, calendar domain.ServiceCalendar) (*domain.ServiceCalendar, error) {
+	existing, err := s.editableCalendar(ownerId, calendar.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCalendar(&calendar); err != nil {
+		return nil, err
+	}
+	calendar.OwnerId = existing.OwnerId
+	if err := s.repo.UpdateCalendar(calendar); err != nil {
+		return nil, err
+	}
+	s.forgetRuns()
+	return s.repo.GetCalendar(calendar.Id)
+}
+
+func (s *service) DeleteCalendar(ownerId, id int64) error {
+	if _, err := s.editableCalendar(ownerId, id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteCalendar(id); err != nil {
+		return err
+	}
+	s.forgetRuns()
+	return nil
+}
+
+func (s *service) SetException(ownerId int64, exception domain.CalendarException) (*domain.CalendarException, error) {
+	if _, err := s.editableCalendar(ownerId, exception.CalendarId); err != nil {
+		return nil, err
+	}
+	if _, err := time.Parse(domain.DateLayout, exception.Date); err != nil {
+		return nil, fmt.Errorf("date must be YYYY-MM-DD")
+	}
+	if exception.Type != domain.ExceptionAdded && exception.Type != domain.ExceptionRemoved {
+		return nil, fmt.Errorf("type must be %q or %q", domain.ExceptionAdded, domain.ExceptionRemoved)
+	}
+	exception.Note = strings.TrimSpace(exception.Note)
+	created, err := s.repo.SetException(exception)
+	if err != nil {
+		return nil, err
+	}
+	s.forgetRuns()
+	return created, nil
+}
+
+func (s *service) DeleteException(ownerId, calendarId int64, date string) error {
+	if _, err := s.editableCalendar(ownerId, calendarId); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteException(calendarId, date); err != nil {
+		return err
+	}
+	s.forgetRuns()
+	return nil
+}
+
+func (s *service) GetTrips(ownerId, routeId int64) ([]domain.ScheduledTrip, error) {
+	return s.repo.GetTrips(ownerId, routeId)
+}
+
+func (s *service) GetTrip(ownerId, id int64) (*domain.ScheduledTrip, error) {
+	trip, err := s.repo.GetTrip(id)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil || !editable(ownerId, trip.OwnerId) {
+		return nil, fmt.Errorf("scheduled trip not found")
+	}
+	return trip, nil
+}
+
+func (s *service) CreateTrip(ownerId int64, trip domain.ScheduledTrip) (*domain.ScheduledTrip, error) {
+	if err := s.validateTrip(ownerId, &trip); err != nil {
+		return nil, err
+	}
+	trip.OwnerId = ownerRef(ownerId)
+	return s.repo.CreateTrip(trip)
+}
+
+func (s *service) UpdateTrip(ownerId int64, trip domain.ScheduledTrip) (*domain.ScheduledTrip, error) {
+	existing, err := s.GetTrip(ownerId, trip.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateTrip(ownerId, &trip); err != nil {
+		return nil, err
+	}
+	trip.OwnerId = existing.OwnerId
+	if err := s.repo.UpdateTrip(trip); err != nil {
+		return nil, err
+	}
+	s.forgetRuns()
+	return s.repo.GetTrip(trip.Id)
+}
+
+func (s *service) DeleteTrip(ownerId, id int64) error {
+	if _, err := s.GetTrip(ownerId, id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteTrip(id); err != nil {
+		return err
+	}
+	s.forgetRuns()
+	return nil
+}
+
+func (s *service) Timetable(routeId int64, date string) (*domain.Timetable, error) {
+	if date == "" {
+		date = time.Now().In(s.loc).Format(domain.DateLayout)
+	}
+	if _, err := time.Parse(domain.DateLayout, date); err != nil {
+		return nil, fmt.Errorf("date must be YYYY-MM-DD")
+	}
+	trips, calendars, err := s.routeTrips(routeId)
+	if err != nil {
+		return nil, err
+	}
+
+	timetable := &domain.Timetable{RouteId: routeId, Date: date, Trips: []domain.ScheduledTrip{}}
+	for _, trip := range trips {
+		if calendar, ok := calendars[trip.CalendarId]; ok && calendar.RunsOn(date) {
+			timetable.Trips = append(timetable.Trips, trip)
+		}
+	}
+	sort.SliceStable(timetable.Trips, func(i, j int) bool {
+		return timetable.Trips[i].StopTimes[0].Departure < timetable.Trips[j].StopTimes[0].Departure
+	})
+	return timetable, nil
+}
+
+func (s *service) MatchRun(routeId int64, at time.Time) (*domain.ScheduledRun, error) {
+	trips, calendars, err := s.routeTrips(routeId)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *domain.ScheduledRun
+	bestGap := matchWindow + 1
+	// A run after midnight belongs to the previous service day
+	for _, day := range s.serviceDays(at) {
+		date := day.Format(domain.DateLayout)
+		for _, trip := range trips {
+			calendar, ok := calendars[trip.CalendarId]
+			if !ok || !calendar.RunsOn(date) {
+				continue
+			}
+			gap := at.Sub(trip.StopTimes[0].Departure.On(day)).Abs()
+			if gap <= matchWindow && gap < bestGap {
+				best, bestGap = newRun(trip, day), gap
+			}
+		}
+	}
+	return best, nil
+}
+
+func (s *service) GetRun(scheduledTripId int64, serviceDate string) (*domain.ScheduledRun, error) {
+	key := fmt.Sprintf("%d:%s", scheduledTripId, serviceDate)
+	s.mu.Lock()
+	cached, ok := s.runs[key]
+	s.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < runTTL {
+		return cached.run, nil
+	}
+
+	day, err := time.ParseInLocation(domain.DateLayout, serviceDate, s.loc)
+	if err != nil {
+		return nil, err
+	}
+	trip, err := s.repo.GetTrip(scheduledTripId)
+	if err != nil {
+		return nil, err
+	}
+	var run *domain.ScheduledRun
+	if trip != nil && len(trip.StopTimes) > 0 {
+		run = newRun(*trip, day)
+	}
+
+	s.mu.Lock()
+	s.runs[key] = cachedRun{run: run, loadedAt: time.Now()}
+	s.mu.Unlock()
+	return run, nil
+}
+
+func (s *service) CurrentRun(scheduledTripId int64, at time.Time) (*domain.ScheduledRun, error) {
+	trip, err := s.repo.GetTrip(scheduledTripId)
+	if err != nil || trip == nil || len(trip.StopTimes) == 0 {
+		return nil, err
+	}
+	calendar, err := s.repo.GetCalendar(trip.CalendarId)
+	if err != nil || calendar == nil {
+		return nil, err
+	}
+
+	// Yesterday's run is current only while it is still under way
+	days := s.serviceDays(at)
+	if yesterday := days[1]; calendar.RunsOn(yesterday.Format(domain.DateLayout)) &&
+		at.Before(trip.StopTimes[len(trip.StopTimes)-1].Arrival.On(yesterday).Add(matchWindow)) {
+		return newRun(*trip, yesterday), nil
+	}
+	if today := days[0]; calendar.RunsOn(today.Format(domain.DateLayout)) {
+		return newRun(*trip, today), nil
+	}
+	return nil, nil
+}
+
+// serviceDays returns midnight of the day at falls on and of the day
+// before, in the schedule's time zone.
+func (s *service) serviceDays(at time.Time) []time.Time {
+	y, m, d := at.In(s.loc).Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, s.loc)
+	return []time.Time{today, today.AddDate(0, 0, -1)}
+}
+
+// routeTrips loads the route's scheduled trips and the calendars they use.
+func (s *service) routeTrips(routeId int64) ([]domain.ScheduledTrip, map[int64]domain.ServiceCalendar, error) {
+	trips, err := s.repo.GetTrips(0, routeId)
+	if err != nil {
+		return nil, nil, err
+	}
+	calendars := make(map[int64]domain.ServiceCalendar)
+	for _, trip := range trips {
+		if _, ok := calendars[trip.CalendarId]; ok {
+			continue
+		}
+		calendar, err := s.repo.GetCalendar(trip.CalendarId)
+		if err != nil {
+			return nil, nil, err
+		}
+		if calendar != nil {
+			calendars[calendar.Id] = *calendar
+		}
+	}
+
+	// Trips always have stop times; skip any left without so callers can
+	// rely on the first one
+	withTimes := trips[:0]
+	for _, trip := range trips {
+		if len(trip.StopTimes) > 0 {
+			withTimes = append(withTimes, trip)
+		}
+	}
+	return withTimes, calendars, nil
+}
+
+// forgetRuns drops cached runs after any schedule change.
+func (s *service) forgetRuns() {
+	s.mu.Lock()
+	s.runs = make(map[string]cachedRun)
+	s.mu.Unlock()
+}
+
+func (s *service) validateTrip(ownerId int64, trip *domain.ScheduledTrip) error {
+	if trip.RouteId == 0 || trip.CalendarId == 0 {
+		return fmt.Errorf("route_id and calendar_id are required")
+	}
+	if len(trip.StopTimes) < 2 {
+		return fmt.Errorf("a scheduled trip needs times for at least two stops")
+	}
+	if ownerId != 0 {
+		ok, err := s.repo.OwnerServesRoute(ownerId, trip.RouteId)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("none of your buses serve this route")
+		}
+	}
+	if _, err := s.GetCalendar(ownerId, trip.CalendarId); err != nil {
+		return err
+	}
+
+	stops, err := s.repo.GetRouteStops(trip.RouteId)
+	if err != nil {
+		return err
+	}
+	sort.Slice(trip.StopTimes, func(i, j int) bool { return trip.StopTimes[i].StopOrder < trip.StopTimes[j].StopOrder })
+	for i := range trip.StopTimes {
+		st := &trip.StopTimes[i]
+		stopId, ok := stops[st.StopOrder]
+		if !ok {
+			return fmt.Errorf("route has no stop with order %d", st.StopOrder)
+		}
+		st.StopId = stopId
+		if st.Departure == 0 {
+			st.Departure = st.Arrival
+		}
+		if st.Departure < st.Arrival {
+			return fmt.Errorf("departure from stop %d is before its arrival", st.StopOrder)
+		}
+		if i > 0 {
+			prev := trip.StopTimes[i-1]
+			if st.StopOrder == prev.StopOrder {
+				return fmt.Errorf("stop %d is listed twice", st.StopOrder)
+			}
+			if st.Arrival < prev.Departure {
+				return fmt.Errorf("arrival at stop %d is before departure from stop %d", st.StopOrder, prev.StopOrder)
+			}
+		}
+	}
+	trip.Headsign = strings.TrimSpace(trip.Headsign)
+	return nil
+}
+
+func validateCalendar(calendar *domain.ServiceCalendar) error {
+	calendar.Name = strings.TrimSpace(calendar.Name)
+	if calendar.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	start, err := time.Parse(domain.DateLayout, calendar.StartDate)
+	if err != nil {
+		return fmt.Errorf("start_date must be YYYY-MM-DD")
+	}
+	end, err := time.Parse(domain.DateLayout, calendar.EndDate)
+	if err != nil {
+		return fmt.Errorf("end_date must be YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return fmt.Errorf("end_date is before start_date")
+	}
+	return nil
+}
+
+func newRun(trip domain.ScheduledTrip, day time.Time) *domain.ScheduledRun {
+	run := &domain.ScheduledRun{
+		ScheduledTripId: trip.Id,
+		RouteId:         trip.RouteId,
+		ServiceDate:     day.Format(domain.DateLayout),
+		Arrivals:        make(map[int]time.Time, len(trip.StopTimes)),
+	}
+	for _, st := range trip.StopTimes {
+		run.Arrivals[st.StopOrder] = st.Arrival.On(day)
+	}
+	return run
+}
+
+func ownerRef(ownerId int64) *int64 {
+	if ownerId == 0 {
+		return nil
+	}
+	return &ownerId
+}
+
+// usable reports whether the owner may see or build on something owned by
+// owner; shared things are usable by everyone.
+func usable(ownerId int64, owner *int64) bool {
+	return ownerId == 0 || owner == nil || *owner == ownerId
+}
+
+func editable(ownerId int64, owner *int64) bool {
+	return ownerId == 0 || (owner != nil && *owner == ownerId)
+}
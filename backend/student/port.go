@@ -0,0 +1,26 @@
+package student
+
+import (
+	"swift_transit/domain"
+	"time"
+)
+
+type Service interface {
+	Submit(userId int64, institutionId string, expiresAt time.Time) (*domain.StudentVerification, error)
+	GetStatus(userId int64) (*domain.StudentVerification, error)
+	List(status string, page, pageSize int) ([]domain.StudentVerification, int, error)
+	Approve(id, adminId int64, note string) (*domain.StudentVerification, error)
+	Reject(id, adminId int64, note string) (*domain.StudentVerification, error)
+}
+
+type StudentRepo interface {
+	Create(verification domain.StudentVerification) (*domain.StudentVerification, error)
+	GetByID(id int64) (*domain.StudentVerification, error)
+	GetLatestByUser(userId int64) (*domain.StudentVerification, error)
+	HasPending(userId int64) (bool, error)
+	List(status string, limit, offset int) ([]domain.StudentVerification, int, error)
+	// Approve marks the request approved and sets the user's
+	// student_verified_until in the same transaction.
+	Approve(id, adminId int64, note string) error
+	Reject(id, adminId int64, note string) error
+}
@@ -0,0 +1,108 @@
+package student
+
+import (
+	"fmt"
+	"strings"
+	"swift_transit/domain"
+	"swift_transit/utils"
+	"time"
+)
+
+type service struct {
+	repo StudentRepo
+}
+
+func NewService(repo StudentRepo) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+func (svc *service) Submit(userId int64, institutionId string, expiresAt time.Time) (*domain.StudentVerification, error) {
+	institutionId = strings.TrimSpace(institutionId)
+	if institutionId == "" {
+		return nil, fmt.Errorf("institution_id is required")
+	}
+	if !expiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("expires_at must be in the future")
+	}
+
+	pending, err := svc.repo.HasPending(userId)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, fmt.Errorf("a verification request is already pending")
+	}
+
+	return svc.repo.Create(domain.StudentVerification{
+		UserId:        userId,
+		InstitutionId: institutionId,
+		ExpiresAt:     expiresAt,
+		Status:        domain.StudentVerificationPending,
+	})
+}
+
+func (svc *service) GetStatus(userId int64) (*domain.StudentVerification, error) {
+	return svc.repo.GetLatestByUser(userId)
+}
+
+func (svc *service) List(status string, page, pageSize int) ([]domain.StudentVerification, int, error) {
+	offset := (page - 1) * pageSize
+	return svc.repo.List(strings.ToUpper(status), pageSize, offset)
+}
+
+func (svc *service) Approve(id, adminId int64, note string) (*domain.StudentVerification, error) {
+	v, err := svc.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if v.Status != domain.StudentVerificationPending {
+		return nil, fmt.Errorf("verification is already %s", strings.ToLower(v.Status))
+	}
+	if !v.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("student ID expired on %s", v.ExpiresAt.Format("2006-01-02"))
+	}
+
+	if err := svc.repo.Approve(id, adminId, note); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Your student status has been verified. Student fares apply until %s.", v.ExpiresAt.Format("02 Jan 2006"))
+	return svc.decided(id, "Swift Transit Student Verification Approved", message, note)
+}
+
+func (svc *service) Reject(id, adminId int64, note string) (*domain.StudentVerification, error) {
+	v, err := svc.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if v.Status != domain.StudentVerificationPending {
+		return nil, fmt.Errorf("verification is already %s", strings.ToLower(v.Status))
+	}
+
+	if err := svc.repo.Reject(id, adminId, note); err != nil {
+		return nil, err
+	}
+
+	message := "Your student verification request was not approved. You can submit a new request with a valid institution ID."
+	return svc.decided(id, "Swift Transit Student Verification Rejected", message, note)
+}
+
+// decided reloads the reviewed request and notifies the user. The decision is
+// already stored, so a failed email is only logged.
+func (svc *service) decided(id int64, subject, message, note string) (*domain.StudentVerification, error) {
+	v, err := svc.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.UserEmail != "" {
+		body := utils.GetStudentVerificationEmailBody(v.UserName, message, note)
+		if err := utils.SendEmail(v.UserEmail, subject, body); err != nil {
+			fmt.Printf("Failed to send student verification email to %s: %v\n", v.UserEmail, err)
+		}
+	}
+
+	return v, nil
+}
@@ -0,0 +1,38 @@
+package ticket
+
+import (
+	"math"
+	"time"
+)
+
+// applyFareCap works out how much of an RFID fare the rider still has to pay
+// once today's and this week's spend are taken into account. The spend is read
+// from ticket history every time, so no cap state has to be kept elsewhere.
+func (s *service) applyFareCap(userID int64, fare float64, now time.Time) (charge float64, capped float64, err error) {
+	charge = fare
+
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	// Weeks start on Monday
+	weekStart := dayStart.AddDate(0, 0, -((int(dayStart.Weekday()) + 6) % 7))
+
+	periods := []struct {
+		cap   float64
+		since time.Time
+	}{
+		{s.fareCaps.Daily, dayStart},
+		{s.fareCaps.Weekly, weekStart},
+	}
+	for _, p := range periods {
+		if p.cap <= 0 {
+			continue
+		}
+		spent, err := s.repo.GetRFIDSpend(userID, p.since)
+		if err != nil {
+			return 0, 0, err
+		}
+		charge = math.Min(charge, math.Max(p.cap-spent, 0))
+	}
+
+	charge = math.Round(charge*100) / 100
+	return charge, fare - charge, nil
+}
@@ -0,0 +1,186 @@
+package ticket
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"swift_transit/domain"
+	"swift_transit/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *service) BuyJourney(req BuyJourneyRequest) (*BuyJourneyResponse, error) {
+	if req.UserId == 0 {
+		return nil, fmt.Errorf("invalid request")
+	}
+	if len(req.Legs) < 2 || len(req.Legs) > 4 {
+		return nil, fmt.Errorf("a journey needs between 2 and 4 legs")
+	}
+	for i, leg := range req.Legs {
+		if leg.RouteId == 0 || leg.StartDestination == "" || leg.EndDestination == "" {
+			return nil, fmt.Errorf("invalid leg %d", i+1)
+		}
+		if i > 0 && leg.RouteId == req.Legs[i-1].RouteId {
+			return nil, fmt.Errorf("consecutive legs must be on different routes")
+		}
+	}
+	if req.PaymentMethod != "wallet" && req.PaymentMethod != "gateway" {
+		return nil, fmt.Errorf("invalid payment method")
+	}
+
+	// Price every leg; legs after the first get the transfer discount
+	batchID := uuid.New().String()
+	paymentRef := fmt.Sprintf("TICKET-%s", uuid.New().String()[:8])
+	now := time.Now().Format(time.RFC3339)
+	legs := make([]domain.Ticket, len(req.Legs))
+	total := 0.0
+	for i, leg := range req.Legs {
+		quote, err := s.quoteFare(req.UserId, leg.RouteId, leg.StartDestination, leg.EndDestination)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate fare for leg %d: %w", i+1, err)
+		}
+
+		transferDiscount := 0.0
+		if i > 0 {
+			transferDiscount = math.Round(quote.Fare*s.transfer.DiscountPercent) / 100
+		}
+		legIndex := i
+		legs[i] = domain.Ticket{
+			UserId:           req.UserId,
+			RouteId:          leg.RouteId,
+			BusName:          leg.BusName,
+			StartDestination: leg.StartDestination,
+			EndDestination:   leg.EndDestination,
+			Fare:             quote.Fare - transferDiscount,
+			Discount:         quote.Discount,
+			TransferDiscount: transferDiscount,
+			QRCode:           uuid.New().String(),
+			CreatedAt:        now,
+			BatchID:          batchID,
+			PaymentMethod:    req.PaymentMethod,
+			PaymentReference: paymentRef,
+			PaymentStatus:    "pending",
+			LegIndex:         &legIndex,
+		}
+		total += legs[i].Fare
+	}
+	total = math.Round(total*100) / 100
+
+	if req.PaymentMethod == "wallet" {
+		if err := s.userRepo.DeductBalance(req.UserId, total); err != nil {
+			return nil, fmt.Errorf("payment failed: %w", err)
+		}
+	}
+
+	for i := range legs {
+		if req.PaymentMethod == "wallet" {
+			legs[i].PaidStatus = true
+			legs[i].PaymentStatus = "paid"
+			legs[i].PaymentUsed = true
+		}
+	}
+
+	// The journey and its legs are written together, so the wallet is only
+	// refunded when none of them was saved
+	journey, err := s.repo.CreateMultiLegJourney(domain.MultiLegJourney{
+		UserId:                  req.UserId,
+		TotalFare:               total,
+		TransferDiscountPercent: s.transfer.DiscountPercent,
+		TransferWindowMinutes:   int(s.transfer.Window / time.Minute),
+		Legs:                    legs,
+	})
+	if err != nil {
+		if req.PaymentMethod == "wallet" {
+			s.userRepo.CreditBalance(req.UserId, total)
+		}
+		return nil, fmt.Errorf("failed to create journey: %w", err)
+	}
+	legs = journey.Legs
+
+	if req.PaymentMethod == "wallet" {
+		transferTotal := 0.0
+		for _, leg := range legs {
+			transferTotal += leg.TransferDiscount
+		}
+		s.CreateTransaction(model.Transaction{
+			UserID:        int(req.UserId),
+			Amount:        total,
+			Type:          "purchase",
+			Description:   fmt.Sprintf("Journey Ticket - %d legs - transfer discount %.2f", len(legs), transferTotal),
+			PaymentMethod: "Swift Balance",
+			CreatedAt:     time.Now(),
+		})
+
+		for _, leg := range legs {
+			s.storeValidLeg(leg)
+		}
+
+		return &BuyJourneyResponse{Journey: journey, Message: "Journey purchased successfully"}, nil
+	}
+
+	// One gateway payment covers the whole batch; the existing ticket payment
+	// callbacks mark every leg paid.
+	firstID := legs[0].Id
+	tranID := fmt.Sprintf("TICKET-%d-%s", firstID, batchID[:8])
+	successUrl := fmt.Sprintf("%s/ticket/payment/success?id=%d", s.publicBaseURL, firstID)
+	failUrl := fmt.Sprintf("%s/ticket/payment/fail?id=%d", s.publicBaseURL, firstID)
+	cancelUrl := fmt.Sprintf("%s/ticket/payment/cancel?id=%d", s.publicBaseURL, firstID)
+
+	gatewayUrl, err := s.sslCommerz.InitPayment(total, tranID, successUrl, failUrl, cancelUrl)
+	if err != nil {
+		s.repo.UpdateBatchPaymentStatus(batchID, false, "failed", true)
+		return nil, fmt.Errorf("failed to initiate payment: %w", err)
+	}
+
+	return &BuyJourneyResponse{Journey: journey, PaymentURL: gatewayUrl, Message: "Complete the payment to activate your journey"}, nil
+}
+
+func (s *service) GetJourney(userID int64, journeyID int64) (*domain.MultiLegJourney, error) {
+	journey, err := s.repo.GetMultiLegJourney(journeyID)
+	if err != nil {
+		return nil, err
+	}
+	if journey.UserId != userID {
+		return nil, fmt.Errorf("journey not found")
+	}
+	return journey, nil
+}
+
+func (s *service) storeValidLeg(leg domain.Ticket) {
+	validTicket := map[string]interface{}{
+		"ticket_id":         leg.Id,
+		"route_id":          leg.RouteId,
+		"start_destination": leg.StartDestination,
+		"end_destination":   leg.EndDestination,
+		"user_id":           leg.UserId,
+		"created_at":        leg.CreatedAt,
+		"checked":           false,
+		"journey_id":        leg.JourneyId,
+		"leg_index":         leg.LegIndex,
+	}
+	validTicketJSON, _ := json.Marshal(validTicket)
+	s.redis.Set(s.ctx, fmt.Sprintf("ticket_valid:%s", leg.QRCode), validTicketJSON, 4*time.Hour)
+}
+
+// CheckJourneyLeg refuses a journey leg until the previous leg has been
+// checked, and after the journey's transfer window since that check has
+// passed. Single tickets and first legs always pass.
+func CheckJourneyLeg(repo TicketRepo, t *domain.Ticket) error {
+	if t.JourneyId == nil || t.LegIndex == nil || *t.LegIndex == 0 {
+		return nil
+	}
+
+	checked, open, err := repo.PreviousLegStatus(*t.JourneyId, *t.LegIndex)
+	if err != nil {
+		return fmt.Errorf("failed to verify previous leg: %w", err)
+	}
+	if !checked {
+		return fmt.Errorf("previous leg of this journey has not been checked")
+	}
+	if !open {
+		return fmt.Errorf("transfer window for this journey has expired")
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package ticket
+
+import (
+	"log"
+	"time"
+)
+
+const (
+	// Journeys still open after this long are charged the held maximum fare
+	journeyMaxDuration   = 3 * time.Hour
+	journeyCheckInterval = 5 * time.Minute
+)
+
+type JourneyTimeoutJob struct {
+	svc Service
+}
+
+func NewJourneyTimeoutJob(svc Service) *JourneyTimeoutJob {
+	return &JourneyTimeoutJob{
+		svc: svc,
+	}
+}
+
+func (j *JourneyTimeoutJob) Start() {
+	ticker := time.NewTicker(journeyCheckInterval)
+	defer ticker.Stop()
+
+	log.Printf(" [*] Journey timeout job running every %s", journeyCheckInterval)
+	for range ticker.C {
+		closed, err := j.svc.CloseStaleJourneys(journeyMaxDuration)
+		if err != nil {
+			log.Printf("Failed to close stale journeys: %v", err)
+			continue
+		}
+		if closed > 0 {
+			log.Printf("Closed %d journeys without tap-off at maximum fare", closed)
+		}
+	}
+}
@@ -17,6 +17,40 @@ type BuyTicketRequest struct {
 	Quantity         int    `json:"quantity"`
 }
 
+type JourneyLegRequest struct {
+	RouteId          int64  `json:"route_id"`
+	BusName          string `json:"bus_name"`
+	StartDestination string `json:"start_destination"`
+	EndDestination   string `json:"end_destination"`
+}
+
+// BuyJourneyRequest buys one ticket per leg under a single payment.
+type BuyJourneyRequest struct {
+	UserId        int64               `json:"-"` // Extracted from JWT
+	Legs          []JourneyLegRequest `json:"legs"`
+	PaymentMethod string              `json:"payment_method"` // "wallet" or "gateway"
+}
+
+type BuyJourneyResponse struct {
+	Journey    *domain.MultiLegJourney `json:"journey"`
+	PaymentURL string                  `json:"payment_url,omitempty"`
+	Message    string                  `json:"message"`
+}
+
+// TransferPolicy is the discount on every leg after the first and how long
+// after the previous leg is checked the next one may be boarded.
+type TransferPolicy struct {
+	DiscountPercent float64
+	Window          time.Duration
+}
+
+// Occupancy counts riders on board a bus, identified by its registration
+// number; see trip.Service.
+type Occupancy interface {
+	Board(registrationNumber, rider, alightStop string) error
+	Alight(registrationNumber, rider string) error
+}
+
 type TicketRequestMessage struct {
 	UserId           int64   `json:"user_id"`
 	RouteId          int64   `json:"route_id"`
@@ -24,6 +58,7 @@ type TicketRequestMessage struct {
 	StartDestination string  `json:"start_destination"`
 	EndDestination   string  `json:"end_destination"`
 	Fare             float64 `json:"fare"`
+	Discount         float64 `json:"discount"`
 	TotalFare        float64 `json:"total_fare"`
 	Quantity         int     `json:"quantity"`
 	BatchID          string  `json:"batch_id"`
@@ -66,38 +101,83 @@ type Service interface {
 	CreateTransaction(t model.Transaction) error
 	CheckTicket(req CheckTicketRequest) (map[string]interface{}, error)
 	ProcessRFIDPayment(req RFIDPaymentRequest) (*RFIDPaymentResponse, error)
+	TapOn(req RFIDTapRequest) (*RFIDPaymentResponse, error)
+	TapOff(req RFIDTapRequest) (*RFIDPaymentResponse, error)
+	CloseStaleJourneys(maxAge time.Duration) (int, error)
+	BuyJourney(req BuyJourneyRequest) (*BuyJourneyResponse, error)
+	GetJourney(userID int64, journeyID int64) (*domain.MultiLegJourney, error)
 	CreateOverTravelTicket(originalTicketID int64, currentStop string, paymentCollected bool) (*domain.Ticket, error)
 }
 
 type RFIDPaymentRequest struct {
 	RFID             string `json:"rfid"`
 	RouteID          int64  `json:"route_id"`
-	BusName          string `json:"bus_name"`
+	BusName          string `json:"bus_name"` // the reader's bus registration number
 	StartDestination string `json:"start_destination"`
 	EndDestination   string `json:"end_destination"`
 }
 
+// RFIDTapRequest is sent by the card reader on boarding and alighting; the
+// rider never states a destination.
+type RFIDTapRequest struct {
+	RFID    string `json:"rfid"`
+	RouteID int64  `json:"route_id"`
+	BusName string `json:"bus_name"` // the reader's bus registration number
+	Stop    string `json:"stop"`
+}
+
 type RFIDPaymentResponse struct {
-	Success  bool    `json:"success"`
-	Status   string  `json:"status"` // SUCCESS, DUPLICATE, INACTIVE, INSUFFICIENT_BALANCE
-	Message  string  `json:"message"`
-	Balance  float64 `json:"balance"`
-	Fare     float64 `json:"fare"`
-	TicketID int64   `json:"ticket_id,omitempty"`
+	Success      bool    `json:"success"`
+	Status       string  `json:"status"` // SUCCESS, CAPPED, PASS, TAPPED_ON, NO_JOURNEY, DUPLICATE, INACTIVE, INSUFFICIENT_BALANCE
+	Message      string  `json:"message"`
+	Balance      float64 `json:"balance"`
+	Fare         float64 `json:"fare"`
+	Discount     float64 `json:"discount,omitempty"`
+	CappedAmount float64 `json:"capped_amount,omitempty"`
+	TicketID     int64   `json:"ticket_id,omitempty"`
+	PassID       int64   `json:"pass_id,omitempty"`
+	JourneyID    int64   `json:"journey_id,omitempty"`
+	HoldAmount   float64 `json:"hold_amount,omitempty"`
+	Refunded     float64 `json:"refunded,omitempty"`
+}
+
+// FareCaps bounds RFID spend per rider; zero disables a cap.
+type FareCaps struct {
+	Daily  float64
+	Weekly float64
 }
 
 type TicketRepo interface {
 	Create(ticket domain.Ticket) (*domain.Ticket, error)
 	UpdateStatus(id int64, status bool) error
 	Get(id int64) (*domain.Ticket, error)
-	CalculateFare(routeId int64, start, end string) (float64, error)
 	GetByUserID(userId int64, limit, offset int) ([]domain.Ticket, int, error)
 	ValidateTicket(id int64, busName string) error
 	CountActiveTicketsByRoute(userId int64, routeId int64) (int, error)
 	UpdateBatchPaymentStatus(batchID string, paid bool, status string, markUsed bool) error
 	CancelTicket(id int64, cancelledAt time.Time, status string) error
 	GetBatchCount(batchID string) (int, error)
-	GetStop(routeId int64, stopName string) (*domain.Stop, error)
+	// GetStop finds the stop on the given route version; 0 is the route as
+	// it is now
+	GetStop(routeId int64, stopName string, versionId int64) (*domain.Stop, error)
 	GetByQRCode(qrCode string) (*domain.Ticket, error)
 	GetLatestTicket(userId int64, routeId int64) (*domain.Ticket, error)
+	GetRFIDSpend(userId int64, since time.Time) (float64, error)
+	GetRouteTermini(routeId int64) (string, string, error)
+
+	// Tap-on/tap-off journeys
+	CreateJourney(journey domain.RFIDJourney) (*domain.RFIDJourney, error)
+	GetOpenJourney(userId int64) (*domain.RFIDJourney, error)
+	// SettleJourney closes the open journey, charges it against its hold and
+	// writes its ticket in one go. It returns a nil ticket when the journey
+	// was already closed, and the amount given back from the hold.
+	SettleJourney(journey domain.RFIDJourney, status string, endStop *string, closedAt time.Time, ticket domain.Ticket) (*domain.Ticket, float64, error)
+	GetStaleJourneys(before time.Time) ([]domain.RFIDJourney, error)
+
+	// Multi-leg journeys
+	// CreateMultiLegJourney saves the journey together with its legs
+	CreateMultiLegJourney(journey domain.MultiLegJourney) (*domain.MultiLegJourney, error)
+	GetMultiLegJourney(id int64) (*domain.MultiLegJourney, error)
+	PreviousLegStatus(journeyId int64, legIndex int) (bool, bool, error)
+	GetBatchTotal(batchID string) (float64, error)
 }
@@ -0,0 +1,275 @@
+package ticket
+
+import (
+	"fmt"
+	"log"
+	"swift_transit/domain"
+	"swift_transit/model"
+	"swift_transit/pass"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TapOn starts a journey. Since the destination is unknown, the maximum fare
+// for the route is held from the wallet until the rider taps off.
+func (s *service) TapOn(req RFIDTapRequest) (*RFIDPaymentResponse, error) {
+	user, err := s.userRepo.FindByRFID(req.RFID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RFID card")
+	}
+
+	if !user.IsRFIDActive {
+		return &RFIDPaymentResponse{
+			Success: false,
+			Status:  "INACTIVE",
+			Message: "RFID card is inactive",
+			Balance: float64(user.Balance),
+		}, nil
+	}
+
+	now := time.Now()
+
+	// A valid travel pass needs no hold
+	activePass, err := s.passSvc.FindActivePass(user.Id, req.RouteID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check travel pass: %w", err)
+	}
+	if activePass != nil {
+		boarded, err := s.passSvc.Board(activePass, pass.BoardRequest{
+			RouteId:            req.RouteID,
+			RegistrationNumber: req.BusName,
+			Stop:               req.Stop,
+			Source:             "RFID",
+		})
+		if err != nil {
+			return nil, err
+		}
+		status, message := "PASS", "Travel pass accepted"
+		if !boarded {
+			status, message = "DUPLICATE", "Already boarded with pass"
+		}
+		return &RFIDPaymentResponse{
+			Success: true,
+			Status:  status,
+			Message: message,
+			Balance: float64(user.Balance),
+			PassID:  activePass.Id,
+		}, nil
+	}
+
+	open, err := s.repo.GetOpenJourney(user.Id)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		if open.BusName == req.BusName && open.RouteId == req.RouteID && now.Sub(open.TappedOnAt) < 5*time.Minute {
+			return &RFIDPaymentResponse{
+				Success:    true,
+				Status:     "DUPLICATE",
+				Message:    "Already tapped on",
+				Balance:    float64(user.Balance),
+				JourneyID:  open.Id,
+				HoldAmount: open.HoldAmount,
+			}, nil
+		}
+		// The rider never tapped off the previous journey
+		if _, err := s.settleJourney(open, nil, open.HoldAmount, open.HoldDiscount, domain.JourneyTimedOut); err != nil {
+			return nil, fmt.Errorf("failed to close previous journey: %w", err)
+		}
+		if user, err = s.userRepo.FindByRFID(req.RFID); err != nil {
+			return nil, err
+		}
+	}
+
+	first, last, err := s.repo.GetRouteTermini(req.RouteID)
+	if err != nil {
+		return nil, fmt.Errorf("route has no stops")
+	}
+	maxQuote, err := s.fareSvc.QuoteFare(req.RouteID, first, last, user, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate fare: %w", err)
+	}
+	hold := maxQuote.Fare
+
+	if float64(user.Balance) < hold {
+		return &RFIDPaymentResponse{
+			Success:    false,
+			Status:     "INSUFFICIENT_BALANCE",
+			Message:    "Insufficient balance for the maximum fare",
+			Balance:    float64(user.Balance),
+			HoldAmount: hold,
+		}, nil
+	}
+
+	if err := s.userRepo.DeductBalance(user.Id, hold); err != nil {
+		return nil, fmt.Errorf("failed to place hold: %w", err)
+	}
+
+	journey, err := s.repo.CreateJourney(domain.RFIDJourney{
+		UserId:       user.Id,
+		RouteId:      req.RouteID,
+		BusName:      req.BusName,
+		StartStop:    req.Stop,
+		HoldAmount:   hold,
+		HoldDiscount: maxQuote.Discount,
+		TappedOnAt:   now,
+	})
+	if err != nil {
+		s.userRepo.CreditBalance(user.Id, hold)
+		return nil, fmt.Errorf("failed to start journey: %w", err)
+	}
+
+	// The destination is only known at tap off, which lets the rider off
+	s.board(req.BusName, journeyRider(journey.Id), "")
+
+	return &RFIDPaymentResponse{
+		Success:    true,
+		Status:     "TAPPED_ON",
+		Message:    "Journey started",
+		Balance:    float64(user.Balance) - hold,
+		JourneyID:  journey.Id,
+		HoldAmount: hold,
+	}, nil
+}
+
+// TapOff settles the open journey for the distance actually travelled and
+// refunds the rest of the hold.
+func (s *service) TapOff(req RFIDTapRequest) (*RFIDPaymentResponse, error) {
+	user, err := s.userRepo.FindByRFID(req.RFID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RFID card")
+	}
+
+	journey, err := s.repo.GetOpenJourney(user.Id)
+	if err != nil {
+		return nil, err
+	}
+	if journey == nil {
+		return &RFIDPaymentResponse{
+			Success: false,
+			Status:  "NO_JOURNEY",
+			Message: "No open journey for this card",
+			Balance: float64(user.Balance),
+		}, nil
+	}
+
+	// Priced as of tap-on, on the route version the journey began on
+	quote, err := s.fareSvc.QuoteFare(journey.RouteId, journey.StartStop, req.Stop, user, journey.TappedOnAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate fare: %w", err)
+	}
+
+	endStop := req.Stop
+	return s.settleJourney(journey, &endStop, quote.Fare, quote.Discount, domain.JourneyClosed)
+}
+
+func journeyRider(journeyID int64) string {
+	return fmt.Sprintf("rfid_journey:%d", journeyID)
+}
+
+// CloseStaleJourneys charges the held maximum fare for journeys that were
+// never tapped off.
+func (s *service) CloseStaleJourneys(maxAge time.Duration) (int, error) {
+	journeys, err := s.repo.GetStaleJourneys(time.Now().Add(-maxAge))
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for i := range journeys {
+		if _, err := s.settleJourney(&journeys[i], nil, journeys[i].HoldAmount, journeys[i].HoldDiscount, domain.JourneyTimedOut); err != nil {
+			fmt.Printf("Failed to close journey %d: %v\n", journeys[i].Id, err)
+			continue
+		}
+		closed++
+	}
+	return closed, nil
+}
+
+// settleJourney closes the journey, charges the fare (after caps) against the
+// hold, writes the ticket and transaction for the trip and lets the rider off
+// the bus.
+func (s *service) settleJourney(j *domain.RFIDJourney, endStop *string, fare, discount float64, status string) (*RFIDPaymentResponse, error) {
+	now := time.Now()
+	charge, capped, err := s.applyFareCap(j.UserId, fare, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check fare cap: %w", err)
+	}
+
+	paymentStatus := "paid"
+	if capped > 0 && charge == 0 {
+		paymentStatus = domain.TicketStatusCapped
+	}
+	end := ""
+	if endStop != nil {
+		end = *endStop
+	}
+
+	ticket, refund, err := s.repo.SettleJourney(*j, status, endStop, now, domain.Ticket{
+		UserId:           j.UserId,
+		RouteId:          j.RouteId,
+		BusName:          j.BusName,
+		StartDestination: j.StartStop,
+		EndDestination:   end,
+		Fare:             charge,
+		Discount:         discount,
+		PaymentStatus:    paymentStatus,
+		PaidStatus:       true,
+		PaymentMethod:    "RFID",
+		BatchID:          uuid.New().String(),
+		QRCode:           fmt.Sprintf("TICKET-%d-%s", now.UnixNano(), uuid.New().String()),
+		CreatedAt:        now.Format(time.RFC3339),
+		Checked:          true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to settle journey: %w", err)
+	}
+	if ticket == nil {
+		return nil, fmt.Errorf("journey already settled")
+	}
+	charge = ticket.Fare
+
+	// Riders board without a destination, so no stop arrival lets them off
+	if err := s.occupancy.Alight(j.BusName, journeyRider(j.Id)); err != nil {
+		log.Printf("failed to let rider off %s: %v", j.BusName, err)
+	}
+
+	if charge > 0 {
+		description := fmt.Sprintf("RFID Trip - %s%s", j.BusName, discountNote(discount))
+		if status == domain.JourneyTimedOut {
+			description += " - no tap-off, maximum fare"
+		}
+		if capped > 0 {
+			description += fmt.Sprintf(" - fare capped %.2f", capped)
+		}
+		s.CreateTransaction(model.Transaction{
+			UserID:        int(j.UserId),
+			Amount:        charge,
+			Type:          "purchase",
+			Description:   description,
+			PaymentMethod: "RFID",
+			CreatedAt:     now,
+		})
+	}
+
+	resp := &RFIDPaymentResponse{
+		Success:      true,
+		Status:       "SUCCESS",
+		Message:      "Journey settled",
+		Fare:         charge,
+		Discount:     discount,
+		CappedAmount: capped,
+		TicketID:     ticket.Id,
+		JourneyID:    j.Id,
+		HoldAmount:   j.HoldAmount,
+		Refunded:     refund,
+	}
+	if capped > 0 && charge == 0 {
+		resp.Status, resp.Message = domain.TicketStatusCapped, "Fare cap reached, no charge"
+	}
+	if u, err := s.userRepo.GetWithPassword(j.UserId); err == nil {
+		resp.Balance = float64(u.Balance)
+	}
+	return resp, nil
+}
@@ -2,9 +2,9 @@ package ticket
 
 import (
 	"fmt"
-	"math"
 	"swift_transit/domain"
 	"swift_transit/model"
+	"swift_transit/pass"
 	"time"
 
 	"github.com/google/uuid"
@@ -28,6 +28,35 @@ func (s *service) ProcessRFIDPayment(req RFIDPaymentRequest) (*RFIDPaymentRespon
 		}, nil
 	}
 
+	// A valid travel pass replaces the fare
+	activePass, err := s.passSvc.FindActivePass(user.Id, req.RouteID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to check travel pass: %w", err)
+	}
+	if activePass != nil {
+		boarded, err := s.passSvc.Board(activePass, pass.BoardRequest{
+			RouteId:            req.RouteID,
+			RegistrationNumber: req.BusName,
+			Stop:               req.StartDestination,
+			Source:             "RFID",
+		})
+		if err != nil {
+			return nil, err
+		}
+		status, message := "PASS", "Travel pass accepted"
+		if !boarded {
+			status, message = "DUPLICATE", "Already boarded with pass"
+		}
+		return &RFIDPaymentResponse{
+			Success: true,
+			Status:  status,
+			Message: message,
+			Balance: float64(user.Balance),
+			Fare:    0,
+			PassID:  activePass.Id,
+		}, nil
+	}
+
 	// Check for double deduction (ticket within last 5 minutes)
 	latestTicket, err := s.repo.GetLatestTicket(user.Id, req.RouteID)
 	if err == nil && latestTicket != nil {
@@ -47,13 +76,22 @@ func (s *service) ProcessRFIDPayment(req RFIDPaymentRequest) (*RFIDPaymentRespon
 	}
 
 	// 2. Calculate Fare
-	fare, err := s.repo.CalculateFare(req.RouteID, req.StartDestination, req.EndDestination)
+	quote, err := s.fareSvc.QuoteFare(req.RouteID, req.StartDestination, req.EndDestination, user, time.Now())
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate fare: %w", err)
 	}
-	fare = math.Ceil(fare) // Ensure whole number
 
-	// 3. Check Balance
+	// 3. Apply daily/weekly caps
+	fare, capped, err := s.applyFareCap(user.Id, quote.Fare, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to check fare cap: %w", err)
+	}
+	paymentStatus := "paid"
+	if capped > 0 && fare == 0 {
+		paymentStatus = domain.TicketStatusCapped
+	}
+
+	// 4. Check Balance
 	if float64(user.Balance) < fare {
 		return &RFIDPaymentResponse{
 			Success: false,
@@ -64,12 +102,14 @@ func (s *service) ProcessRFIDPayment(req RFIDPaymentRequest) (*RFIDPaymentRespon
 		}, nil
 	}
 
-	// 4. Deduct Balance
-	if err := s.userRepo.DeductBalance(user.Id, fare); err != nil {
-		return nil, fmt.Errorf("failed to deduct balance: %w", err)
+	// 5. Deduct Balance
+	if fare > 0 {
+		if err := s.userRepo.DeductBalance(user.Id, fare); err != nil {
+			return nil, fmt.Errorf("failed to deduct balance: %w", err)
+		}
 	}
 
-	// 5. Create Ticket (Paid and Checked)
+	// 6. Create Ticket (Paid and Checked)
 	batchID := uuid.New().String()
 	ticket := domain.Ticket{
 		UserId:           user.Id,
@@ -78,7 +118,8 @@ func (s *service) ProcessRFIDPayment(req RFIDPaymentRequest) (*RFIDPaymentRespon
 		StartDestination: req.StartDestination,
 		EndDestination:   req.EndDestination,
 		Fare:             fare,
-		PaymentStatus:    "paid",
+		Discount:         quote.Discount,
+		PaymentStatus:    paymentStatus,
 		PaidStatus:       true,
 		PaymentMethod:    "RFID",
 		BatchID:          batchID,
@@ -94,22 +135,37 @@ func (s *service) ProcessRFIDPayment(req RFIDPaymentRequest) (*RFIDPaymentRespon
 		return nil, fmt.Errorf("failed to create ticket: %w", err)
 	}
 
-	// 6. Create Transaction
-	s.CreateTransaction(model.Transaction{
-		UserID:        int(user.Id),
-		Amount:        fare,
-		Type:          "purchase",
-		Description:   fmt.Sprintf("RFID Trip - %s", req.BusName),
-		PaymentMethod: "RFID",
-		CreatedAt:     time.Now(),
-	})
+	// 7. Create Transaction (nothing is charged once the cap is reached)
+	if fare > 0 {
+		description := fmt.Sprintf("RFID Trip - %s%s", req.BusName, discountNote(quote.Discount))
+		if capped > 0 {
+			description += fmt.Sprintf(" - fare capped %.2f", capped)
+		}
+		s.CreateTransaction(model.Transaction{
+			UserID:        int(user.Id),
+			Amount:        fare,
+			Type:          "purchase",
+			Description:   description,
+			PaymentMethod: "RFID",
+			CreatedAt:     time.Now(),
+		})
+	}
+
+	s.board(req.BusName, fmt.Sprintf("ticket:%d", createdTicket.Id), req.EndDestination)
+
+	status, message := "SUCCESS", "Payment successful"
+	if capped > 0 && fare == 0 {
+		status, message = domain.TicketStatusCapped, "Fare cap reached, no charge"
+	}
 
 	return &RFIDPaymentResponse{
-		Success:  true,
-		Status:   "SUCCESS",
-		Message:  "Payment successful",
-		Balance:  float64(user.Balance) - fare,
-		Fare:     fare,
-		TicketID: createdTicket.Id,
+		Success:      true,
+		Status:       status,
+		Message:      message,
+		Balance:      float64(user.Balance) - fare,
+		Fare:         fare,
+		Discount:     quote.Discount,
+		CappedAmount: capped,
+		TicketID:     createdTicket.Id,
 	}, nil
 }
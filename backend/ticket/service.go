@@ -5,13 +5,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"math"
 	"strconv"
 	"strings"
 	"swift_transit/domain"
+	"swift_transit/fare"
 	"swift_transit/infra/payment"
 	"swift_transit/infra/rabbitmq"
 	"swift_transit/model"
+	"swift_transit/pass"
 	"swift_transit/user"
 	"time"
 
@@ -28,6 +31,8 @@ type TransactionRepo interface {
 
 type service struct {
 	repo            TicketRepo
+	fareSvc         fare.Service
+	passSvc         pass.Service
 	userRepo        user.UserRepo
 	transactionRepo TransactionRepo
 	redis           *redis.Client
@@ -35,11 +40,16 @@ type service struct {
 	rabbitMQ        *rabbitmq.RabbitMQ
 	ctx             context.Context
 	publicBaseURL   string
+	fareCaps        FareCaps
+	transfer        TransferPolicy
+	occupancy       Occupancy
 }
 
-func NewService(repo TicketRepo, userRepo user.UserRepo, transactionRepo TransactionRepo, redis *redis.Client, sslCommerz *payment.SSLCommerz, rabbitMQ *rabbitmq.RabbitMQ, ctx context.Context, publicBaseURL string) Service {
+func NewService(repo TicketRepo, fareSvc fare.Service, passSvc pass.Service, userRepo user.UserRepo, transactionRepo TransactionRepo, redis *redis.Client, sslCommerz *payment.SSLCommerz, rabbitMQ *rabbitmq.RabbitMQ, ctx context.Context, publicBaseURL string, fareCaps FareCaps, transfer TransferPolicy, occupancy Occupancy) Service {
 	return &service{
 		repo:            repo,
+		fareSvc:         fareSvc,
+		passSvc:         passSvc,
 		userRepo:        userRepo,
 		transactionRepo: transactionRepo,
 		redis:           redis,
@@ -47,6 +57,17 @@ func NewService(repo TicketRepo, userRepo user.UserRepo, transactionRepo Transac
 		rabbitMQ:        rabbitMQ,
 		ctx:             ctx,
 		publicBaseURL:   strings.TrimRight(publicBaseURL, "/"),
+		fareCaps:        fareCaps,
+		transfer:        transfer,
+		occupancy:       occupancy,
+	}
+}
+
+// board counts a rider on the bus. Occupancy is best effort and never holds
+// up boarding.
+func (s *service) board(registrationNumber, rider, alightStop string) {
+	if err := s.occupancy.Board(registrationNumber, rider, alightStop); err != nil {
+		log.Printf("failed to count rider %s on %s: %v", rider, registrationNumber, err)
 	}
 }
 
@@ -64,8 +85,8 @@ func (s *service) BuyTicket(req BuyTicketRequest) (*BuyTicketResponse, error) {
 		return nil, fmt.Errorf("you can purchase between 1 and 4 tickets per request")
 	}
 
-	// 2. Calculate Fare
-	fare, err := s.repo.CalculateFare(req.RouteId, req.StartDestination, req.EndDestination)
+	// 2. Calculate Fare (with any concession the rider is entitled to)
+	quote, err := s.quoteFare(req.UserId, req.RouteId, req.StartDestination, req.EndDestination)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate fare: %w", err)
 	}
@@ -85,7 +106,7 @@ func (s *service) BuyTicket(req BuyTicketRequest) (*BuyTicketResponse, error) {
 	}
 
 	batchID := uuid.New().String()
-	totalFare := fare * float64(req.Quantity)
+	totalFare := quote.Fare * float64(req.Quantity)
 
 	// 3. Create a temporary ID or use a UUID for tracking the request
 	// For simplicity, we might need to generate an ID here or let the worker handle it.
@@ -103,7 +124,8 @@ func (s *service) BuyTicket(req BuyTicketRequest) (*BuyTicketResponse, error) {
 		BusName:          req.BusName,
 		StartDestination: req.StartDestination,
 		EndDestination:   req.EndDestination,
-		Fare:             fare,
+		Fare:             quote.Fare,
+		Discount:         quote.Discount,
 		TotalFare:        totalFare,
 		Quantity:         req.Quantity,
 		BatchID:          batchID,
@@ -284,7 +306,13 @@ func (s *service) ValidatePayment(valID string, tranID string, amount float64) (
 
 	// 5. Store valid ticket in Redis for 4 hours
 	ticket, err := s.repo.Get(ticketID)
-	if err == nil {
+	if err == nil && ticket.JourneyId != nil {
+		if journey, err := s.repo.GetMultiLegJourney(*ticket.JourneyId); err == nil {
+			for _, leg := range journey.Legs {
+				s.storeValidLeg(leg)
+			}
+		}
+	} else if err == nil {
 		validTicket := map[string]interface{}{
 			"ticket_id":         ticket.Id,
 			"route_id":          ticket.RouteId,
@@ -302,6 +330,10 @@ func (s *service) ValidatePayment(valID string, tranID string, amount float64) (
 }
 
 func (s *service) CheckTicket(req CheckTicketRequest) (map[string]interface{}, error) {
+	if strings.HasPrefix(req.QRCode, pass.QRPrefix) {
+		return s.checkPass(req), nil
+	}
+
 	// 1. Check Redis
 	val, err := s.redis.Get(s.ctx, fmt.Sprintf("ticket_valid:%s", req.QRCode)).Result()
 	if err == redis.Nil {
@@ -339,13 +371,32 @@ func (s *service) CheckTicket(req CheckTicketRequest) (map[string]interface{}, e
 		}, nil
 	}
 
-	// 4. Check for Over-travel (Extra Fare)
+	// 4. Journey legs are only valid within the transfer window
+	var routeVersionID int64
+	if ticketID, ok := ticketData["ticket_id"].(float64); ok {
+		if leg, err := s.repo.Get(int64(ticketID)); err == nil {
+			if leg.RouteVersionId != nil {
+				routeVersionID = *leg.RouteVersionId
+			}
+			if err := CheckJourneyLeg(s.repo, leg); err != nil {
+				return map[string]interface{}{
+					"success":   false,
+					"status":    "transfer_window",
+					"message":   err.Error(),
+					"ticket_id": ticketData["ticket_id"],
+				}, nil
+			}
+		}
+	}
+
+	// 5. Check for Over-travel (Extra Fare)
 	endDest, ok := ticketData["end_destination"].(string)
 	if !ok {
 		return nil, fmt.Errorf("invalid ticket data: missing end_destination")
 	}
 
-	destStop, err := s.repo.GetStop(req.RouteID, endDest)
+	// The stop order is the one of the route version the ticket was sold on
+	destStop, err := s.repo.GetStop(req.RouteID, endDest, routeVersionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to verify destination stop: %v", err)
 	}
@@ -353,10 +404,24 @@ func (s *service) CheckTicket(req CheckTicketRequest) (map[string]interface{}, e
 	var response map[string]interface{}
 	if req.CurrentStoppage.Order > destStop.Order {
 		// Calculate Extra Fare
-		extraFare, err := s.repo.CalculateFare(req.RouteID, endDest, req.CurrentStoppage.Name)
+		// Tickets cached before user_id was stored are loaded for it
+		var userID int64
+		if id, ok := ticketData["user_id"].(float64); ok {
+			userID = int64(id)
+		} else if ticketID, ok := ticketData["ticket_id"].(float64); ok {
+			t, err := s.repo.Get(int64(ticketID))
+			if err != nil {
+				return nil, fmt.Errorf("failed to load ticket: %v", err)
+			}
+			userID = t.UserId
+		} else {
+			return nil, fmt.Errorf("invalid ticket data: missing user_id")
+		}
+		extraQuote, err := s.quoteFare(userID, req.RouteID, endDest, req.CurrentStoppage.Name)
 		if err != nil {
 			return nil, fmt.Errorf("failed to calculate extra fare: %v", err)
 		}
+		extraFare := extraQuote.Fare
 
 		response = map[string]interface{}{
 			"success":          false, // Not fully successful until extra fare is handled
@@ -376,12 +441,12 @@ func (s *service) CheckTicket(req CheckTicketRequest) (map[string]interface{}, e
 		response["checked"] = true
 	}
 
-	// 5. Mark as checked in Redis
+	// 6. Mark as checked in Redis
 	ticketData["checked"] = true
 	updatedJSON, _ := json.Marshal(ticketData)
 	s.redis.Set(s.ctx, fmt.Sprintf("ticket_valid:%s", req.QRCode), updatedJSON, redis.KeepTTL)
 
-	// 6. Publish to RabbitMQ for async updates (DB update)
+	// 7. Publish to RabbitMQ for async updates (DB update)
 	checkEvent := map[string]interface{}{
 		"ticket_id":        ticketData["ticket_id"],
 		"qr_code":          req.QRCode,
@@ -413,11 +478,10 @@ func (s *service) CreateOverTravelTicket(originalTicketID int64, currentStop str
 		return nil, fmt.Errorf("original ticket not found: %w", err)
 	}
 
-	fare, err := s.repo.CalculateFare(originalTicket.RouteId, originalTicket.EndDestination, currentStop)
+	quote, err := s.quoteFare(originalTicket.UserId, originalTicket.RouteId, originalTicket.EndDestination, currentStop)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate fare: %w", err)
 	}
-	fare = math.Ceil(fare)
 
 	batchID := uuid.New().String()
 	newTicket := domain.Ticket{
@@ -426,7 +490,8 @@ func (s *service) CreateOverTravelTicket(originalTicketID int64, currentStop str
 		BusName:            originalTicket.BusName,
 		StartDestination:   originalTicket.EndDestination,
 		EndDestination:     currentStop,
-		Fare:               fare,
+		Fare:               quote.Fare,
+		Discount:           quote.Discount,
 		PaymentStatus:      "unpaid",
 		PaidStatus:         false,
 		PaymentMethod:      "CASH", // Assumed cash for over-travel
@@ -451,9 +516,9 @@ func (s *service) CreateOverTravelTicket(originalTicketID int64, currentStop str
 	if paymentCollected {
 		s.CreateTransaction(model.Transaction{
 			UserID:        int(originalTicket.UserId),
-			Amount:        fare,
+			Amount:        quote.Fare,
 			Type:          "purchase",
-			Description:   fmt.Sprintf("Over-Travel Ticket - %s", originalTicket.BusName),
+			Description:   fmt.Sprintf("Over-Travel Ticket - %s%s", originalTicket.BusName, discountNote(quote.Discount)),
 			PaymentMethod: "CASH",
 			CreatedAt:     time.Now(),
 		})
@@ -462,6 +527,49 @@ func (s *service) CreateOverTravelTicket(originalTicketID int64, currentStop str
 	return createdTicket, nil
 }
 
+// checkPass validates a pass QR code in place of a ticket and logs the boarding.
+func (s *service) checkPass(req CheckTicketRequest) map[string]interface{} {
+	p, err := s.passSvc.BoardWithQR(req.QRCode, pass.BoardRequest{
+		RouteId:            req.RouteID,
+		RegistrationNumber: req.RegistrationNumber,
+		Stop:               req.CurrentStoppage.Name,
+		Source:             "QR",
+	})
+	if err != nil {
+		return map[string]interface{}{
+			"success": false,
+			"status":  "invalid_pass",
+			"message": err.Error(),
+		}
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"status":  "valid_pass",
+		"message": "Pass Valid",
+		"pass":    p,
+	}
+}
+
+// quoteFare prices a trip for a specific rider so concessions are applied
+// the same way on every purchase path.
+func (s *service) quoteFare(userID int64, routeID int64, start, end string) (*fare.Quote, error) {
+	rider, err := s.userRepo.GetWithPassword(userID)
+	if err != nil {
+		return nil, fmt.Errorf("rider not found: %w", err)
+	}
+	return s.fareSvc.QuoteFare(routeID, start, end, rider, time.Now())
+}
+
+// discountNote is appended to transaction descriptions so concession costs
+// show up in statements and owner reports.
+func discountNote(discount float64) string {
+	if discount <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" - student discount %.2f", discount)
+}
+
 func (s *service) ValidateTicket(id int64) error {
 	ticket, err := s.repo.Get(id)
 	if err != nil {
@@ -505,14 +613,18 @@ func (s *service) UpdatePaymentStatus(id int64) error {
 		count = 1
 	}
 
-	totalAmount := ticket.Fare * float64(count)
+	// Journey legs in one batch can have different fares
+	totalAmount, err := s.repo.GetBatchTotal(ticket.BatchID)
+	if err != nil {
+		totalAmount = ticket.Fare * float64(count)
+	}
 
 	// Create Transaction
 	return s.CreateTransaction(model.Transaction{
 		UserID:        int(ticket.UserId),
 		Amount:        totalAmount,
 		Type:          "purchase",
-		Description:   fmt.Sprintf("Ticket Purchase - %s (x%d)", ticket.BusName, count),
+		Description:   fmt.Sprintf("Ticket Purchase - %s (x%d)%s", ticket.BusName, count, discountNote(ticket.Discount*float64(count))),
 		PaymentMethod: "Online", // Or "Gateway"
 		CreatedAt:     time.Now(),
 	})
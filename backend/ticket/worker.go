@@ -151,6 +151,7 @@ func (w *TicketWorker) ProcessTicket(req TicketRequestMessage, trackingID string
 			StartDestination: req.StartDestination,
 			EndDestination:   req.EndDestination,
 			Fare:             req.Fare,
+			Discount:         req.Discount,
 			PaidStatus:       paidStatus,
 			Checked:          false,
 			QRCode:           qrCode,
@@ -182,7 +183,7 @@ func (w *TicketWorker) ProcessTicket(req TicketRequestMessage, trackingID string
 			UserID:        int(req.UserId),
 			Amount:        req.TotalFare,
 			Type:          "purchase",
-			Description:   fmt.Sprintf("Ticket Purchase - %s (x%d)", req.BusName, req.Quantity),
+			Description:   fmt.Sprintf("Ticket Purchase - %s (x%d)%s", req.BusName, req.Quantity, discountNote(req.Discount*float64(req.Quantity))),
 			PaymentMethod: "Swift Balance",
 			CreatedAt:     time.Now(),
 		})
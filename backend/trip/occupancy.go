@@ -0,0 +1,197 @@
+package trip
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"swift_transit/domain"
+	"swift_transit/location"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// How long the trip in progress of a bus is cached, and how long the
+	// absence of one is
+	activeTripTTL = 12 * time.Hour
+	noTripTTL     = time.Minute
+
+	ridersTTL = 24 * time.Hour
+
+	// Alighting stop of riders who did not say where they get off
+	unknownStop = -1
+
+	// Riders, as a share of seats, at which a bus takes no more standing
+	fullLoadPercent = 150
+)
+
+// activeTrip is the part of a trip in progress needed on every position
+// update, cached in Redis. A zero Id caches that the bus has no trip.
+type activeTrip struct {
+	Id              int64  `json:"id"`
+	RouteId         int64  `json:"route_id"`
+	SeatCapacity    int    `json:"seat_capacity"`
+	ScheduledTripId int64  `json:"scheduled_trip_id,omitempty"`
+	ServiceDate     string `json:"service_date,omitempty"`
+}
+
+func activeTripKey(busId int64) string {
+	return fmt.Sprintf("bus_trip:%d", busId)
+}
+
+// ridersKey holds the riders on board a trip, each with the order of the
+// stop they get off at.
+func ridersKey(tripId int64) string {
+	return fmt.Sprintf("trip_riders:%d", tripId)
+}
+
+func (s *service) activeTrip(busId int64) (*activeTrip, error) {
+	val, err := s.redis.Get(s.ctx, activeTripKey(busId)).Result()
+	if err == nil {
+		var t activeTrip
+		if err := json.Unmarshal([]byte(val), &t); err != nil {
+			return nil, err
+		}
+		if t.Id == 0 {
+			return nil, nil
+		}
+		return &t, nil
+	}
+	if err != redis.Nil {
+		return nil, err
+	}
+
+	trip, err := s.repo.FindActive(busId)
+	if err != nil {
+		return nil, err
+	}
+	t := s.cacheActiveTrip(busId, trip)
+	if t.Id == 0 {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+func (s *service) cacheActiveTrip(busId int64, trip *domain.Trip) activeTrip {
+	t, ttl := activeTrip{}, noTripTTL
+	if trip != nil && trip.Status == domain.TripActive {
+		t = activeTrip{Id: trip.Id, RouteId: trip.RouteId, SeatCapacity: trip.SeatCapacity}
+		if trip.ScheduledTripId != nil && trip.ServiceDate != nil {
+			t.ScheduledTripId, t.ServiceDate = *trip.ScheduledTripId, *trip.ServiceDate
+		}
+		ttl = activeTripTTL
+	}
+	data, _ := json.Marshal(t)
+	if err := s.redis.Set(s.ctx, activeTripKey(busId), data, ttl).Err(); err != nil {
+		log.Printf("failed to cache trip of bus %d: %v", busId, err)
+	}
+	return t
+}
+
+func (s *service) Board(registrationNumber, rider, alightStop string) error {
+	busId, err := s.repo.GetBusIdByRegistration(registrationNumber)
+	if err != nil || busId == 0 {
+		return err
+	}
+	t, err := s.activeTrip(busId)
+	if err != nil || t == nil {
+		return err
+	}
+
+	order := unknownStop
+	if alightStop != "" {
+		if order, err = s.repo.GetStopOrder(t.RouteId, alightStop); err != nil {
+			return err
+		}
+	}
+
+	key := ridersKey(t.Id)
+	_, err = s.redis.Pipelined(s.ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(s.ctx, key, rider, order)
+		pipe.Expire(s.ctx, key, ridersTTL)
+		return nil
+	})
+	return err
+}
+
+func (s *service) Alight(registrationNumber, rider string) error {
+	busId, err := s.repo.GetBusIdByRegistration(registrationNumber)
+	if err != nil || busId == 0 {
+		return err
+	}
+	t, err := s.activeTrip(busId)
+	if err != nil || t == nil {
+		return err
+	}
+	return s.redis.HDel(s.ctx, ridersKey(t.Id), rider).Err()
+}
+
+func (s *service) Crowding(busId int64) (*location.Crowding, error) {
+	t, err := s.activeTrip(busId)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	n, err := s.redis.HLen(s.ctx, ridersKey(t.Id)).Result()
+	if err != nil {
+		return nil, err
+	}
+	return &location.Crowding{
+		Occupancy: int(n),
+		Capacity:  t.SeatCapacity,
+		Level:     crowdingLevel(int(n), t.SeatCapacity),
+	}, nil
+}
+
+func (s *service) ObserveStopEvent(event domain.StopEvent) {
+	if event.Event != domain.StopArrived {
+		return
+	}
+	t, err := s.activeTrip(event.BusId)
+	if err != nil || t == nil {
+		if err != nil {
+			log.Printf("failed to load trip of bus %d: %v", event.BusId, err)
+		}
+		return
+	}
+	s.recordDelay(t, event)
+
+	key := ridersKey(t.Id)
+	riders, err := s.redis.HGetAll(s.ctx, key).Result()
+	if err != nil {
+		log.Printf("failed to load riders of trip %d: %v", t.Id, err)
+		return
+	}
+	// Riders whose stop was passed without an arrival being detected get off
+	// here too
+	var leaving []string
+	for rider, val := range riders {
+		order, err := strconv.Atoi(val)
+		if err == nil && order != unknownStop && order <= event.StopOrder {
+			leaving = append(leaving, rider)
+		}
+	}
+	if len(leaving) > 0 {
+		if err := s.redis.HDel(s.ctx, key, leaving...).Err(); err != nil {
+			log.Printf("failed to let riders off trip %d: %v", t.Id, err)
+		}
+	}
+}
+
+func crowdingLevel(occupancy, capacity int) string {
+	switch {
+	case capacity <= 0:
+		return ""
+	case occupancy == 0:
+		return location.CrowdingEmpty
+	case occupancy*2 < capacity:
+		return location.CrowdingManySeats
+	case occupancy < capacity:
+		return location.CrowdingFewSeats
+	case occupancy*100 < capacity*fullLoadPercent:
+		return location.CrowdingStanding
+	default:
+		return location.CrowdingFull
+	}
+}
@@ -0,0 +1,73 @@
+package trip
+
+import (
+	"swift_transit/domain"
+	"swift_transit/location"
+	"time"
+)
+
+type StartRequest struct {
+	BusId      int64  `json:"-"` // Extracted from the bus token
+	RouteId    int64  `json:"-"`
+	Variant    string `json:"-"`
+	DriverName string `json:"driver_name"`
+	// ScheduledTripId picks the timetabled run the trip follows; without it
+	// the run due to leave closest to now is used
+	ScheduledTripId int64 `json:"scheduled_trip_id"`
+}
+
+// Schedules looks up the timetabled runs trips are compared against.
+type Schedules interface {
+	MatchRun(routeId int64, at time.Time) (*domain.ScheduledRun, error)
+	GetRun(scheduledTripId int64, serviceDate string) (*domain.ScheduledRun, error)
+	CurrentRun(scheduledTripId int64, at time.Time) (*domain.ScheduledRun, error)
+}
+
+type Service interface {
+	Start(req StartRequest) (*domain.Trip, error)
+	End(busId int64) (*domain.Trip, error)
+	// Current returns the bus's trip in progress, or nil
+	Current(busId int64) (*domain.Trip, error)
+	// ObserveLocation adds the position to the bus's trip in progress, if any
+	ObserveLocation(update location.LocationUpdate)
+
+	GetOwnerTrips(ownerId, busId int64, page, pageSize int) ([]domain.Trip, int, error)
+	GetOwnerTrip(ownerId, tripId int64) (*domain.Trip, error)
+	GetTrip(tripId int64) (*domain.Trip, error)
+	// Board counts a rider on the bus's trip in progress until the bus
+	// arrives at alightStop, or until Alight when the stop is unknown. Riders
+	// are identified by a key unique to their ticket or card journey.
+	Board(registrationNumber, rider, alightStop string) error
+	Alight(registrationNumber, rider string) error
+	// Crowding reports how full the bus is on its trip in progress, or nil
+	Crowding(busId int64) (*location.Crowding, error)
+	// ObserveStopEvent lets off the riders whose stop the bus arrived at and
+	// records how late the bus got there
+	ObserveStopEvent(event domain.StopEvent)
+	// ScheduledRun returns the timetabled run the bus's trip in progress
+	// follows, or nil
+	ScheduledRun(busId int64) (*domain.ScheduledRun, error)
+
+	// Replay returns the positions, stop events and ticket checks recorded
+	// during the trip in time order
+	Replay(trip *domain.Trip) ([]location.Frame, error)
+}
+
+type TripRepo interface {
+	Create(trip domain.Trip) (*domain.Trip, error)
+	FindActive(busId int64) (*domain.Trip, error)
+	Complete(tripId int64, endedAt time.Time) (*domain.Trip, error)
+	// AddPoint records a position on the bus's active trip; it does nothing
+	// when the bus has no trip in progress
+	AddPoint(busId int64, point domain.TripPoint) error
+	SetDelay(tripId int64, seconds int) error
+	FindByOwner(ownerId, busId int64, limit, offset int) ([]domain.Trip, int, error)
+	FindByIdForOwner(ownerId, tripId int64) (*domain.Trip, error)
+	FindById(tripId int64) (*domain.Trip, error)
+	GetPoints(tripId int64) ([]domain.TripPoint, error)
+	GetStopEvents(busId int64, from, to time.Time) ([]domain.StopEvent, error)
+	GetTicketChecks(registrationNumber string, from, to time.Time) ([]domain.TripTicketCheck, error)
+	// GetBusIdByRegistration returns 0 for an unknown bus
+	GetBusIdByRegistration(registrationNumber string) (int64, error)
+	GetStopOrder(routeId int64, name string) (int, error)
+}
@@ -0,0 +1,62 @@
+package trip
+
+import (
+	"fmt"
+	"log"
+	"swift_transit/domain"
+	"time"
+)
+
+// scheduledRun resolves the timetabled run a new trip follows: the one the
+// driver picked, or else the run of the route due to leave closest to now.
+func (s *service) scheduledRun(req StartRequest, now time.Time) (*domain.ScheduledRun, error) {
+	if req.ScheduledTripId == 0 {
+		run, err := s.schedules.MatchRun(req.RouteId, now)
+		if err != nil {
+			// A trip is still worth recording without a schedule to compare
+			log.Printf("failed to match bus %d to a scheduled trip: %v", req.BusId, err)
+			return nil, nil
+		}
+		return run, nil
+	}
+
+	run, err := s.schedules.CurrentRun(req.ScheduledTripId, now)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil || run.RouteId != req.RouteId {
+		return nil, fmt.Errorf("scheduled trip %d does not run on this route today", req.ScheduledTripId)
+	}
+	return run, nil
+}
+
+func (s *service) ScheduledRun(busId int64) (*domain.ScheduledRun, error) {
+	t, err := s.activeTrip(busId)
+	if err != nil || t == nil || t.ScheduledTripId == 0 {
+		return nil, err
+	}
+	return s.schedules.GetRun(t.ScheduledTripId, t.ServiceDate)
+}
+
+// recordDelay stores how late the bus arrived at the stop against its
+// scheduled run. Stops the run has no time for are skipped.
+func (s *service) recordDelay(t *activeTrip, event domain.StopEvent) {
+	if t.ScheduledTripId == 0 {
+		return
+	}
+	run, err := s.schedules.GetRun(t.ScheduledTripId, t.ServiceDate)
+	if err != nil || run == nil {
+		if err != nil {
+			log.Printf("failed to load scheduled run of trip %d: %v", t.Id, err)
+		}
+		return
+	}
+	due, ok := run.Arrivals[event.StopOrder]
+	if !ok {
+		return
+	}
+	delay := int(event.OccurredAt.Sub(due).Round(time.Second) / time.Second)
+	if err := s.repo.SetDelay(t.Id, delay); err != nil {
+		log.Printf("failed to record delay of trip %d: %v", t.Id, err)
+	}
+}
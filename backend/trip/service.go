@@ -0,0 +1,172 @@
+package trip
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"swift_transit/domain"
+	"swift_transit/location"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type service struct {
+	repo      TripRepo
+	schedules Schedules
+	redis     *redis.Client
+	ctx       context.Context
+}
+
+func NewService(repo TripRepo, schedules Schedules, redis *redis.Client, ctx context.Context) Service {
+	return &service{
+		repo:      repo,
+		schedules: schedules,
+		redis:     redis,
+		ctx:       ctx,
+	}
+}
+
+func (s *service) Start(req StartRequest) (*domain.Trip, error) {
+	if req.BusId == 0 || req.RouteId == 0 {
+		return nil, fmt.Errorf("a bus token is required to start a trip")
+	}
+
+	active, err := s.repo.FindActive(req.BusId)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, fmt.Errorf("trip %d is already in progress", active.Id)
+	}
+
+	now := time.Now()
+	run, err := s.scheduledRun(req, now)
+	if err != nil {
+		return nil, err
+	}
+
+	t := domain.Trip{
+		BusId:      req.BusId,
+		RouteId:    req.RouteId,
+		Variant:    req.Variant,
+		DriverName: strings.TrimSpace(req.DriverName),
+		Status:     domain.TripActive,
+		StartedAt:  now,
+	}
+	if run != nil {
+		t.ScheduledTripId = &run.ScheduledTripId
+		t.ServiceDate = &run.ServiceDate
+	}
+	trip, err := s.repo.Create(t)
+	if err != nil {
+		return nil, err
+	}
+	s.cacheActiveTrip(req.BusId, trip)
+	return trip, nil
+}
+
+func (s *service) End(busId int64) (*domain.Trip, error) {
+	active, err := s.repo.FindActive(busId)
+	if err != nil {
+		return nil, err
+	}
+	if active == nil {
+		return nil, fmt.Errorf("no trip in progress")
+	}
+	trip, err := s.repo.Complete(active.Id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	s.cacheActiveTrip(busId, nil)
+	s.redis.Del(s.ctx, ridersKey(active.Id))
+	return trip, nil
+}
+
+func (s *service) Current(busId int64) (*domain.Trip, error) {
+	return s.repo.FindActive(busId)
+}
+
+func (s *service) ObserveLocation(update location.LocationUpdate) {
+	err := s.repo.AddPoint(update.BusID, domain.TripPoint{
+		Latitude:   update.Latitude,
+		Longitude:  update.Longitude,
+		Speed:      update.Speed,
+		RecordedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("failed to record trip point for bus %d: %v", update.BusID, err)
+	}
+}
+
+func (s *service) GetOwnerTrips(ownerId, busId int64, page, pageSize int) ([]domain.Trip, int, error) {
+	offset := (page - 1) * pageSize
+	return s.repo.FindByOwner(ownerId, busId, pageSize, offset)
+}
+
+func (s *service) GetOwnerTrip(ownerId, tripId int64) (*domain.Trip, error) {
+	trip, err := s.repo.FindByIdForOwner(ownerId, tripId)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil {
+		return nil, fmt.Errorf("trip not found")
+	}
+	return trip, nil
+}
+
+func (s *service) GetTrip(tripId int64) (*domain.Trip, error) {
+	trip, err := s.repo.FindById(tripId)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil {
+		return nil, fmt.Errorf("trip not found")
+	}
+	return trip, nil
+}
+
+func (s *service) Replay(trip *domain.Trip) ([]location.Frame, error) {
+	points, err := s.repo.GetPoints(trip.Id)
+	if err != nil {
+		return nil, err
+	}
+	end := time.Now()
+	if trip.EndedAt != nil {
+		end = *trip.EndedAt
+	}
+	events, err := s.repo.GetStopEvents(trip.BusId, trip.StartedAt, end)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := s.repo.GetTicketChecks(trip.RegistrationNumber, trip.StartedAt, end)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make([]location.Frame, 0, len(points)+len(events)+len(checks))
+	for _, p := range points {
+		frames = append(frames, location.Frame{At: p.RecordedAt, Type: location.MessageLocation, Data: location.VehiclePosition{
+			LocationUpdate: location.LocationUpdate{
+				BusID:              trip.BusId,
+				RouteID:            trip.RouteId,
+				RegistrationNumber: trip.RegistrationNumber,
+				Latitude:           p.Latitude,
+				Longitude:          p.Longitude,
+				Speed:              p.Speed,
+			},
+			UpdatedAt: p.RecordedAt,
+		}})
+	}
+	for _, e := range events {
+		frames = append(frames, location.Frame{At: e.OccurredAt, Type: location.MessageStopEvent, Data: e})
+	}
+	for _, c := range checks {
+		c.BusId = trip.BusId
+		frames = append(frames, location.Frame{At: c.CheckedAt, Type: location.MessageTicketCheck, Data: c})
+	}
+	sort.SliceStable(frames, func(i, j int) bool { return frames[i].At.Before(frames[j].At) })
+	return frames, nil
+}
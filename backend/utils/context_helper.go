@@ -46,3 +46,14 @@ func (h *Handler) GetUserIDFromContext(ctx context.Context) int64 {
 	}
 	return 0
 }
+
+// GetRoleFromContext returns the role claim of the caller's token, or "" for
+// tokens issued without one.
+func (h *Handler) GetRoleFromContext(ctx context.Context) string {
+	if v, ok := h.GetUserFromContext(ctx).(map[string]interface{}); ok {
+		if role, ok := v["role"].(string); ok {
+			return role
+		}
+	}
+	return ""
+}